@@ -0,0 +1,350 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RyanSStephens/TF-NLP-Agent/internal/ai"
+	"github.com/RyanSStephens/TF-NLP-Agent/internal/nlp"
+	"github.com/RyanSStephens/TF-NLP-Agent/internal/security"
+	"github.com/RyanSStephens/TF-NLP-Agent/internal/terraform"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// unsafeIDChars matches characters not allowed in a batch item's directory name
+var unsafeIDChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// batchSeverities are the issue severities counted in the summary, most severe first
+var batchSeverities = []string{"CRITICAL", "HIGH", "MEDIUM", "LOW"}
+
+// batchItem is one line of a batch file. Backlog files with request_id,
+// title and body fields are accepted as well as id and description.
+type batchItem struct {
+	ID          string `json:"id"`
+	RequestID   string `json:"request_id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Body        string `json:"body"`
+}
+
+// batchResult is the outcome of generating one batch item
+type batchResult struct {
+	ID         string             `json:"id"`
+	Title      string             `json:"title,omitempty"`
+	Success    bool               `json:"success"`
+	Error      string             `json:"error,omitempty"`
+	OutputDir  string             `json:"output_dir,omitempty"`
+	Files      []string           `json:"files,omitempty"`
+	Issues     map[string]int     `json:"issues"`
+	Suppressed int                `json:"suppressed,omitempty"`
+	Costs      map[string]float64 `json:"costs,omitempty"`
+	TotalCost  float64            `json:"total_cost"`
+	Warnings   []string           `json:"warnings,omitempty"`
+	Duration   string             `json:"duration"`
+}
+
+// batchSummary aggregates the results of a batch run
+type batchSummary struct {
+	Total     int            `json:"total"`
+	Succeeded int            `json:"succeeded"`
+	Failed    int            `json:"failed"`
+	Issues    map[string]int `json:"issues"`
+	TotalCost float64        `json:"total_cost"`
+	Results   []batchResult  `json:"results"`
+}
+
+// runBatch generates every description in a JSONL file with bounded
+// concurrency and reports a summary. It fails if any description failed.
+func runBatch(cmd *cobra.Command, path string) error {
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	format, _ := cmd.Flags().GetString("format")
+	if format != "table" && format != "json" {
+		return fmt.Errorf("unsupported format: %s (use table or json)", format)
+	}
+	if concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1")
+	}
+
+	items, err := loadBatch(path)
+	if err != nil {
+		return err
+	}
+
+	outputDir := cmd.Flag("output-dir").Value.String()
+	if outputDir == "" {
+		outputDir = viper.GetString("terraform.output_dir")
+	}
+
+	aiProvider := ai.NewProvider(viper.GetString("ai.provider"))
+	engine, err := newEngine()
+	if err != nil {
+		return err
+	}
+	parser, err := newParser(engine, aiProvider)
+	if err != nil {
+		return err
+	}
+	tfGenerator := terraform.NewGenerator()
+	securityScanner, err := newScanner()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Processing %d descriptions from %s (concurrency %d)\n", len(items), path, concurrency)
+
+	results := make([]batchResult, len(items))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, item := range items {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, item batchItem) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			results[i] = processBatchItem(item, filepath.Join(outputDir, item.ID), engine, parser, aiProvider, tfGenerator, securityScanner)
+			status := "ok"
+			if !results[i].Success {
+				status = "failed: " + results[i].Error
+			}
+			fmt.Fprintf(os.Stderr, "  [%d/%d] %s %s\n", i+1, len(items), item.ID, status)
+		}(i, item)
+	}
+	wg.Wait()
+
+	summary := summarizeBatch(results)
+
+	report, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode summary: %w", err)
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", outputDir, err)
+	}
+	summaryPath := filepath.Join(outputDir, "summary.json")
+	if err := os.WriteFile(summaryPath, report, 0644); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+
+	if format == "json" {
+		fmt.Println(string(report))
+	} else {
+		fmt.Print(summary.Table())
+		fmt.Printf("Summary written to: %s\n", summaryPath)
+	}
+
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d of %d descriptions failed", summary.Failed, summary.Total)
+	}
+	return nil
+}
+
+// loadBatch reads the batch items of a JSONL file, skipping blank lines and
+// comments. Items without an id are numbered by line.
+func loadBatch(path string) ([]batchItem, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open batch file: %w", err)
+	}
+	defer file.Close()
+
+	var items []batchItem
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var item batchItem
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			return nil, fmt.Errorf("batch line %d: %w", lineNum, err)
+		}
+
+		if item.ID == "" {
+			item.ID = item.RequestID
+		}
+		if item.ID == "" {
+			item.ID = fmt.Sprintf("line-%d", lineNum)
+		}
+		item.ID = strings.Trim(unsafeIDChars.ReplaceAllString(item.ID, "-"), "-.")
+		if item.ID == "" {
+			return nil, fmt.Errorf("batch line %d: id has no usable characters", lineNum)
+		}
+		if seen[item.ID] {
+			return nil, fmt.Errorf("batch line %d: duplicate id %q", lineNum, item.ID)
+		}
+		seen[item.ID] = true
+
+		if item.Description == "" {
+			item.Description = item.Body
+		}
+		if strings.TrimSpace(item.Description) == "" {
+			return nil, fmt.Errorf("batch line %d: description is required", lineNum)
+		}
+
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read batch file: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("batch file %s has no descriptions", path)
+	}
+
+	return items, nil
+}
+
+// processBatchItem runs one description through parse, generate, validate
+// and scan, and writes the result to dir. Ambiguities are resolved with the
+// suggested defaults since nobody is there to answer. An item that violates
+// the failure policy fails but is still written and priced.
+func processBatchItem(item batchItem, dir string, engine *nlp.Engine, parser nlp.Parser, aiProvider ai.Provider, tfGenerator *terraform.Generator, securityScanner *security.Scanner) batchResult {
+	started := time.Now()
+	result := batchResult{ID: item.ID, Title: item.Title, Issues: make(map[string]int)}
+
+	err := func() error {
+		parsed, err := parser.Parse(item.Description)
+		if err != nil {
+			return fmt.Errorf("failed to parse description: %w", err)
+		}
+		for _, correction := range parsed.Corrections {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("interpreted %q as %q", correction.From, correction.To))
+		}
+
+		if questions := engine.Clarify(parsed); len(questions) > 0 {
+			if err := engine.ApplyAnswers(parsed, nlp.DefaultAnswers(questions)); err != nil {
+				return fmt.Errorf("failed to apply clarifications: %w", err)
+			}
+		}
+
+		config, err := aiProvider.GenerateConfig(parsed)
+		if err != nil {
+			return fmt.Errorf("failed to generate configuration: %w", err)
+		}
+
+		validated, err := tfGenerator.Validate(config)
+		if err != nil {
+			return fmt.Errorf("failed to validate configuration: %w", err)
+		}
+
+		// A policy failure is reported after the output is written, so the
+		// generation is kept for review and its cost is still counted
+		var policyErr error
+		if viper.GetBool("security.scan_enabled") {
+			scanned, complianceIssues, err := scanConfiguration(securityScanner, validated, parsed)
+			if err != nil {
+				return err
+			}
+			for _, issue := range scanned.Issues {
+				result.Issues[issue.Severity]++
+			}
+			result.Suppressed = len(scanned.Suppressed)
+			policyErr = checkFailurePolicy(scanned.Issues, complianceIssues)
+		}
+
+		costs, err := tfGenerator.EstimateCost(validated)
+		if err != nil {
+			return fmt.Errorf("cost estimation failed: %w", err)
+		}
+		result.Costs = costs
+		for _, cost := range costs {
+			result.TotalCost += cost
+		}
+		if warning := terraform.BudgetWarning(costs, parsed.Budget); warning != "" {
+			result.Warnings = append(result.Warnings, warning)
+		}
+
+		layout := &terraform.EnvironmentLayout{Files: map[string]string{"main.tf": validated}}
+		if environments := terraform.EnvironmentsFrom(parsed); len(environments) > 1 {
+			layout, err = tfGenerator.SplitEnvironments(validated, environments)
+			if err != nil {
+				return fmt.Errorf("failed to split configuration into environments: %w", err)
+			}
+			result.Warnings = append(result.Warnings, layout.Warnings...)
+		}
+		if err := layout.Write(dir); err != nil {
+			return err
+		}
+		result.OutputDir = dir
+		result.Files = layout.Paths()
+
+		return policyErr
+	}()
+
+	result.Success = err == nil
+	if err != nil {
+		result.Error = err.Error()
+	}
+	result.Duration = time.Since(started).Round(time.Millisecond).String()
+
+	return result
+}
+
+// summarizeBatch totals the results of a batch run
+func summarizeBatch(results []batchResult) *batchSummary {
+	summary := &batchSummary{
+		Total:   len(results),
+		Issues:  make(map[string]int),
+		Results: results,
+	}
+
+	for _, result := range results {
+		if result.Success {
+			summary.Succeeded++
+		} else {
+			summary.Failed++
+		}
+		for severity, count := range result.Issues {
+			summary.Issues[severity] += count
+		}
+		summary.TotalCost += result.TotalCost
+	}
+
+	return summary
+}
+
+// Table renders the summary as a fixed-width table, one row per description
+func (s *batchSummary) Table() string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("%-24s %-7s %8s %6s %6s %6s %10s  %s\n", "ID", "STATUS", "CRITICAL", "HIGH", "MEDIUM", "LOW", "COST/MONTH", "DETAILS"))
+	for _, result := range s.Results {
+		status, details := "ok", result.OutputDir
+		if !result.Success {
+			status, details = "failed", result.Error
+		}
+		b.WriteString(fmt.Sprintf("%-24s %-7s %8d %6d %6d %6d %10.2f  %s\n",
+			result.ID, status, result.Issues["CRITICAL"], result.Issues["HIGH"], result.Issues["MEDIUM"], result.Issues["LOW"], result.TotalCost, details))
+	}
+
+	var severities []string
+	for _, severity := range batchSeverities {
+		if count := s.Issues[severity]; count > 0 {
+			severities = append(severities, fmt.Sprintf("%d %s", count, strings.ToLower(severity)))
+		}
+	}
+	issues := "no issues"
+	if len(severities) > 0 {
+		issues = strings.Join(severities, ", ") + " issues"
+	}
+
+	b.WriteString(fmt.Sprintf("\n%d descriptions, %d succeeded, %d failed, %s, $%.2f/month estimated\n", s.Total, s.Succeeded, s.Failed, issues, s.TotalCost))
+
+	return b.String()
+}
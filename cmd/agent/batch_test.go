@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/RyanSStephens/TF-NLP-Agent/internal/nlp"
+	"github.com/RyanSStephens/TF-NLP-Agent/internal/security"
+	"github.com/RyanSStephens/TF-NLP-Agent/internal/terraform"
+	"github.com/spf13/viper"
+)
+
+// staticProvider returns the same configuration for every description
+type staticProvider struct {
+	config string
+}
+
+func (p staticProvider) GenerateConfig(parsed *nlp.ParsedInput) (string, error) {
+	return p.config, nil
+}
+
+func (p staticProvider) Complete(ctx context.Context, prompt string) (string, error) {
+	return "", nil
+}
+
+func writeBatchFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "batch.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write batch file: %v", err)
+	}
+	return path
+}
+
+func TestLoadBatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantIDs []string
+		wantErr string
+	}{
+		{
+			name: "blank lines and comments are skipped",
+			content: `
+# first batch
+{"id": "bucket", "description": "Create an encrypted S3 bucket"}
+
+{"description": "Create a VPC"}
+`,
+			wantIDs: []string{"bucket", "line-5"},
+		},
+		{
+			name:    "backlog lines",
+			content: `{"request_id": "user-001", "title": "Bucket", "body": "Create an encrypted S3 bucket"}`,
+			wantIDs: []string{"user-001"},
+		},
+		{
+			name:    "unsafe ids are sanitized",
+			content: `{"id": "../etc/passwd", "description": "Create a VPC"}` + "\n" + `{"id": "web app!", "description": "Create a VPC"}`,
+			wantIDs: []string{"etc-passwd", "web-app"},
+		},
+		{
+			name:    "bad JSON",
+			content: `{"id": "bucket", "description": "Create an encrypted S3 bucket"}` + "\n" + `{"id": "broken"`,
+			wantErr: "batch line 2:",
+		},
+		{
+			name:    "duplicate id",
+			content: `{"id": "bucket", "description": "one"}` + "\n" + `{"id": "bucket", "description": "two"}`,
+			wantErr: `batch line 2: duplicate id "bucket"`,
+		},
+		{
+			name:    "duplicate after sanitizing",
+			content: `{"id": "web app", "description": "one"}` + "\n" + `{"id": "web/app", "description": "two"}`,
+			wantErr: `batch line 2: duplicate id "web-app"`,
+		},
+		{
+			name:    "id without usable characters",
+			content: `{"id": "../", "description": "Create a VPC"}`,
+			wantErr: "batch line 1: id has no usable characters",
+		},
+		{
+			name:    "missing description",
+			content: `{"id": "empty", "description": "  "}`,
+			wantErr: "batch line 1: description is required",
+		},
+		{
+			name:    "no descriptions",
+			content: "\n# nothing here\n",
+			wantErr: "has no descriptions",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := loadBatch(writeBatchFile(t, tt.content))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadBatch() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadBatch() error = %v", err)
+			}
+
+			var ids []string
+			for _, item := range items {
+				ids = append(ids, item.ID)
+				if item.Description == "" {
+					t.Errorf("item %s has no description", item.ID)
+				}
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.wantIDs, ",") {
+				t.Errorf("loadBatch() ids = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+
+	if _, err := loadBatch(filepath.Join(t.TempDir(), "missing.jsonl")); err == nil {
+		t.Error("loadBatch() accepted a missing file")
+	}
+}
+
+func TestSummarizeBatch(t *testing.T) {
+	summary := summarizeBatch([]batchResult{
+		{ID: "a", Success: true, Issues: map[string]int{"HIGH": 1, "LOW": 2}, TotalCost: 10.5},
+		{ID: "b", Success: false, Error: "high severity security issues found", Issues: map[string]int{"HIGH": 2}, TotalCost: 25},
+		{ID: "c", Success: true, Issues: map[string]int{}},
+	})
+
+	if summary.Total != 3 || summary.Succeeded != 2 || summary.Failed != 1 {
+		t.Errorf("summary counts = %d/%d/%d, want 3/2/1", summary.Total, summary.Succeeded, summary.Failed)
+	}
+	if summary.Issues["HIGH"] != 3 || summary.Issues["LOW"] != 2 || summary.Issues["CRITICAL"] != 0 {
+		t.Errorf("summary issues = %v, want 3 high and 2 low", summary.Issues)
+	}
+	if summary.TotalCost != 35.5 {
+		t.Errorf("summary total cost = %.2f, want 35.50", summary.TotalCost)
+	}
+
+	table := summary.Table()
+	for _, want := range []string{"high severity security issues found", "3 descriptions, 2 succeeded, 1 failed, 3 high, 2 low issues, $35.50/month estimated"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("Table() is missing %q:\n%s", want, table)
+		}
+	}
+}
+
+func TestProcessBatchItemKeepsOutputOnPolicyFailure(t *testing.T) {
+	viper.Set("security.scan_enabled", true)
+	viper.Set("security.fail_on_high", true)
+	defer viper.Set("security.fail_on_high", false)
+
+	config := `resource "aws_instance" "web" {
+  instance_type = "t3.micro"
+}
+
+resource "aws_s3_bucket" "public" {
+  bucket = "public-assets"
+  acl    = "public-read"
+}
+`
+	dir := filepath.Join(t.TempDir(), "bucket")
+	engine := nlp.NewEngine()
+	result := processBatchItem(batchItem{ID: "bucket", Description: "Create a public S3 bucket and a web server"}, dir,
+		engine, engine, staticProvider{config: config}, terraform.NewGenerator(), security.NewScanner())
+
+	if result.Success || result.Error != "high severity security issues found" {
+		t.Fatalf("processBatchItem() success = %v, error = %q, want the failure policy error", result.Success, result.Error)
+	}
+	if result.Issues["HIGH"] == 0 {
+		t.Errorf("processBatchItem() issues = %v, want high severity issues", result.Issues)
+	}
+	if result.Costs["EC2 Instances"] != 7.6 || result.TotalCost == 0 {
+		t.Errorf("processBatchItem() costs = %v (total %.2f), want the estimate", result.Costs, result.TotalCost)
+	}
+	if result.OutputDir != dir || strings.Join(result.Files, ",") != "main.tf" {
+		t.Errorf("processBatchItem() output = %s %v, want %s [main.tf]", result.OutputDir, result.Files, dir)
+	}
+	if written, err := os.ReadFile(filepath.Join(dir, "main.tf")); err != nil || !strings.Contains(string(written), "public-read") {
+		t.Errorf("main.tf was not written: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/RyanSStephens/TF-NLP-Agent/internal/security"
+	"github.com/spf13/cobra"
+)
+
+var complianceCmd = &cobra.Command{
+	Use:   "compliance [file]",
+	Short: "Report the controls of a compliance framework a configuration passes",
+	Long: `Scan a Terraform configuration file and report pass, fail or not applicable
+for every control of a control framework, from the security rules mapped to
+each control. A control is not applicable when none of its rules inspects a
+resource of the configuration. Suppressed findings are listed but do not fail
+a control.
+
+Frameworks: ` + strings.Join(security.ControlFrameworks(), ", ") + `
+
+Example:
+  tf-nlp-agent compliance --framework cis-aws main.tf
+  tf-nlp-agent compliance --framework hipaa --format html -o report.html main.tf`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		framework, _ := cmd.Flags().GetString("framework")
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		if format != "markdown" && format != "json" && format != "html" {
+			return fmt.Errorf("unsupported format: %s (use markdown, json or html)", format)
+		}
+
+		filename := args[0]
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+
+		securityScanner, err := newScanner()
+		if err != nil {
+			return err
+		}
+
+		report, err := securityScanner.ComplianceReport(string(content), filename, framework)
+		if err != nil {
+			return fmt.Errorf("compliance report failed: %w", err)
+		}
+
+		var rendered string
+		switch format {
+		case "json":
+			data, err := report.JSON()
+			if err != nil {
+				return fmt.Errorf("failed to encode report: %w", err)
+			}
+			rendered = string(data) + "\n"
+		case "html":
+			rendered, err = report.HTML()
+			if err != nil {
+				return err
+			}
+		default:
+			rendered = report.Markdown()
+		}
+
+		if output == "" {
+			fmt.Print(rendered)
+			return nil
+		}
+		if err := os.WriteFile(output, []byte(rendered), 0644); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		fmt.Printf("Compliance report written to: %s (%d passed, %d failed, %d not applicable)\n",
+			output, report.Summary.Passed, report.Summary.Failed, report.Summary.NotApplicable)
+		return nil
+	},
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/RyanSStephens/TF-NLP-Agent/internal/ai"
+	"github.com/RyanSStephens/TF-NLP-Agent/internal/nlp"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var evalParserCmd = &cobra.Command{
+	Use:   "eval-parser",
+	Short: "Measure parser accuracy against a labeled corpus",
+	Long: `Run the configured parser over a JSONL corpus of labeled descriptions and
+report precision and recall per field (provider, resource types, attributes,
+intent, requirements). Exits with an error when any field falls below the
+thresholds.
+
+Example:
+  tf-nlp-agent eval-parser --corpus internal/nlp/testdata/corpus.jsonl --min-recall 0.9`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		corpusPath, _ := cmd.Flags().GetString("corpus")
+		minPrecision, _ := cmd.Flags().GetFloat64("min-precision")
+		minRecall, _ := cmd.Flags().GetFloat64("min-recall")
+		format, _ := cmd.Flags().GetString("format")
+
+		cases, err := nlp.LoadCorpus(corpusPath)
+		if err != nil {
+			return err
+		}
+
+		engine, err := newEngine()
+		if err != nil {
+			return err
+		}
+		parser, err := newParser(engine, ai.NewProvider(viper.GetString("ai.provider")))
+		if err != nil {
+			return err
+		}
+
+		report := nlp.Evaluate(parser, cases)
+
+		switch format {
+		case "json":
+			output, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode report: %w", err)
+			}
+			fmt.Println(string(output))
+		case "table":
+			fmt.Print(report.Table())
+			if len(report.Mismatches) > 0 {
+				fmt.Println("\nMismatches:")
+				for _, mismatch := range report.Mismatches {
+					fmt.Printf("  - %s %s: missing %v, spurious %v\n", mismatch.CaseID, mismatch.Field, mismatch.Missing, mismatch.Spurious)
+				}
+			}
+		default:
+			return fmt.Errorf("unsupported format: %s (use table or json)", format)
+		}
+
+		if failures := report.Failures(minPrecision, minRecall); len(failures) > 0 {
+			return fmt.Errorf("parser accuracy below thresholds:\n  %s", strings.Join(failures, "\n  "))
+		}
+
+		return nil
+	},
+}
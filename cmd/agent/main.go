@@ -1,8 +1,13 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
 
 	"github.com/RyanSStephens/TF-NLP-Agent/internal/ai"
 	"github.com/RyanSStephens/TF-NLP-Agent/internal/nlp"
@@ -38,27 +43,88 @@ var generateCmd = &cobra.Command{
 	Short: "Generate Terraform configuration from natural language",
 	Long: `Generate Terraform configuration from a natural language description.
 	
+With --batch, every description in a JSONL file is generated, validated and
+scanned, each into its own directory below --output-dir, followed by a summary.
+
 Example:
-  tf-nlp-agent generate "Create an AWS VPC with public and private subnets"`,
-	Args: cobra.ExactArgs(1),
+  tf-nlp-agent generate "Create an AWS VPC with public and private subnets"
+  tf-nlp-agent generate --batch requests.jsonl --concurrency 8 --output-dir ./out
+  tf-nlp-agent generate -o main.tf --sarif results.sarif "Create an encrypted S3 bucket"`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if batch, _ := cmd.Flags().GetString("batch"); batch != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if batch, _ := cmd.Flags().GetString("batch"); batch != "" {
+			if sarifFile, _ := cmd.Flags().GetString("sarif"); sarifFile != "" {
+				return fmt.Errorf("--sarif cannot be combined with --batch; issue counts are reported in summary.json")
+			}
+			return runBatch(cmd, batch)
+		}
+
 		description := args[0]
 
 		// Initialize components
 		aiProvider := ai.NewProvider(viper.GetString("ai.provider"))
-		nlpEngine := nlp.NewEngine()
+		engine, err := newEngine()
+		if err != nil {
+			return err
+		}
+		parser, err := newParser(engine, aiProvider)
+		if err != nil {
+			return err
+		}
 		tfGenerator := terraform.NewGenerator()
-		securityScanner := security.NewScanner()
+		securityScanner, err := newScanner()
+		if err != nil {
+			return err
+		}
 
 		// Process the description
 		fmt.Printf("Processing: %s\n", description)
 
 		// Parse the natural language input
-		parsed, err := nlpEngine.Parse(description)
+		parsed, err := parser.Parse(description)
 		if err != nil {
 			return fmt.Errorf("failed to parse description: %w", err)
 		}
 
+		for _, correction := range parsed.Corrections {
+			fmt.Printf("Interpreted %q as %q\n", correction.From, correction.To)
+		}
+
+		// Resolve ambiguities before spending a model call
+		if questions := engine.Clarify(parsed); len(questions) > 0 {
+			answers := nlp.DefaultAnswers(questions)
+			noClarify, _ := cmd.Flags().GetBool("no-clarify")
+			if !noClarify && isInteractive() {
+				answers = askClarifyingQuestions(bufio.NewReader(os.Stdin), questions)
+			} else {
+				fmt.Println("Using suggested defaults for ambiguous details:")
+				for _, question := range questions {
+					fmt.Printf("  - %s -> %s\n", question.Text, question.Default)
+				}
+			}
+
+			if err := engine.ApplyAnswers(parsed, answers); err != nil {
+				return fmt.Errorf("failed to apply clarifications: %w", err)
+			}
+		}
+
+		// Preview the extracted topology before calling the model
+		switch graphFormat := cmd.Flag("graph").Value.String(); graphFormat {
+		case "":
+		case "text":
+			fmt.Println("\nResource graph:")
+			fmt.Print(parsed.Graph().Text())
+		case "dot":
+			fmt.Print(parsed.Graph().DOT())
+		default:
+			return fmt.Errorf("unsupported graph format: %s (use text or dot)", graphFormat)
+		}
+
 		// Generate Terraform configuration using AI
 		config, err := aiProvider.GenerateConfig(parsed)
 		if err != nil {
@@ -73,21 +139,60 @@ Example:
 
 		// Security scan if enabled
 		if viper.GetBool("security.scan_enabled") {
-			issues, err := securityScanner.Scan(validated)
+			result, complianceIssues, err := scanConfiguration(securityScanner, validated, parsed)
 			if err != nil {
-				return fmt.Errorf("security scan failed: %w", err)
+				return err
 			}
 
-			if len(issues) > 0 {
-				fmt.Println("Security issues found:")
-				for _, issue := range issues {
-					fmt.Printf("  - %s: %s\n", issue.Severity, issue.Message)
+			if sarifFile, _ := cmd.Flags().GetString("sarif"); sarifFile != "" {
+				artifact := cmd.Flag("output").Value.String()
+				if artifact == "" {
+					artifact = "main.tf"
 				}
-
-				if viper.GetBool("security.fail_on_high") && hasHighSeverityIssues(issues) {
-					return fmt.Errorf("high severity security issues found")
+				if err := writeSARIF(securityScanner, result, artifact, sarifFile); err != nil {
+					return err
 				}
 			}
+
+			printScanResult(result)
+			if err := checkFailurePolicy(result.Issues, complianceIssues); err != nil {
+				return err
+			}
+		}
+
+		// Check the estimated cost against a stated budget
+		if parsed.Budget != nil {
+			costs, err := tfGenerator.EstimateCost(validated)
+			if err != nil {
+				return fmt.Errorf("cost estimation failed: %w", err)
+			}
+			if warning := terraform.BudgetWarning(costs, parsed.Budget); warning != "" {
+				fmt.Printf("Warning: %s\n", warning)
+			}
+		}
+
+		// Several environments get a shared module plus a root per environment
+		if environments := terraform.EnvironmentsFrom(parsed); len(environments) > 1 {
+			layout, err := tfGenerator.SplitEnvironments(validated, environments)
+			if err != nil {
+				return fmt.Errorf("failed to split configuration into environments: %w", err)
+			}
+			for _, warning := range layout.Warnings {
+				fmt.Printf("Warning: %s\n", warning)
+			}
+
+			outputDir := cmd.Flag("output-dir").Value.String()
+			if outputDir == "" {
+				outputDir = viper.GetString("terraform.output_dir")
+			}
+			if err := layout.Write(outputDir); err != nil {
+				return err
+			}
+			fmt.Printf("Configuration for %d environments written to: %s\n", len(environments), outputDir)
+			for _, path := range layout.Paths() {
+				fmt.Printf("  %s\n", path)
+			}
+			return nil
 		}
 
 		// Output the configuration
@@ -115,7 +220,34 @@ var serveCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		port := cmd.Flag("port").Value.String()
 
-		server := web.NewServer()
+		engine, err := newEngine()
+		if err != nil {
+			return err
+		}
+		parser, err := newParser(engine, ai.NewProvider(viper.GetString("ai.provider")))
+		if err != nil {
+			return err
+		}
+
+		// Reload the vocabulary on SIGHUP without restarting the server
+		reload := make(chan os.Signal, 1)
+		signal.Notify(reload, syscall.SIGHUP)
+		go func() {
+			for range reload {
+				if err := engine.Reload(viper.GetStringSlice("nlp.vocabulary_paths")...); err != nil {
+					fmt.Fprintf(os.Stderr, "Vocabulary reload failed, keeping the current vocabulary: %v\n", err)
+					continue
+				}
+				fmt.Println("Vocabulary reloaded")
+			}
+		}()
+
+		securityScanner, err := newScanner()
+		if err != nil {
+			return err
+		}
+
+		server := web.NewServer(engine, parser, securityScanner)
 		fmt.Printf("Starting web server on port %s\n", port)
 		fmt.Printf("Open your browser to http://localhost:%s\n", port)
 
@@ -126,8 +258,21 @@ var serveCmd = &cobra.Command{
 var validateCmd = &cobra.Command{
 	Use:   "validate [file]",
 	Short: "Validate a Terraform configuration file",
-	Long:  "Validate syntax and security of an existing Terraform configuration file.",
-	Args:  cobra.ExactArgs(1),
+	Long: `Validate syntax and security of an existing Terraform configuration file.
+
+With --sarif, the security findings are also written as SARIF 2.1.0 for code
+scanning dashboards; --sarif - prints only the SARIF log.
+
+With --fix, issues that have an automatic fix are rewritten in the file:
+encryption is enabled, public access is turned off and literal secrets are
+replaced with sensitive variables. The fixes and a diff are printed, and the
+remaining issues are reported as usual.
+
+Example:
+  tf-nlp-agent validate main.tf
+  tf-nlp-agent validate --fix main.tf
+  tf-nlp-agent validate --sarif - main.tf > results.sarif`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		filename := args[0]
 
@@ -137,7 +282,10 @@ var validateCmd = &cobra.Command{
 		}
 
 		tfGenerator := terraform.NewGenerator()
-		securityScanner := security.NewScanner()
+		securityScanner, err := newScanner()
+		if err != nil {
+			return err
+		}
 
 		// Validate syntax
 		_, err = tfGenerator.Validate(string(content))
@@ -145,22 +293,42 @@ var validateCmd = &cobra.Command{
 			return fmt.Errorf("validation failed: %w", err)
 		}
 
+		if fix, _ := cmd.Flags().GetBool("fix"); fix {
+			remediation, err := securityScanner.Remediate(string(content), filepath.Base(filename))
+			if err != nil {
+				return fmt.Errorf("remediation failed: %w", err)
+			}
+			if len(remediation.Fixes) > 0 {
+				if err := os.WriteFile(filename, []byte(remediation.Configuration), 0644); err != nil {
+					return fmt.Errorf("failed to write fixed configuration: %w", err)
+				}
+				content = []byte(remediation.Configuration)
+			}
+			printRemediation(remediation, cmd.Flag("sarif").Value.String() == "-")
+		}
+
 		// Security scan
-		issues, err := securityScanner.Scan(string(content))
+		result, err := securityScanner.Scan(string(content))
 		if err != nil {
 			return fmt.Errorf("security scan failed: %w", err)
 		}
 
+		if sarifFile, _ := cmd.Flags().GetString("sarif"); sarifFile != "" {
+			if err := writeSARIF(securityScanner, result, filename, sarifFile); err != nil {
+				return err
+			}
+			// The SARIF log is the only output on stdout
+			if sarifFile == "-" {
+				return nil
+			}
+		}
+
 		fmt.Printf("Validation successful for: %s\n", filename)
 
-		if len(issues) > 0 {
-			fmt.Println("Security issues found:")
-			for _, issue := range issues {
-				fmt.Printf("  - %s: %s\n", issue.Severity, issue.Message)
-			}
-		} else {
+		if len(result.Issues) == 0 {
 			fmt.Println("No security issues found.")
 		}
+		printScanResult(result)
 
 		return nil
 	},
@@ -173,14 +341,45 @@ func init() {
 
 	// Generate command flags
 	generateCmd.Flags().StringP("output", "o", "", "output file for generated configuration")
+	generateCmd.Flags().String("output-dir", "", "output directory when several environments are described (default terraform.output_dir)")
 	generateCmd.Flags().StringP("provider", "p", "aws", "cloud provider (aws, azure, gcp)")
+	generateCmd.Flags().Bool("no-clarify", false, "skip clarifying questions and use suggested defaults")
+	generateCmd.Flags().String("graph", "", "preview the resource graph before generation (text, dot)")
+	generateCmd.Flags().String("batch", "", "JSONL file of descriptions to generate, one result directory each below --output-dir")
+	generateCmd.Flags().Int("concurrency", 4, "number of batch descriptions processed at once")
+	generateCmd.Flags().String("format", "table", "batch summary format (table, json)")
+	generateCmd.Flags().String("sarif", "", "write security findings as SARIF 2.1.0 to this file")
+
+	// Eval-parser command flags
+	evalParserCmd.Flags().String("corpus", "", "JSONL corpus of labeled descriptions")
+	evalParserCmd.Flags().Float64("min-precision", 0.9, "minimum precision required for every field")
+	evalParserCmd.Flags().Float64("min-recall", 0.9, "minimum recall required for every field")
+	evalParserCmd.Flags().String("format", "table", "report format (table, json)")
+	_ = evalParserCmd.MarkFlagRequired("corpus")
+
+	// Validate command flags
+	validateCmd.Flags().String("sarif", "", "write security findings as SARIF 2.1.0 to this file (- for stdout)")
+	validateCmd.Flags().Bool("fix", false, "rewrite the file to fix issues that have an automatic fix")
+
+	// Compliance command flags
+	complianceCmd.Flags().String("framework", "", "control framework to report on ("+strings.Join(security.ControlFrameworks(), ", ")+")")
+	complianceCmd.Flags().String("format", "markdown", "report format (markdown, json, html)")
+	complianceCmd.Flags().StringP("output", "o", "", "write the report to this file instead of stdout")
+	_ = complianceCmd.MarkFlagRequired("framework")
+
+	// Parse command flags
+	parseCmd.Flags().String("format", "table", "output format (table, json)")
+	parseCmd.Flags().Bool("no-prompt", false, "omit the generation prompt")
 
 	// Serve command flags
 	serveCmd.Flags().StringP("port", "p", "8080", "port to run the web server on")
 
 	rootCmd.AddCommand(generateCmd)
+	rootCmd.AddCommand(parseCmd)
 	rootCmd.AddCommand(serveCmd)
 	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(evalParserCmd)
+	rootCmd.AddCommand(complianceCmd)
 }
 
 func initConfig() {
@@ -201,11 +400,15 @@ func initConfig() {
 	// Set defaults
 	viper.SetDefault("ai.provider", "openai")
 	viper.SetDefault("ai.model", "gpt-4")
+	viper.SetDefault("nlp.parser", nlp.ParserKeyword)
+	viper.SetDefault("nlp.fuzzy_tolerance", nlp.DefaultFuzzyTolerance)
 	viper.SetDefault("terraform.default_provider", "aws")
 	viper.SetDefault("terraform.validate", true)
 	viper.SetDefault("terraform.format", true)
+	viper.SetDefault("terraform.output_dir", "./output")
 	viper.SetDefault("security.scan_enabled", true)
 	viper.SetDefault("security.fail_on_high", false)
+	viper.SetDefault("security.fail_on_compliance", true)
 	viper.SetDefault("templates.path", "./templates")
 
 	if err := viper.ReadInConfig(); err == nil {
@@ -213,6 +416,133 @@ func initConfig() {
 	}
 }
 
+// newEngine creates the keyword engine with the vocabulary files from nlp.vocabulary_paths
+func newEngine() (*nlp.Engine, error) {
+	engine := nlp.NewEngine()
+	engine.SetFuzzyTolerance(viper.GetInt("nlp.fuzzy_tolerance"))
+	if err := engine.Reload(viper.GetStringSlice("nlp.vocabulary_paths")...); err != nil {
+		return nil, fmt.Errorf("failed to load vocabulary: %w", err)
+	}
+	return engine, nil
+}
+
+// newScanner creates the security scanner with the rule files from
+// security.custom_rules, the Rego policies from security.policy_paths and the
+// accepted findings from security.baseline
+func newScanner() (*security.Scanner, error) {
+	scanner := security.NewScanner()
+	if err := scanner.LoadRules(viper.GetStringSlice("security.custom_rules")...); err != nil {
+		return nil, fmt.Errorf("failed to load custom security rules: %w", err)
+	}
+	if err := scanner.LoadPolicies(viper.GetStringSlice("security.policy_paths")...); err != nil {
+		return nil, fmt.Errorf("failed to load security policies: %w", err)
+	}
+	if err := scanner.LoadBaseline(viper.GetString("security.baseline")); err != nil {
+		return nil, err
+	}
+	if err := scanner.AllowSecrets(viper.GetStringSlice("security.secret_allowlist")...); err != nil {
+		return nil, err
+	}
+	return scanner, nil
+}
+
+// printRemediation lists the fixes applied and the diff. With quiet, as
+// when stdout carries a SARIF log, the fixes go to stderr and the diff is
+// left out.
+func printRemediation(remediation *security.Remediation, quiet bool) {
+	out := os.Stdout
+	if quiet {
+		out = os.Stderr
+	}
+	if len(remediation.Fixes) == 0 {
+		fmt.Fprintln(out, "No automatic fixes to apply.")
+		return
+	}
+
+	fmt.Fprintf(out, "Applied %d fixes:\n", len(remediation.Fixes))
+	for _, fix := range remediation.Fixes {
+		fmt.Fprintf(out, "  - %s %s: %s\n", fix.Rule, fix.Resource, fix.Description)
+	}
+	if !quiet {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, remediation.Diff)
+	}
+}
+
+// writeSARIF writes the issues found in the file at artifact as a SARIF log
+// to output, or to stdout for "-"
+func writeSARIF(scanner *security.Scanner, result *security.ScanResult, artifact, output string) error {
+	data, err := scanner.SARIF(result, artifact, version).JSON()
+	if err != nil {
+		return fmt.Errorf("failed to encode SARIF: %w", err)
+	}
+
+	if output == "-" {
+		fmt.Println(string(data))
+		return nil
+	}
+	if err := os.WriteFile(output, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write SARIF file: %w", err)
+	}
+	return nil
+}
+
+// newParser creates the NLP parser selected by the nlp.parser setting
+func newParser(engine *nlp.Engine, aiProvider ai.Provider) (nlp.Parser, error) {
+	parser, err := nlp.NewParser(viper.GetString("nlp.parser"), engine, aiProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parser: %w", err)
+	}
+	return parser, nil
+}
+
+// scanConfiguration runs the security rules, the requirement checks and the
+// compliance rule sets of the parsed frameworks over a configuration. The
+// compliance issues are also returned on their own for the failure policy.
+func scanConfiguration(scanner *security.Scanner, config string, parsed *nlp.ParsedInput) (*security.ScanResult, []security.Issue, error) {
+	result, err := scanner.Scan(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("security scan failed: %w", err)
+	}
+	result.Issues = append(result.Issues, scanner.CheckRequirements(config, parsed.Requirements)...)
+
+	complianceIssues, err := scanner.CheckCompliance(config, parsed.FrameworkIDs())
+	if err != nil {
+		return nil, nil, fmt.Errorf("compliance check failed: %w", err)
+	}
+	result.Issues = append(result.Issues, complianceIssues...)
+
+	return result, complianceIssues, nil
+}
+
+// printScanResult lists the issues of a scan and the suppressed ones with
+// their reasons
+func printScanResult(result *security.ScanResult) {
+	if len(result.Issues) > 0 {
+		fmt.Println("Security issues found:")
+		for _, issue := range result.Issues {
+			fmt.Printf("  - %s: %s (%s)\n", issue.Severity, issue.Message, issue.Location())
+		}
+	}
+	if len(result.Suppressed) > 0 {
+		fmt.Println("Suppressed issues:")
+		for _, issue := range result.Suppressed {
+			fmt.Printf("  - %s %s: %s (%s)\n", issue.Rule, issue.Location(), issue.Reason, issue.Source)
+		}
+	}
+}
+
+// checkFailurePolicy applies security.fail_on_high and security.fail_on_compliance
+func checkFailurePolicy(issues, complianceIssues []security.Issue) error {
+	if viper.GetBool("security.fail_on_high") && hasHighSeverityIssues(issues) {
+		return fmt.Errorf("high severity security issues found")
+	}
+	if viper.GetBool("security.fail_on_compliance") && len(complianceIssues) > 0 {
+		return fmt.Errorf("configuration violates %d compliance controls", len(complianceIssues))
+	}
+	return nil
+}
+
 func hasHighSeverityIssues(issues []security.Issue) bool {
 	for _, issue := range issues {
 		if issue.Severity == "HIGH" || issue.Severity == "CRITICAL" {
@@ -221,3 +551,42 @@ func hasHighSeverityIssues(issues []security.Issue) bool {
 	}
 	return false
 }
+
+// isInteractive reports whether stdin is attached to a terminal
+func isInteractive() bool {
+	stat, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
+
+// askClarifyingQuestions prompts for each question, falling back to its default on empty input
+func askClarifyingQuestions(reader *bufio.Reader, questions []nlp.Question) map[string]string {
+	answers := make(map[string]string)
+	fmt.Println("The description is ambiguous. Please answer a few questions (press Enter for the default):")
+
+	for _, question := range questions {
+		// The suggested region follows the provider picked earlier
+		if question.ID == nlp.QuestionRegion {
+			if provider, ok := answers[nlp.QuestionCloudProvider]; ok {
+				question.Default = nlp.DefaultRegion(provider)
+			}
+		}
+
+		prompt := question.Text
+		if len(question.Options) > 0 {
+			prompt += " [" + strings.Join(question.Options, "/") + "]"
+		}
+		fmt.Printf("%s (default: %s): ", prompt, question.Default)
+
+		answer, _ := reader.ReadString('\n')
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			answer = question.Default
+		}
+		answers[question.ID] = answer
+	}
+
+	return answers
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/RyanSStephens/TF-NLP-Agent/internal/ai"
+	"github.com/RyanSStephens/TF-NLP-Agent/internal/nlp"
+	"github.com/spf13/cobra"
+)
+
+var parseCmd = &cobra.Command{
+	Use:   "parse [description]",
+	Short: "Show how a description is interpreted, without generating",
+	Long: `Parse a description with the keyword engine and print the structured
+interpretation (provider, resources, attributes, requirements, intent,
+entities) together with the exact prompt generation would send. No language
+model is called. Ambiguities are resolved with the suggested defaults, as with
+generate --no-clarify, and the questions are listed.
+
+Example:
+  tf-nlp-agent parse "Web servers behind an ALB with a private Postgres database"
+  tf-nlp-agent parse --format json "Create an encrypted S3 bucket"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		noPrompt, _ := cmd.Flags().GetBool("no-prompt")
+		if format != "table" && format != "json" {
+			return fmt.Errorf("unsupported format: %s (use table or json)", format)
+		}
+
+		engine, err := newEngine()
+		if err != nil {
+			return err
+		}
+
+		parsed, err := engine.Parse(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to parse description: %w", err)
+		}
+
+		questions := engine.Clarify(parsed)
+		if err := engine.ApplyAnswers(parsed, nlp.DefaultAnswers(questions)); err != nil {
+			return fmt.Errorf("failed to apply clarifications: %w", err)
+		}
+
+		prompt := ""
+		if !noPrompt {
+			prompt = ai.BuildPrompt(parsed)
+		}
+
+		if format == "json" {
+			output, err := json.MarshalIndent(struct {
+				Parsed    *nlp.ParsedInput `json:"parsed"`
+				Questions []nlp.Question   `json:"questions,omitempty"`
+				Prompt    string           `json:"prompt,omitempty"`
+			}{parsed, questions, prompt}, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode parsed input: %w", err)
+			}
+			fmt.Println(string(output))
+			return nil
+		}
+
+		fmt.Print(parsedTable(parsed, questions))
+		if prompt != "" {
+			fmt.Println("\nPrompt:")
+			fmt.Println(prompt)
+		}
+		return nil
+	},
+}
+
+// parsedTable renders the parsed input as readable sections
+func parsedTable(parsed *nlp.ParsedInput, questions []nlp.Question) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("%-16s %s\n", "Provider:", parsed.CloudProvider))
+	b.WriteString(fmt.Sprintf("%-16s %s\n", "Intent:", parsed.Intent))
+	b.WriteString(fmt.Sprintf("%-16s %s\n", "Language:", nlp.LanguageName(parsed.Language)))
+	if ids := parsed.FrameworkIDs(); len(ids) > 0 {
+		b.WriteString(fmt.Sprintf("%-16s %s\n", "Frameworks:", strings.Join(ids, ", ")))
+	}
+	if environments := parsed.EnvironmentNames(); len(environments) > 0 {
+		b.WriteString(fmt.Sprintf("%-16s %s\n", "Environments:", strings.Join(environments, ", ")))
+	}
+	if parsed.Budget != nil {
+		b.WriteString(fmt.Sprintf("%-16s %s\n", "Budget:", parsed.Budget))
+	}
+	if tier := parsed.SizeTier(); tier != "" {
+		b.WriteString(fmt.Sprintf("%-16s %s\n", "Size:", tier))
+	}
+
+	b.WriteString("\nResources:\n")
+	if len(parsed.Resources) == 0 {
+		b.WriteString("  (none)\n")
+	} else {
+		b.WriteString(fmt.Sprintf("  %-12s %-16s %-32s %s\n", "TYPE", "NAME", "ATTRIBUTES", "TEXT"))
+		for _, resource := range parsed.Resources {
+			b.WriteString(fmt.Sprintf("  %-12s %-16s %-32s %s\n", resource.Type, resource.Name, strings.Join(resource.Attributes, ","), quoteSpan(parsed, resource.Span)))
+		}
+	}
+
+	if len(parsed.Requirements) > 0 {
+		b.WriteString("\nRequirements:\n")
+		b.WriteString(fmt.Sprintf("  %-14s %-40s %-12s %s\n", "CATEGORY", "CONSTRAINT", "SCOPE", "TEXT"))
+		for _, requirement := range parsed.Requirements {
+			scope := requirement.Environment
+			if requirement.Framework != "" {
+				scope = requirement.Framework
+			}
+			if scope == "" {
+				scope = "-"
+			}
+			b.WriteString(fmt.Sprintf("  %-14s %-40s %-12s %s\n", requirement.Category, requirement.Constraint(), scope, quoteSpan(parsed, requirement.Span)))
+		}
+	}
+
+	if len(parsed.Relations) > 0 {
+		b.WriteString("\nRelations:\n")
+		for _, line := range strings.Split(strings.TrimSuffix(parsed.Graph().Text(), "\n"), "\n") {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+
+	if entities := entityLines(parsed.Entities); len(entities) > 0 {
+		b.WriteString("\nEntities:\n")
+		for _, entity := range entities {
+			b.WriteString("  " + entity + "\n")
+		}
+	}
+
+	if len(parsed.Corrections) > 0 {
+		b.WriteString("\nCorrections:\n")
+		for _, correction := range parsed.Corrections {
+			b.WriteString(fmt.Sprintf("  %q -> %q\n", correction.From, correction.To))
+		}
+	}
+
+	if len(questions) > 0 {
+		b.WriteString("\nAmbiguities (suggested defaults applied):\n")
+		for _, question := range questions {
+			b.WriteString(fmt.Sprintf("  %s -> %s\n", question.Text, question.Default))
+		}
+	}
+
+	return b.String()
+}
+
+// entityLines renders the typed entities as "kind: value" lines
+func entityLines(entities nlp.Entities) []string {
+	var lines []string
+
+	for _, region := range entities.Regions {
+		lines = append(lines, "region: "+region.Name)
+	}
+	for _, zone := range entities.AvailabilityZones {
+		lines = append(lines, "availability zone: "+zone.Name)
+	}
+	for _, cidr := range entities.CIDRBlocks {
+		lines = append(lines, "cidr block: "+cidr.Value)
+	}
+	for _, instanceType := range entities.InstanceTypes {
+		lines = append(lines, "instance type: "+instanceType.Name)
+	}
+	for _, version := range entities.EngineVersions {
+		lines = append(lines, fmt.Sprintf("engine version: %s %s", version.Engine, version.Version))
+	}
+	for _, port := range entities.Ports {
+		if port.From == port.To {
+			lines = append(lines, fmt.Sprintf("port: %d", port.From))
+		} else {
+			lines = append(lines, fmt.Sprintf("port range: %d-%d", port.From, port.To))
+		}
+	}
+	for _, duration := range entities.Durations {
+		lines = append(lines, strings.TrimSpace(fmt.Sprintf("duration: %d %s %s", duration.Value, duration.Unit, duration.Subject)))
+	}
+	for _, name := range entities.Names {
+		lines = append(lines, "name: "+name.Value)
+	}
+	for _, tag := range entities.Tags {
+		lines = append(lines, fmt.Sprintf("tag: %s=%s", tag.Key, tag.Value))
+	}
+
+	return lines
+}
+
+// quoteSpan returns the quoted input text a span covers, or "-" for values
+// that did not come from the input
+func quoteSpan(parsed *nlp.ParsedInput, span nlp.Span) string {
+	if text := parsed.Text(span); text != "" {
+		return fmt.Sprintf("%q", text)
+	}
+	return "-"
+}
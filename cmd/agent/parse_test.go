@@ -0,0 +1,33 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/RyanSStephens/TF-NLP-Agent/internal/nlp"
+)
+
+func TestParsedTable(t *testing.T) {
+	engine := nlp.NewEngine()
+
+	parsed, err := engine.Parse("set up a public and private network with a database on aws in us-west-2")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	questions := engine.Clarify(parsed)
+	if err := engine.ApplyAnswers(parsed, nlp.DefaultAnswers(questions)); err != nil {
+		t.Fatalf("ApplyAnswers() error = %v", err)
+	}
+
+	table := parsedTable(parsed, questions)
+	for _, want := range []string{"Provider:        aws", "Resources:", "region: us-west-2", "Ambiguities (suggested defaults applied):"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("parsedTable() is missing %q:\n%s", want, table)
+		}
+	}
+
+	parsed, _ = engine.Parse("hello")
+	if table := parsedTable(parsed, nil); !strings.Contains(table, "  (none)\n") || strings.Contains(table, "Ambiguities") {
+		t.Errorf("parsedTable() without resources or questions:\n%s", table)
+	}
+}
@@ -0,0 +1,31 @@
+# Example Rego policy. Load it with:
+#
+#   security:
+#     policy_paths: ["examples/policies"]
+#
+# Packages below tfnlp may define deny and warn rules. The input holds the
+# configuration keyed like Terraform's JSON syntax (input.resource.aws_s3_bucket)
+# and a flat list of resources with their address, type, name and config.
+package tfnlp.s3
+
+import future.keywords.contains
+import future.keywords.if
+import future.keywords.in
+
+deny contains result if {
+	some r in input.resources
+	r.type == "aws_s3_bucket"
+	not r.config.tags.DataClass
+	result := {
+		"msg": sprintf("%s has no DataClass tag", [r.address]),
+		"resource": r.address,
+		"rule": "POL-S3-001",
+		"remediation": "Tag every bucket with the classification of the data it holds",
+	}
+}
+
+warn contains msg if {
+	some name, bucket in input.resource.aws_s3_bucket
+	not bucket.force_destroy == false
+	msg := sprintf("aws_s3_bucket.%s does not set force_destroy = false", [name])
+}
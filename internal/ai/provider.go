@@ -13,6 +13,7 @@ import (
 // Provider represents an AI provider interface
 type Provider interface {
 	GenerateConfig(parsed *nlp.ParsedInput) (string, error)
+	Complete(ctx context.Context, prompt string) (string, error)
 }
 
 // OpenAIProvider implements the Provider interface for OpenAI
@@ -49,15 +50,28 @@ func (p *OpenAIProvider) GenerateConfig(parsed *nlp.ParsedInput) (string, error)
 		return "", fmt.Errorf("parsed input cannot be nil")
 	}
 
+	// Build the prompt for the AI model
+	prompt := BuildPrompt(parsed)
+
+	content, err := p.Complete(context.Background(), prompt)
+	if err != nil {
+		return "", err
+	}
+
+	// Clean up the response (remove markdown formatting if present)
+	content = p.cleanResponse(content)
+
+	return content, nil
+}
+
+// Complete sends a single prompt to OpenAI and returns the raw reply
+func (p *OpenAIProvider) Complete(ctx context.Context, prompt string) (string, error) {
 	if p.client == nil {
 		return "", fmt.Errorf("OpenAI client not initialized")
 	}
 
-	// Build the prompt for the AI model
-	prompt := p.buildPrompt(parsed)
-
 	// Make the API call to OpenAI
-	response, err := p.client.Chat.Completions.New(context.Background(), openai.ChatCompletionNewParams{
+	response, err := p.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
 		Messages: openai.F([]openai.ChatCompletionMessageParamUnion{
 			openai.UserMessage(prompt),
 		}),
@@ -72,17 +86,12 @@ func (p *OpenAIProvider) GenerateConfig(parsed *nlp.ParsedInput) (string, error)
 		return "", fmt.Errorf("no response choices returned from OpenAI")
 	}
 
-	// Extract the generated Terraform configuration
-	content := response.Choices[0].Message.Content
-
-	// Clean up the response (remove markdown formatting if present)
-	content = p.cleanResponse(content)
-
-	return content, nil
+	return response.Choices[0].Message.Content, nil
 }
 
-// buildPrompt constructs the prompt for the AI model
-func (p *OpenAIProvider) buildPrompt(parsed *nlp.ParsedInput) string {
+// BuildPrompt constructs the generation prompt sent to the AI model for the
+// parsed input. It makes no model call, so it can be used to preview a request.
+func BuildPrompt(parsed *nlp.ParsedInput) string {
 	var prompt strings.Builder
 
 	prompt.WriteString("Generate a Terraform configuration based on the following requirements:\n\n")
@@ -95,17 +104,70 @@ func (p *OpenAIProvider) buildPrompt(parsed *nlp.ParsedInput) string {
 	if len(parsed.Resources) > 0 {
 		prompt.WriteString("Resources identified:\n")
 		for _, resource := range parsed.Resources {
-			prompt.WriteString(fmt.Sprintf("- %s: %s\n", resource.Type, resource.Name))
+			if name, ok := resource.Properties["name"]; ok {
+				prompt.WriteString(fmt.Sprintf("- %s: %s (named %q)\n", resource.Type, resource.Name, name))
+			} else {
+				prompt.WriteString(fmt.Sprintf("- %s: %s\n", resource.Type, resource.Name))
+			}
 		}
 	}
 
+	if len(parsed.Relations) > 0 {
+		prompt.WriteString("Relationships between resources:\n")
+		for _, relation := range parsed.Relations {
+			prompt.WriteString(fmt.Sprintf("- %s %s %s\n", relation.From, strings.ReplaceAll(relation.Kind, "_", " "), relation.To))
+		}
+	}
+
+	if len(parsed.Frameworks) > 0 {
+		var names []string
+		for _, framework := range parsed.Frameworks {
+			names = append(names, framework.Name)
+		}
+		prompt.WriteString(fmt.Sprintf("Compliance frameworks: %s (the configuration must satisfy their controls)\n", strings.Join(names, ", ")))
+	}
+
 	if len(parsed.Requirements) > 0 {
 		prompt.WriteString("Requirements:\n")
 		for _, req := range parsed.Requirements {
-			prompt.WriteString(fmt.Sprintf("- %s\n", req))
+			if req.Environment != "" {
+				prompt.WriteString(fmt.Sprintf("- %s (%s, %s only)\n", req, req.Constraint(), req.Environment))
+			} else {
+				prompt.WriteString(fmt.Sprintf("- %s (%s)\n", req, req.Constraint()))
+			}
+		}
+	}
+
+	if parsed.Budget != nil {
+		prompt.WriteString(fmt.Sprintf("Budget: at most %s; prefer the most cost-effective options that meet the requirements\n", parsed.Budget))
+	}
+
+	if sizes, ok := parsed.InstanceSizes(); ok {
+		size := parsed.SizeTier()
+		if parsed.Workload != nil && parsed.Workload.DailyUsers > 0 {
+			size += fmt.Sprintf(" (about %d daily users)", parsed.Workload.DailyUsers)
+		}
+		prompt.WriteString(fmt.Sprintf("Workload size: %s; unless an instance type is given, use %s for compute and %s for databases\n", size, sizes.Compute, sizes.Database))
+	}
+
+	if environments := parsed.EnvironmentNames(); len(environments) > 1 {
+		prompt.WriteString(fmt.Sprintf("Environments: %s\n", strings.Join(environments, ", ")))
+		prompt.WriteString("Write one reusable configuration parameterized by an \"environment\" variable. ")
+		prompt.WriteString(fmt.Sprintf("Declare variables for settings that differ between environments (%s) ", strings.Join(nlp.EnvironmentVariableNames(), ", ")))
+		prompt.WriteString("with defaults suited to the smallest environment; each environment sets them in its own tfvars file.\n")
+	}
+
+	if details := describeEntities(parsed.Entities); len(details) > 0 {
+		prompt.WriteString("Specific values requested:\n")
+		for _, detail := range details {
+			prompt.WriteString(fmt.Sprintf("- %s\n", detail))
 		}
 	}
 
+	if parsed.Language != "" && parsed.Language != nlp.LanguageEnglish {
+		prompt.WriteString(fmt.Sprintf("The description was written in %s; write all comments and descriptions in the configuration in %s.\n", nlp.LanguageName(parsed.Language), nlp.LanguageName(parsed.Language)))
+	}
+
 	prompt.WriteString("\nPlease provide a complete, working Terraform configuration that:\n")
 	prompt.WriteString("1. Follows Terraform best practices\n")
 	prompt.WriteString("2. Includes proper resource naming and tagging\n")
@@ -117,6 +179,52 @@ func (p *OpenAIProvider) buildPrompt(parsed *nlp.ParsedInput) string {
 	return prompt.String()
 }
 
+// describeEntities renders extracted entities as prompt lines
+func describeEntities(entities nlp.Entities) []string {
+	var details []string
+
+	for _, region := range entities.Regions {
+		details = append(details, fmt.Sprintf("Region: %s (%s)", region.Name, region.Provider))
+	}
+	for _, zone := range entities.AvailabilityZones {
+		details = append(details, "Availability zone: "+zone.Name)
+	}
+	for _, cidr := range entities.CIDRBlocks {
+		details = append(details, "CIDR block: "+cidr.Value)
+	}
+	for _, instanceType := range entities.InstanceTypes {
+		details = append(details, "Instance type: "+instanceType.Name)
+	}
+	for _, version := range entities.EngineVersions {
+		details = append(details, fmt.Sprintf("Engine version: %s %s", version.Engine, version.Version))
+	}
+	for _, port := range entities.Ports {
+		if port.From == port.To {
+			details = append(details, fmt.Sprintf("Port: %d", port.From))
+		} else {
+			details = append(details, fmt.Sprintf("Port range: %d-%d", port.From, port.To))
+		}
+	}
+	for _, duration := range entities.Durations {
+		detail := fmt.Sprintf("Duration: %d %s", duration.Value, duration.Unit)
+		if duration.Subject != "" {
+			detail += " (" + duration.Subject + ")"
+		}
+		details = append(details, detail)
+	}
+	for _, environment := range entities.Environments {
+		details = append(details, "Environment: "+environment.Name)
+	}
+	for _, name := range entities.Names {
+		details = append(details, fmt.Sprintf("Name (use exactly as written): %q", name.Value))
+	}
+	for _, tag := range entities.Tags {
+		details = append(details, fmt.Sprintf("Tag (use exactly as written): %q = %q", tag.Key, tag.Value))
+	}
+
+	return details
+}
+
 // cleanResponse cleans up the response from OpenAI
 func (p *OpenAIProvider) cleanResponse(content string) string {
 	// Remove markdown code blocks if present
@@ -0,0 +1,288 @@
+package nlp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Question identifiers returned by Clarify
+const (
+	QuestionResources      = "resources"
+	QuestionCloudProvider  = "cloud_provider"
+	QuestionRegion         = "region"
+	QuestionDatabaseEngine = "database_engine"
+	QuestionNetworkAccess  = "network_access"
+	QuestionComputeOS      = "compute_os"
+)
+
+// Question represents a clarifying question for an ambiguous description
+type Question struct {
+	ID      string   `json:"id"`
+	Text    string   `json:"text"`
+	Options []string `json:"options,omitempty"`
+	Default string   `json:"default"`
+}
+
+// knownResourceTypes lists the resource types a user can pick when none were found
+var knownResourceTypes = []string{"compute", "storage", "network", "database", "container", "serverless"}
+
+// defaultRegions holds the suggested region for each cloud provider
+var defaultRegions = map[string]string{
+	"aws":   "us-east-1",
+	"azure": "eastus",
+	"gcp":   "us-central1",
+}
+
+// DefaultRegion returns the suggested region for a cloud provider
+func DefaultRegion(provider string) string {
+	return defaultRegions[provider]
+}
+
+// Clarify analyzes parsed input and returns questions for anything ambiguous
+// or missing. The provider is only asked for when no keyword of the detected
+// provider occurs in the text; the options are the providers of the vocabulary.
+func (e *Engine) Clarify(parsed *ParsedInput) []Question {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var questions []Question
+	text := strings.ToLower(parsed.OriginalText)
+
+	if len(parsed.Resources) == 0 {
+		questions = append(questions, Question{
+			ID:      QuestionResources,
+			Text:    "Which resources should be created? (comma-separated)",
+			Options: knownResourceTypes,
+			Default: "compute",
+		})
+	}
+
+	if !e.mentionsProvider(text, parsed.CloudProvider) {
+		questions = append(questions, Question{
+			ID:      QuestionCloudProvider,
+			Text:    "Which cloud provider?",
+			Options: e.providerNames(),
+			Default: parsed.CloudProvider,
+		})
+	}
+
+	// Providers without a suggested region are left to their own default
+	if len(parsed.Entities.Regions) == 0 && DefaultRegion(parsed.CloudProvider) != "" {
+		questions = append(questions, Question{
+			ID:      QuestionRegion,
+			Text:    "Which region?",
+			Default: DefaultRegion(parsed.CloudProvider),
+		})
+	}
+
+	for _, resource := range parsed.Resources {
+		switch resource.Type {
+		case "database":
+			engines := attributeValues(resource, "engine")
+			if len(engines) != 1 {
+				questions = append(questions, Question{
+					ID:      QuestionDatabaseEngine,
+					Text:    "Which database engine?",
+					Options: []string{"mysql", "postgresql"},
+					Default: "postgresql",
+				})
+			}
+
+		case "network":
+			if len(attributeValues(resource, "access")) > 1 {
+				suggested := "private"
+				if strings.Contains(text, "subnet") {
+					suggested = "both"
+				}
+				questions = append(questions, Question{
+					ID:      QuestionNetworkAccess,
+					Text:    "Both public and private access were mentioned. Should the network be public, private, or have both public and private subnets?",
+					Options: []string{"public", "private", "both"},
+					Default: suggested,
+				})
+			}
+
+		case "compute":
+			if len(attributeValues(resource, "os")) > 1 {
+				questions = append(questions, Question{
+					ID:      QuestionComputeOS,
+					Text:    "Both Linux and Windows were mentioned. Which operating system should the instances run?",
+					Options: []string{"linux", "windows"},
+					Default: "linux",
+				})
+			}
+		}
+	}
+
+	return questions
+}
+
+// PendingQuestions returns the questions that have no entry in answers
+func PendingQuestions(questions []Question, answers map[string]string) []Question {
+	var pending []Question
+	for _, question := range questions {
+		if _, ok := answers[question.ID]; !ok {
+			pending = append(pending, question)
+		}
+	}
+	return pending
+}
+
+// DefaultAnswers returns the suggested default for every question
+func DefaultAnswers(questions []Question) map[string]string {
+	answers := make(map[string]string)
+	for _, question := range questions {
+		answers[question.ID] = question.Default
+	}
+	return answers
+}
+
+// questionOrder is the order answers are applied in; later answers depend on
+// earlier ones (the region follows the provider, engines need the resource)
+var questionOrder = []string{
+	QuestionCloudProvider,
+	QuestionResources,
+	QuestionRegion,
+	QuestionDatabaseEngine,
+	QuestionNetworkAccess,
+	QuestionComputeOS,
+}
+
+// ApplyAnswers updates the parsed input with the answers to clarifying questions
+func (e *Engine) ApplyAnswers(parsed *ParsedInput, answers map[string]string) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for id := range answers {
+		if !contains(questionOrder, id) {
+			return fmt.Errorf("unknown clarifying question: %s", id)
+		}
+	}
+
+	for _, id := range questionOrder {
+		answer, ok := answers[id]
+		if !ok {
+			continue
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+
+		switch id {
+		case QuestionCloudProvider:
+			if !contains(e.providerNames(), answer) {
+				return fmt.Errorf("invalid answer for %s: %q", id, answer)
+			}
+			parsed.CloudProvider = answer
+
+		case QuestionResources:
+			for _, resourceType := range strings.Split(answer, ",") {
+				resourceType = strings.TrimSpace(resourceType)
+				if !contains(knownResourceTypes, resourceType) {
+					return fmt.Errorf("invalid answer for %s: unknown resource type %q", id, resourceType)
+				}
+				if findResource(parsed, resourceType) == nil {
+					parsed.Resources = append(parsed.Resources, Resource{
+						Type:       resourceType,
+						Name:       defaultResourceName(resourceType),
+						Properties: make(map[string]string),
+						Attributes: []string{},
+						Span:       NoSpan,
+					})
+				}
+			}
+
+		case QuestionRegion:
+			if answer == "" {
+				if DefaultRegion(parsed.CloudProvider) == "" {
+					continue
+				}
+				return fmt.Errorf("invalid answer for %s: region cannot be empty", id)
+			}
+			parsed.Entities.Regions = []Region{{Name: answer, Provider: parsed.CloudProvider, Span: NoSpan}}
+
+		case QuestionDatabaseEngine:
+			if err := setAttribute(parsed, "database", "engine", answer, []string{"mysql", "postgresql"}); err != nil {
+				return err
+			}
+
+		case QuestionNetworkAccess:
+			values := []string{answer}
+			if answer == "both" {
+				values = []string{"public", "private"}
+			} else if answer != "public" && answer != "private" {
+				return fmt.Errorf("invalid answer for %s: %q", id, answer)
+			}
+			resource := findResource(parsed, "network")
+			if resource == nil {
+				continue
+			}
+			resource.Attributes = removeAttributes(resource.Attributes, "access")
+			for _, value := range values {
+				resource.Attributes = append(resource.Attributes, "access:"+value)
+			}
+
+		case QuestionComputeOS:
+			if err := setAttribute(parsed, "compute", "os", answer, []string{"linux", "windows"}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// setAttribute replaces a "key:value" attribute on the resource of the given type
+func setAttribute(parsed *ParsedInput, resourceType, key, value string, allowed []string) error {
+	if !contains(allowed, value) {
+		return fmt.Errorf("invalid answer for %s %s: %q", resourceType, key, value)
+	}
+
+	resource := findResource(parsed, resourceType)
+	if resource == nil {
+		return nil
+	}
+
+	resource.Attributes = append(removeAttributes(resource.Attributes, key), key+":"+value)
+	return nil
+}
+
+// findResource returns the first resource of the given type
+func findResource(parsed *ParsedInput, resourceType string) *Resource {
+	for i := range parsed.Resources {
+		if parsed.Resources[i].Type == resourceType {
+			return &parsed.Resources[i]
+		}
+	}
+	return nil
+}
+
+// attributeValues returns the values of all "key:value" attributes with the given key
+func attributeValues(resource Resource, key string) []string {
+	var values []string
+	for _, attribute := range resource.Attributes {
+		if strings.HasPrefix(attribute, key+":") {
+			values = append(values, strings.TrimPrefix(attribute, key+":"))
+		}
+	}
+	return values
+}
+
+// removeAttributes drops all "key:value" attributes with the given key
+func removeAttributes(attributes []string, key string) []string {
+	result := []string{}
+	for _, attribute := range attributes {
+		if !strings.HasPrefix(attribute, key+":") {
+			result = append(result, attribute)
+		}
+	}
+	return result
+}
+
+// contains reports whether value is in values
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package nlp
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// Compliance framework identifiers
+const (
+	FrameworkHIPAA   = "hipaa"
+	FrameworkPCIDSS  = "pci-dss"
+	FrameworkSOC2    = "soc2"
+	FrameworkCIS     = "cis"
+	FrameworkFedRAMP = "fedramp"
+)
+
+// Constraint kinds introduced by compliance frameworks
+const (
+	KindAuditLogging    = "audit_logging"
+	KindBackupRetention = "backup_retention"
+)
+
+// ComplianceFramework represents a named compliance framework found in the input
+type ComplianceFramework struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Span Span   `json:"span"`
+}
+
+// frameworkPatterns recognizes the ways users write each framework
+var frameworkPatterns = []struct {
+	id   string
+	name string
+	re   *regexp.Regexp
+}{
+	{FrameworkHIPAA, "HIPAA", regexp.MustCompile(`\bhipaa\b`)},
+	{FrameworkPCIDSS, "PCI-DSS", regexp.MustCompile(`\bpci(?:[\s-]?dss)?\b`)},
+	{FrameworkSOC2, "SOC 2", regexp.MustCompile(`\bsoc[\s-]?(?:2|ii)\b`)},
+	{FrameworkCIS, "CIS", regexp.MustCompile(`\bcis(?:\s+benchmarks?)?\b`)},
+	{FrameworkFedRAMP, "FedRAMP", regexp.MustCompile(`\bfed[\s-]?ramp\b`)},
+}
+
+// frameworkRetentionDays is the minimum backup retention each framework is
+// expanded into
+var frameworkRetentionDays = map[string]int{
+	FrameworkHIPAA:   35,
+	FrameworkPCIDSS:  30,
+	FrameworkSOC2:    14,
+	FrameworkCIS:     7,
+	FrameworkFedRAMP: 35,
+}
+
+// frameworkKinds are the controls every framework is expanded into
+var frameworkKinds = []string{
+	KindEncryptionAtRest,
+	KindEncryptionInTransit,
+	KindAuditLogging,
+	KindBackupRetention,
+	KindPrivateAccess,
+}
+
+// FrameworkName returns the display name of a framework identifier
+func FrameworkName(id string) string {
+	for _, pattern := range frameworkPatterns {
+		if pattern.id == id {
+			return pattern.name
+		}
+	}
+	return ""
+}
+
+// extractFrameworks finds the compliance frameworks named in the input
+func extractFrameworks(input string) []ComplianceFramework {
+	var frameworks []ComplianceFramework
+
+	for _, pattern := range frameworkPatterns {
+		if loc := pattern.re.FindStringIndex(input); loc != nil {
+			frameworks = append(frameworks, ComplianceFramework{
+				ID:   pattern.id,
+				Name: pattern.name,
+				Span: Span{Start: loc[0], End: loc[1]},
+			})
+		}
+	}
+
+	sort.Slice(frameworks, func(i, j int) bool {
+		return frameworks[i].Span.Start < frameworks[j].Span.Start
+	})
+
+	return frameworks
+}
+
+// expandFrameworks turns each framework into explicit requirements, one per
+// control and applicable resource. Controls the input already asks for on
+// the same resource are not repeated.
+func expandFrameworks(parsed *ParsedInput) {
+	for _, framework := range parsed.Frameworks {
+		for _, kind := range frameworkKinds {
+			requirement := Requirement{
+				Category:  "Compliance",
+				Text:      parsed.Text(framework.Span),
+				Kind:      kind,
+				Framework: framework.ID,
+				Span:      framework.Span,
+			}
+			if requirement.Text == "" {
+				requirement.Text = framework.Name
+			}
+			if kind == KindBackupRetention {
+				requirement.Value = strconv.Itoa(frameworkRetentionDays[framework.ID])
+				requirement.Unit = "days"
+			}
+
+			targets := applicableTargets(parsed, kind)
+			if len(targets) == 0 {
+				targets = []string{""}
+			}
+			for _, target := range targets {
+				requirement.Target = target
+				if !hasConstraint(parsed.Requirements, requirement) {
+					parsed.Requirements = append(parsed.Requirements, requirement)
+				}
+			}
+		}
+	}
+}
+
+// applicableTargets returns the parsed resource types a kind can apply to
+func applicableTargets(parsed *ParsedInput, kind string) []string {
+	var targets []string
+	for _, resourceType := range kindTargets[kind] {
+		if findResource(parsed, resourceType) != nil {
+			targets = append(targets, resourceType)
+		}
+	}
+	return targets
+}
+
+// hasConstraint reports whether a requirement with the same kind and target is present
+func hasConstraint(requirements []Requirement, requirement Requirement) bool {
+	for _, r := range requirements {
+		if r.Kind == requirement.Kind && r.Target == requirement.Target {
+			return true
+		}
+	}
+	return false
+}
+
+// FrameworkIDs returns the identifiers of the frameworks in parsed
+func (p *ParsedInput) FrameworkIDs() []string {
+	var ids []string
+	for _, framework := range p.Frameworks {
+		ids = append(ids, framework.ID)
+	}
+	return ids
+}
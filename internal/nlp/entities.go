@@ -0,0 +1,382 @@
+package nlp
+
+import (
+	"net"
+	"regexp"
+	"strconv"
+)
+
+// Entities holds typed values extracted from the input
+type Entities struct {
+	Regions           []Region           `json:"regions,omitempty"`
+	AvailabilityZones []AvailabilityZone `json:"availability_zones,omitempty"`
+	CIDRBlocks        []CIDRBlock        `json:"cidr_blocks,omitempty"`
+	InstanceTypes     []InstanceType     `json:"instance_types,omitempty"`
+	EngineVersions    []EngineVersion    `json:"engine_versions,omitempty"`
+	Ports             []PortRange        `json:"ports,omitempty"`
+	Durations         []Duration         `json:"durations,omitempty"`
+	Environments      []Environment      `json:"environments,omitempty"`
+	Names             []Name             `json:"names,omitempty"`
+	Tags              []Tag              `json:"tags,omitempty"`
+}
+
+// Region represents a cloud region mentioned in the input
+type Region struct {
+	Name     string `json:"name"`
+	Provider string `json:"provider"`
+	Span     Span   `json:"span"`
+}
+
+// AvailabilityZone represents an availability zone mentioned in the input
+type AvailabilityZone struct {
+	Name string `json:"name"`
+	Span Span   `json:"span"`
+}
+
+// CIDRBlock represents an IPv4 CIDR block mentioned in the input
+type CIDRBlock struct {
+	Value string `json:"value"`
+	Span  Span   `json:"span"`
+}
+
+// InstanceType represents an instance or machine type mentioned in the input
+type InstanceType struct {
+	Name     string `json:"name"`
+	Provider string `json:"provider"`
+	Span     Span   `json:"span"`
+}
+
+// EngineVersion represents a versioned engine or runtime, e.g. "postgres 15"
+type EngineVersion struct {
+	Engine  string `json:"engine"`
+	Version string `json:"version"`
+	Span    Span   `json:"span"`
+}
+
+// PortRange represents a single port (From == To) or an inclusive port range
+type PortRange struct {
+	From int  `json:"from"`
+	To   int  `json:"to"`
+	Span Span `json:"span"`
+}
+
+// Duration represents a time span such as "7-day backups"
+type Duration struct {
+	Value   int    `json:"value"`
+	Unit    string `json:"unit"`
+	Subject string `json:"subject,omitempty"`
+	Span    Span   `json:"span"`
+}
+
+// Environment represents a deployment environment such as "prod"
+type Environment struct {
+	Name string `json:"name"`
+	Span Span   `json:"span"`
+}
+
+var (
+	awsRegionPattern   = regexp.MustCompile(`\b((?:us|eu|ap|sa|ca|me|af|il|mx)-(?:gov-)?(?:north|south|east|west|central|northeast|southeast|northwest|southwest)-\d)([a-f])?\b`)
+	gcpRegionPattern   = regexp.MustCompile(`\b((?:us|europe|asia|australia|northamerica|southamerica|me|africa)-(?:north|south|east|west|central|northeast|southeast|northwest|southwest)\d)(?:-([a-f]))?\b`)
+	cidrPattern        = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}/\d{1,2}\b`)
+	awsInstancePattern = regexp.MustCompile(`\b(?:(?:db|cache)\.)?[a-z]\d[a-z0-9-]*\.(?:nano|micro|small|medium|large|metal|\d*xlarge)\b`)
+	gcpMachinePattern  = regexp.MustCompile(`\b(?:e2|n1|n2|n2d|n4|c2|c2d|c3|t2d|t2a|m1|m2|m3|a2|a3)-(?:standard|highmem|highcpu|micro|small|medium)(?:-\d+)?\b`)
+	azureSizePattern   = regexp.MustCompile(`\bstandard_[a-z]+\d+[a-z]*(?:_v\d+)?\b`)
+	enginePattern      = regexp.MustCompile(`\b(postgresql|postgres|mysql|mariadb|redis|mongodb|kubernetes|k8s|nodejs|node|python|java|terraform)\s*(?:version\s*|v)?(\d+(?:\.\d+)*)\b`)
+	portListPattern    = regexp.MustCompile(`\bports?\s+((?:\d+(?:\s*(?:-|to)\s*\d+)?(?:\s*(?:,|and|or)\s*)?)+)`)
+	portRangePattern   = regexp.MustCompile(`(\d+)(?:\s*(?:-|to)\s*(\d+))?`)
+	durationPattern    = regexp.MustCompile(`\b(\d+)[\s-]*(minute|hour|day|week|month|year)s?\b(?:\s+(?:of\s+)?([a-z]+))?`)
+	environmentPattern = regexp.MustCompile(`\b(dev|development|staging|stage|qa|test|uat|prod|production|sandbox)\b`)
+)
+
+// azureRegions lists the Azure region names recognized in the input
+var azureRegions = []string{
+	"eastus", "eastus2", "westus", "westus2", "westus3", "centralus",
+	"northcentralus", "southcentralus", "westcentralus", "canadacentral",
+	"canadaeast", "brazilsouth", "northeurope", "westeurope", "uksouth",
+	"ukwest", "francecentral", "germanywestcentral", "swedencentral",
+	"switzerlandnorth", "norwayeast", "eastasia", "southeastasia",
+	"japaneast", "japanwest", "australiaeast", "australiasoutheast",
+	"centralindia", "southindia", "koreacentral", "uaenorth",
+	"southafricanorth",
+}
+
+// engineAliases maps engine spellings to their canonical names
+var engineAliases = map[string]string{
+	"postgres": "postgresql",
+	"k8s":      "kubernetes",
+	"node":     "nodejs",
+}
+
+// environmentAliases maps environment spellings to their canonical names
+var environmentAliases = map[string]string{
+	"development": "dev",
+	"stage":       "staging",
+	"production":  "prod",
+}
+
+// durationSubjectStopwords are words that never describe what a duration applies to
+var durationSubjectStopwords = map[string]bool{
+	"and": true, "or": true, "with": true, "for": true, "in": true,
+	"the": true, "a": true, "an": true, "to": true, "on": true,
+}
+
+// extractEntities extracts typed entities such as regions, CIDR blocks and ports
+func (e *Engine) extractEntities(input string) Entities {
+	var entities Entities
+
+	entities.Regions, entities.AvailabilityZones = extractRegions(input)
+	entities.CIDRBlocks = extractCIDRBlocks(input)
+	entities.InstanceTypes = extractInstanceTypes(input)
+	entities.EngineVersions = extractEngineVersions(input)
+	entities.Ports = extractPorts(input)
+	entities.Durations = extractDurations(input)
+	entities.Environments = extractEnvironments(input)
+
+	return entities
+}
+
+// extractRegions finds AWS, GCP and Azure regions and availability zones
+func extractRegions(input string) ([]Region, []AvailabilityZone) {
+	var regions []Region
+	var zones []AvailabilityZone
+	seenRegions := make(map[string]bool)
+	seenZones := make(map[string]bool)
+
+	addRegion := func(name, provider string, span Span) {
+		if !seenRegions[name] {
+			seenRegions[name] = true
+			regions = append(regions, Region{Name: name, Provider: provider, Span: span})
+		}
+	}
+
+	addZone := func(name string, span Span) {
+		if !seenZones[name] {
+			seenZones[name] = true
+			zones = append(zones, AvailabilityZone{Name: name, Span: span})
+		}
+	}
+
+	for _, pattern := range []struct {
+		provider string
+		re       *regexp.Regexp
+	}{
+		{"aws", awsRegionPattern},
+		{"gcp", gcpRegionPattern},
+	} {
+		for _, idx := range pattern.re.FindAllStringSubmatchIndex(input, -1) {
+			addRegion(input[idx[2]:idx[3]], pattern.provider, Span{Start: idx[2], End: idx[3]})
+			if idx[4] >= 0 {
+				addZone(input[idx[0]:idx[1]], Span{Start: idx[0], End: idx[1]})
+			}
+		}
+	}
+
+	for _, word := range words(input) {
+		for _, region := range azureRegions {
+			if input[word.Start:word.End] == region {
+				addRegion(region, "azure", word)
+			}
+		}
+	}
+
+	return regions, zones
+}
+
+// extractCIDRBlocks finds valid IPv4 CIDR blocks
+func extractCIDRBlocks(input string) []CIDRBlock {
+	var blocks []CIDRBlock
+	seen := make(map[string]bool)
+
+	for _, loc := range cidrPattern.FindAllStringIndex(input, -1) {
+		value := input[loc[0]:loc[1]]
+		if _, _, err := net.ParseCIDR(value); err == nil && !seen[value] {
+			seen[value] = true
+			blocks = append(blocks, CIDRBlock{Value: value, Span: Span{Start: loc[0], End: loc[1]}})
+		}
+	}
+
+	return blocks
+}
+
+// extractInstanceTypes finds instance, machine and VM size names
+func extractInstanceTypes(input string) []InstanceType {
+	var types []InstanceType
+	seen := make(map[string]bool)
+
+	patterns := []struct {
+		provider string
+		re       *regexp.Regexp
+	}{
+		{"aws", awsInstancePattern},
+		{"gcp", gcpMachinePattern},
+		{"azure", azureSizePattern},
+	}
+
+	for _, p := range patterns {
+		for _, loc := range p.re.FindAllStringIndex(input, -1) {
+			name := input[loc[0]:loc[1]]
+			if !seen[name] {
+				seen[name] = true
+				types = append(types, InstanceType{Name: name, Provider: p.provider, Span: Span{Start: loc[0], End: loc[1]}})
+			}
+		}
+	}
+
+	return types
+}
+
+// extractEngineVersions finds engine and runtime versions such as "mysql 8.0"
+func extractEngineVersions(input string) []EngineVersion {
+	var versions []EngineVersion
+	seen := make(map[string]bool)
+
+	for _, idx := range enginePattern.FindAllStringSubmatchIndex(input, -1) {
+		match := submatches(input, idx)
+		engine := match[1]
+		if alias, ok := engineAliases[engine]; ok {
+			engine = alias
+		}
+
+		key := engine + "@" + match[2]
+		if !seen[key] {
+			seen[key] = true
+			versions = append(versions, EngineVersion{Engine: engine, Version: match[2], Span: Span{Start: idx[0], End: idx[1]}})
+		}
+	}
+
+	return versions
+}
+
+// extractPorts finds single ports, port lists and port ranges
+func extractPorts(input string) []PortRange {
+	var ports []PortRange
+	seen := make(map[[2]int]bool)
+
+	for _, loc := range portListPattern.FindAllStringSubmatchIndex(input, -1) {
+		list := input[loc[2]:loc[3]]
+		for _, idx := range portRangePattern.FindAllStringSubmatchIndex(list, -1) {
+			// A trailing hyphen means the number belongs to something else, e.g. "7-day"
+			if end := loc[2] + idx[1]; end < len(input) && input[end] == '-' {
+				continue
+			}
+
+			match := submatches(list, idx)
+			from, err := strconv.Atoi(match[1])
+			if err != nil {
+				continue
+			}
+
+			to := from
+			if match[2] != "" {
+				if to, err = strconv.Atoi(match[2]); err != nil {
+					continue
+				}
+			}
+
+			if from < 1 || to > 65535 || from > to {
+				continue
+			}
+
+			if key := [2]int{from, to}; !seen[key] {
+				seen[key] = true
+				ports = append(ports, PortRange{From: from, To: to, Span: Span{Start: loc[2] + idx[0], End: loc[2] + idx[1]}})
+			}
+		}
+	}
+
+	return ports
+}
+
+// extractDurations finds time spans such as "7-day backups" or "30 days of logs"
+func extractDurations(input string) []Duration {
+	var durations []Duration
+
+	for _, idx := range durationPattern.FindAllStringSubmatchIndex(input, -1) {
+		match := submatches(input, idx)
+		value, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+
+		subject := match[3]
+		if durationSubjectStopwords[subject] {
+			subject = ""
+		}
+
+		durations = append(durations, Duration{
+			Value:   value,
+			Unit:    match[2],
+			Subject: subject,
+			Span:    Span{Start: idx[0], End: idx[1]},
+		})
+	}
+
+	return durations
+}
+
+// extractEnvironments finds deployment environment names
+func extractEnvironments(input string) []Environment {
+	var environments []Environment
+	seen := make(map[string]bool)
+
+	for _, loc := range environmentPattern.FindAllStringIndex(input, -1) {
+		name := input[loc[0]:loc[1]]
+		if alias, ok := environmentAliases[name]; ok {
+			name = alias
+		}
+		if !seen[name] {
+			seen[name] = true
+			environments = append(environments, Environment{Name: name, Span: Span{Start: loc[0], End: loc[1]}})
+		}
+	}
+
+	return environments
+}
+
+// words returns the spans of the words in the input
+func words(input string) []Span {
+	var spans []Span
+	start := -1
+
+	for i, r := range input {
+		if isWordSeparator(r) {
+			if start >= 0 {
+				spans = append(spans, Span{Start: start, End: i})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, Span{Start: start, End: len(input)})
+	}
+
+	return spans
+}
+
+// submatches converts a submatch index slice into the matched strings
+func submatches(s string, idx []int) []string {
+	match := make([]string, len(idx)/2)
+	for i := range match {
+		if idx[2*i] >= 0 {
+			match[i] = s[idx[2*i]:idx[2*i+1]]
+		}
+	}
+	return match
+}
+
+// isWordSeparator reports whether r separates words in the input
+func isWordSeparator(r rune) bool {
+	return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_')
+}
+
+// appendUnique appends value to values if it is not already present
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
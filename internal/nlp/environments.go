@@ -0,0 +1,146 @@
+package nlp
+
+import (
+	"regexp"
+	"strconv"
+)
+
+var (
+	// environmentListSeparator matches the text between environments in a
+	// list such as "dev, staging and prod"
+	environmentListSeparator = regexp.MustCompile(`^\s*(?:,|/|&|and|or)\s*(?:and\s+|or\s+)?$`)
+	// environmentPrefixGap matches the text between an environment and a
+	// requirement that follows it, e.g. "prod multi-az", "prod with 3 servers"
+	environmentPrefixGap = regexp.MustCompile(`^(?:'s)?\s+(?:(?:should|must|will|needs? to)\s+(?:be|have|use|run)\s+|(?:with|gets|has|uses|needs|is|runs|on)\s+)?(?:an?\s+|the\s+)?$`)
+	// environmentSuffixGap matches the text between a requirement and an
+	// environment that follows it, e.g. "multi-az in prod", "3 servers for production"
+	environmentSuffixGap = regexp.MustCompile(`^(?:s|es)?\s+(?:only\s+)?(?:in|for|on)\s+(?:the\s+)?$`)
+)
+
+// environmentMention is one occurrence of an environment name in the input
+type environmentMention struct {
+	name   string
+	span   Span
+	listed bool
+}
+
+// EnvironmentNames returns the canonical names of the environments in the input
+func (p *ParsedInput) EnvironmentNames() []string {
+	var names []string
+	for _, environment := range p.Entities.Environments {
+		names = append(names, environment.Name)
+	}
+	return names
+}
+
+// RequirementsForEnvironment returns the requirements that apply to the
+// named environment: the shared ones plus those scoped to it
+func (p *ParsedInput) RequirementsForEnvironment(name string) []Requirement {
+	var requirements []Requirement
+	for _, requirement := range p.Requirements {
+		if requirement.Environment == "" || requirement.Environment == name {
+			requirements = append(requirements, requirement)
+		}
+	}
+	return requirements
+}
+
+// scopeRequirements assigns requirements that are stated for a single
+// environment ("with prod multi-az") to that environment. It only applies
+// when the input names more than one environment; with a single one every
+// requirement is shared.
+func scopeRequirements(parsed *ParsedInput, input string) {
+	if len(parsed.Entities.Environments) < 2 {
+		return
+	}
+
+	mentions := findEnvironmentMentions(input)
+
+	for i := range parsed.Requirements {
+		requirement := &parsed.Requirements[i]
+		if requirement.Span == NoSpan {
+			continue
+		}
+
+		for _, m := range mentions {
+			if m.listed {
+				continue
+			}
+			if m.span.End <= requirement.Span.Start && environmentPrefixGap.MatchString(input[m.span.End:requirement.Span.Start]) {
+				requirement.Environment = m.name
+			}
+			if m.span.Start >= requirement.Span.End && environmentSuffixGap.MatchString(input[requirement.Span.End:m.span.Start]) {
+				requirement.Environment = m.name
+				break
+			}
+		}
+	}
+}
+
+// findEnvironmentMentions returns every environment occurrence, marking the
+// ones that are part of a list
+func findEnvironmentMentions(input string) []environmentMention {
+	var mentions []environmentMention
+
+	for _, loc := range environmentPattern.FindAllStringIndex(input, -1) {
+		name := input[loc[0]:loc[1]]
+		if alias, ok := environmentAliases[name]; ok {
+			name = alias
+		}
+		mentions = append(mentions, environmentMention{name: name, span: Span{Start: loc[0], End: loc[1]}})
+	}
+
+	for i := 1; i < len(mentions); i++ {
+		if environmentListSeparator.MatchString(input[mentions[i-1].span.End:mentions[i].span.Start]) {
+			mentions[i-1].listed = true
+			mentions[i].listed = true
+		}
+	}
+
+	return mentions
+}
+
+// environmentVariables maps requirement kinds that commonly differ between
+// environments to the Terraform variable that controls them
+var environmentVariables = []struct {
+	kind     string
+	variable string
+}{
+	{KindMultiAZ, "multi_az"},
+	{KindHighAvailability, "multi_az"},
+	{KindMinInstances, "instance_count"},
+	{KindStorageSize, "storage_size_gb"},
+	{KindBackupRetention, "backup_retention_days"},
+	{KindEncryptionAtRest, "enable_encryption"},
+	{KindAutoscaling, "enable_autoscaling"},
+}
+
+// EnvironmentVariableNames returns the Terraform variables per-environment
+// differences are expressed with, in a stable order
+func EnvironmentVariableNames() []string {
+	var names []string
+	for _, v := range environmentVariables {
+		names = appendUnique(names, v.variable)
+	}
+	return names
+}
+
+// EnvironmentVariable returns the Terraform variable and HCL literal value
+// that express the requirement, if it is one that can vary per environment
+func (r Requirement) EnvironmentVariable() (name, value string, ok bool) {
+	for _, v := range environmentVariables {
+		if v.kind != r.Kind {
+			continue
+		}
+		if r.Value == "" {
+			return v.variable, "true", true
+		}
+		if r.Kind == KindStorageSize && r.Unit == "tb" {
+			if size, err := strconv.Atoi(r.Value); err == nil {
+				return v.variable, strconv.Itoa(size * 1024), true
+			}
+		}
+		return v.variable, r.Value, true
+	}
+	return "", "", false
+}
@@ -0,0 +1,294 @@
+package nlp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Evaluated fields, in report order
+const (
+	FieldCloudProvider = "cloud_provider"
+	FieldResourceTypes = "resource_types"
+	FieldAttributes    = "attributes"
+	FieldIntent        = "intent"
+	FieldRequirements  = "requirements"
+)
+
+var evaluatedFields = []string{FieldCloudProvider, FieldResourceTypes, FieldAttributes, FieldIntent, FieldRequirements}
+
+// CorpusCase is one labeled example: a description and the expected parse.
+// Fields left out of Expected are not scored; an empty list means "nothing
+// should be extracted".
+type CorpusCase struct {
+	ID          string      `json:"id,omitempty"`
+	Description string      `json:"description"`
+	Expected    Expectation `json:"expected"`
+}
+
+// Expectation holds the labeled values for a corpus case. Attributes are
+// written as "resource_type.key:value", e.g. "database.engine:mysql", and
+// requirements as "Category: text".
+type Expectation struct {
+	CloudProvider string   `json:"cloud_provider,omitempty"`
+	ResourceTypes []string `json:"resource_types,omitempty"`
+	Attributes    []string `json:"attributes,omitempty"`
+	Intent        string   `json:"intent,omitempty"`
+	Requirements  []string `json:"requirements,omitempty"`
+}
+
+// FieldScore holds the confusion counts and derived metrics for one field
+type FieldScore struct {
+	TruePositives  int     `json:"true_positives"`
+	FalsePositives int     `json:"false_positives"`
+	FalseNegatives int     `json:"false_negatives"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	F1             float64 `json:"f1"`
+}
+
+// Mismatch records a case where the parser disagreed with the label
+type Mismatch struct {
+	CaseID   string   `json:"case_id"`
+	Field    string   `json:"field"`
+	Missing  []string `json:"missing,omitempty"`
+	Spurious []string `json:"spurious,omitempty"`
+}
+
+// EvalReport summarizes a parser evaluation over a corpus
+type EvalReport struct {
+	Cases      int                    `json:"cases"`
+	Errors     int                    `json:"errors"`
+	Fields     map[string]*FieldScore `json:"fields"`
+	Mismatches []Mismatch             `json:"mismatches,omitempty"`
+}
+
+// LoadCorpus reads a JSONL corpus, one CorpusCase per line. Blank lines and
+// lines starting with '#' are skipped.
+func LoadCorpus(path string) ([]CorpusCase, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open corpus: %w", err)
+	}
+	defer file.Close()
+
+	return ReadCorpus(file)
+}
+
+// ReadCorpus decodes a JSONL corpus from r
+func ReadCorpus(r io.Reader) ([]CorpusCase, error) {
+	var cases []CorpusCase
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var c CorpusCase
+		if err := json.Unmarshal([]byte(line), &c); err != nil {
+			return nil, fmt.Errorf("corpus line %d: %w", lineNum, err)
+		}
+		if c.Description == "" {
+			return nil, fmt.Errorf("corpus line %d: description is required", lineNum)
+		}
+		if c.ID == "" {
+			c.ID = fmt.Sprintf("line-%d", lineNum)
+		}
+		cases = append(cases, c)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read corpus: %w", err)
+	}
+
+	return cases, nil
+}
+
+// Evaluate runs the parser over the corpus and scores each field
+func Evaluate(parser Parser, cases []CorpusCase) *EvalReport {
+	report := &EvalReport{
+		Cases:  len(cases),
+		Fields: make(map[string]*FieldScore),
+	}
+	for _, field := range evaluatedFields {
+		report.Fields[field] = &FieldScore{}
+	}
+
+	for _, c := range cases {
+		parsed, err := parser.Parse(c.Description)
+		if err != nil {
+			report.Errors++
+			parsed = &ParsedInput{}
+		}
+
+		got := observe(parsed)
+		for _, field := range evaluatedFields {
+			want, labeled := c.Expected.values(field)
+			if !labeled {
+				continue
+			}
+			report.score(c.ID, field, want, got[field])
+		}
+	}
+
+	for _, score := range report.Fields {
+		score.compute()
+	}
+
+	return report
+}
+
+// Failures returns the fields whose precision or recall is below the thresholds
+func (r *EvalReport) Failures(minPrecision, minRecall float64) []string {
+	var failures []string
+
+	for _, field := range evaluatedFields {
+		score := r.Fields[field]
+		if score.TruePositives+score.FalsePositives+score.FalseNegatives == 0 {
+			continue // not labeled anywhere in the corpus
+		}
+		if score.Precision < minPrecision {
+			failures = append(failures, fmt.Sprintf("%s precision %.3f < %.3f", field, score.Precision, minPrecision))
+		}
+		if score.Recall < minRecall {
+			failures = append(failures, fmt.Sprintf("%s recall %.3f < %.3f", field, score.Recall, minRecall))
+		}
+	}
+
+	return failures
+}
+
+// Table renders the per-field scores as a plain-text table
+func (r *EvalReport) Table() string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("%-16s %6s %6s %6s %9s %7s %7s\n", "FIELD", "TP", "FP", "FN", "PRECISION", "RECALL", "F1"))
+	for _, field := range evaluatedFields {
+		s := r.Fields[field]
+		b.WriteString(fmt.Sprintf("%-16s %6d %6d %6d %9.3f %7.3f %7.3f\n",
+			field, s.TruePositives, s.FalsePositives, s.FalseNegatives, s.Precision, s.Recall, s.F1))
+	}
+	b.WriteString(fmt.Sprintf("\n%d cases, %d parse errors, %d mismatches\n", r.Cases, r.Errors, len(r.Mismatches)))
+
+	return b.String()
+}
+
+// score compares expected and observed values for one field of one case
+func (r *EvalReport) score(caseID, field string, want, got []string) {
+	score := r.Fields[field]
+	wantSet := toSet(want)
+	gotSet := toSet(got)
+
+	var missing, spurious []string
+	for value := range wantSet {
+		if gotSet[value] {
+			score.TruePositives++
+		} else {
+			score.FalseNegatives++
+			missing = append(missing, value)
+		}
+	}
+	for value := range gotSet {
+		if !wantSet[value] {
+			score.FalsePositives++
+			spurious = append(spurious, value)
+		}
+	}
+
+	if len(missing) > 0 || len(spurious) > 0 {
+		sort.Strings(missing)
+		sort.Strings(spurious)
+		r.Mismatches = append(r.Mismatches, Mismatch{CaseID: caseID, Field: field, Missing: missing, Spurious: spurious})
+	}
+}
+
+// compute derives precision, recall and F1 from the counts. A field with no
+// predictions and no labels scores 1.
+func (s *FieldScore) compute() {
+	s.Precision, s.Recall = 1, 1
+	if s.TruePositives+s.FalsePositives > 0 {
+		s.Precision = float64(s.TruePositives) / float64(s.TruePositives+s.FalsePositives)
+	}
+	if s.TruePositives+s.FalseNegatives > 0 {
+		s.Recall = float64(s.TruePositives) / float64(s.TruePositives+s.FalseNegatives)
+	}
+	if s.Precision+s.Recall > 0 {
+		s.F1 = 2 * s.Precision * s.Recall / (s.Precision + s.Recall)
+	}
+}
+
+// values returns the labeled values for a field and whether it was labeled at all
+func (x Expectation) values(field string) ([]string, bool) {
+	switch field {
+	case FieldCloudProvider:
+		return []string{x.CloudProvider}, x.CloudProvider != ""
+	case FieldResourceTypes:
+		return x.ResourceTypes, x.ResourceTypes != nil
+	case FieldAttributes:
+		return x.Attributes, x.Attributes != nil
+	case FieldIntent:
+		return []string{x.Intent}, x.Intent != ""
+	case FieldRequirements:
+		return normalizeAll(x.Requirements), x.Requirements != nil
+	}
+	return nil, false
+}
+
+// observe flattens a parse result into the same shape as an Expectation
+func observe(parsed *ParsedInput) map[string][]string {
+	got := map[string][]string{
+		FieldCloudProvider: {parsed.CloudProvider},
+		FieldIntent:        {parsed.Intent},
+	}
+
+	for _, resource := range parsed.Resources {
+		got[FieldResourceTypes] = append(got[FieldResourceTypes], resource.Type)
+		for _, attribute := range resource.Attributes {
+			got[FieldAttributes] = append(got[FieldAttributes], resource.Type+"."+attribute)
+		}
+	}
+
+	for _, requirement := range parsed.Requirements {
+		got[FieldRequirements] = append(got[FieldRequirements], normalize(requirement.String()))
+	}
+
+	return got
+}
+
+// normalize canonicalizes a label for comparison
+func normalize(value string) string {
+	return strings.Join(strings.Fields(strings.ToLower(value)), " ")
+}
+
+// normalizeAll canonicalizes a list of labels
+func normalizeAll(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		result = append(result, normalize(value))
+	}
+	return result
+}
+
+// toSet converts values into a set, ignoring empty strings
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, value := range values {
+		if value != "" {
+			set[value] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,207 @@
+package nlp
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultFuzzyTolerance is the maximum edit distance used by NewEngine
+const DefaultFuzzyTolerance = 1
+
+// minFuzzyLength is the shortest keyword that is matched with typos; shorter
+// ones are too close to ordinary words ("subnet" and "subset")
+const minFuzzyLength = 7
+
+// maxFuzzyWords is the longest run of words compared against a keyword
+const maxFuzzyWords = 3
+
+// fuzzyExclusions are ordinary words within one edit of a keyword
+var fuzzyExclusions = map[string]bool{
+	"contained": true,
+}
+
+// Correction records a misspelled or oddly spaced keyword that was matched
+// fuzzily, e.g. "postgress" read as "postgres"
+type Correction struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Distance int    `json:"distance"`
+	Span     Span   `json:"span"`
+}
+
+// SetFuzzyTolerance sets the maximum edit distance for fuzzy keyword
+// matching; 0 disables typo correction but keeps hyphen and space
+// normalization ("loadbalancer", "load-balancer")
+func (e *Engine) SetFuzzyTolerance(tolerance int) {
+	if tolerance < 0 {
+		tolerance = 0
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.fuzzyTolerance = tolerance
+}
+
+// fuzzyKeywords returns the keywords eligible for fuzzy matching: resource
+// types and cloud providers
+func (e *Engine) fuzzyKeywords() []string {
+	var keywords []string
+	for _, groups := range [][]KeywordGroup{e.resourceTypes, e.cloudProviders} {
+		for _, group := range groups {
+			for _, keyword := range group.Keywords {
+				keywords = appendUnique(keywords, keyword)
+			}
+		}
+	}
+	return keywords
+}
+
+// correct rewrites misspelled keywords in text to their vocabulary form and
+// reports the corrections. Offsets in the result map back to text.
+func (e *Engine) correct(text string) (string, []Correction, *offsetMap) {
+	keywords := e.fuzzyKeywords()
+	var corrections []Correction
+
+	corrected, offsets := rewrite(text, func(folded string, i int) (int, string) {
+		if i > 0 {
+			if r, _ := utf8.DecodeLastRuneInString(folded[:i]); isWordRune(r) {
+				return 0, ""
+			}
+		}
+
+		best, bestLength, bestDistance := "", 0, -1
+		for _, end := range wordEnds(folded, i) {
+			segment := folded[i:end]
+			if containsKeyword(segment, keywords) || fuzzyExclusions[segment] {
+				break
+			}
+
+			for _, keyword := range keywords {
+				distance, ok := e.fuzzyDistance(segment, keyword)
+				if ok && (bestDistance < 0 || distance < bestDistance || distance == bestDistance && end-i > bestLength) {
+					best, bestLength, bestDistance = keyword, end-i, distance
+				}
+			}
+		}
+
+		if best == "" {
+			return 0, ""
+		}
+		corrections = append(corrections, Correction{
+			From:     text[i : i+bestLength],
+			To:       best,
+			Distance: bestDistance,
+			Span:     Span{Start: i, End: i + bestLength},
+		})
+		return bestLength, best
+	})
+
+	if len(corrections) == 0 {
+		return text, nil, nil
+	}
+	return corrected, corrections, offsets
+}
+
+// fuzzyDistance compares a run of words with a keyword. Hyphens and spaces
+// are ignored first, so "loadbalancer" matches "load balancer" at distance
+// 0; otherwise keywords of at least minFuzzyLength letters that start with
+// the same letter match within the tolerance.
+func (e *Engine) fuzzyDistance(segment, keyword string) (int, bool) {
+	compactSegment, compactKeyword := compact(segment), compact(keyword)
+	if compactSegment == compactKeyword {
+		return 0, true
+	}
+
+	if e.fuzzyTolerance == 0 || len(compactKeyword) < minFuzzyLength || compactSegment[0] != compactKeyword[0] {
+		return 0, false
+	}
+	if diff := len(compactSegment) - len(compactKeyword); diff > e.fuzzyTolerance || -diff > e.fuzzyTolerance {
+		return 0, false
+	}
+
+	distance := editDistance(compactSegment, compactKeyword)
+	return distance, distance <= e.fuzzyTolerance
+}
+
+// wordEnds returns the end offsets of the first maxFuzzyWords words starting at i
+func wordEnds(folded string, i int) []int {
+	var ends []int
+
+	pos := i
+	for len(ends) < maxFuzzyWords && pos < len(folded) {
+		start := pos
+		for pos < len(folded) {
+			r, size := utf8.DecodeRuneInString(folded[pos:])
+			if !isWordRune(r) {
+				break
+			}
+			pos += size
+		}
+		if pos == start {
+			break
+		}
+		ends = append(ends, pos)
+
+		// Words of a keyword are separated by a single space or hyphen
+		if pos >= len(folded) || (folded[pos] != ' ' && folded[pos] != '-') {
+			break
+		}
+		pos++
+	}
+
+	return ends
+}
+
+// compact removes spaces and hyphens
+func compact(s string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(s)
+}
+
+// containsKeyword reports whether s already matches one of the keywords:
+// as a whole word, or for longer keywords anywhere ("postgresql")
+func containsKeyword(s string, keywords []string) bool {
+	words := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == '-' })
+	for _, keyword := range keywords {
+		if len(keyword) >= 5 && strings.Contains(s, keyword) || contains(words, keyword) || s == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+// editDistance returns the optimal string alignment distance between a and
+// b: insertions, deletions, substitutions and transpositions of adjacent
+// characters each cost 1
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	rows := make([][]int, len(ra)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(rb)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			rows[i][j] = rows[i-1][j-1] + cost
+			if rows[i-1][j]+1 < rows[i][j] {
+				rows[i][j] = rows[i-1][j] + 1
+			}
+			if rows[i][j-1]+1 < rows[i][j] {
+				rows[i][j] = rows[i][j-1] + 1
+			}
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] && rows[i-2][j-2]+1 < rows[i][j] {
+				rows[i][j] = rows[i-2][j-2] + 1
+			}
+		}
+	}
+
+	return rows[len(ra)][len(rb)]
+}
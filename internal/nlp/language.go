@@ -0,0 +1,259 @@
+package nlp
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// LanguageEnglish is the language the vocabulary is written in
+const LanguageEnglish = "en"
+
+// englishStopwords are compared against the stopwords of the language packs
+// so mixed descriptions that are mostly English are not translated
+var englishStopwords = map[string]bool{
+	"the": true, "a": true, "an": true, "and": true, "with": true, "for": true,
+	"in": true, "of": true, "to": true, "on": true, "that": true, "is": true, "i": true, "need": true,
+}
+
+// minLanguageEvidence is the number of words required to detect a language
+const minLanguageEvidence = 2
+
+// language is a language pack prepared for matching
+type language struct {
+	code         string
+	stopwords    map[string]bool
+	phrases      []string // longest first
+	translations map[string]string
+}
+
+// newLanguage prepares a language pack for matching
+func newLanguage(pack LanguagePack) language {
+	l := language{
+		code:         pack.Code,
+		stopwords:    make(map[string]bool),
+		translations: pack.Translations,
+	}
+	for _, stopword := range pack.Stopwords {
+		l.stopwords[stopword] = true
+	}
+	for phrase := range pack.Translations {
+		l.phrases = append(l.phrases, phrase)
+	}
+	sort.Slice(l.phrases, func(i, j int) bool {
+		if len(l.phrases[i]) != len(l.phrases[j]) {
+			return len(l.phrases[i]) > len(l.phrases[j])
+		}
+		return l.phrases[i] < l.phrases[j]
+	})
+	return l
+}
+
+// detectLanguage returns the language whose stopwords and translatable
+// words occur most often in the folded input, or nil for English
+func (e *Engine) detectLanguage(input string) *language {
+	counts := make([]int, len(e.languages))
+	english := 0
+
+	for _, word := range strings.FieldsFunc(input, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if englishStopwords[word] {
+			english++
+		}
+		for i := range e.languages {
+			if _, ok := e.languages[i].translations[word]; ok || e.languages[i].stopwords[word] {
+				counts[i]++
+			}
+		}
+	}
+
+	var best *language
+	bestCount := 0
+	for i := range e.languages {
+		if counts[i] > bestCount {
+			best, bestCount = &e.languages[i], counts[i]
+		}
+	}
+	if bestCount < minLanguageEvidence || bestCount <= english {
+		return nil
+	}
+	return best
+}
+
+// offsetMap maps byte offsets in a normalized text back to the original
+type offsetMap struct {
+	starts []int // normalized offset -> original offset, for span starts
+	ends   []int // normalized offset -> original offset, for span ends
+}
+
+// translate rewrites original phrase by phrase into English. Matching is
+// done on the folded text and untranslated text keeps its casing, so names
+// and tags can still be extracted from the result.
+func (l *language) translate(original string) (string, *offsetMap) {
+	return rewrite(original, func(folded string, i int) (int, string) {
+		phrase := l.matchAt(folded, i)
+		return len(phrase), l.translations[phrase]
+	})
+}
+
+// rewrite builds a new text from original, replacing the spans match
+// reports. match is called at every rune boundary with the folded text and
+// returns the length of the text to replace at i (0 for none) and its
+// replacement. The returned map converts offsets in the new text back to
+// offsets in original.
+func rewrite(original string, match func(folded string, i int) (int, string)) (string, *offsetMap) {
+	folded := foldCase(original)
+
+	var b strings.Builder
+	offsets := &offsetMap{}
+
+	for i := 0; i < len(original); {
+		if n, replacement := match(folded, i); n > 0 {
+			for j := 0; j < len(replacement); j++ {
+				offsets.starts = append(offsets.starts, i)
+				offsets.ends = append(offsets.ends, i+n)
+			}
+			b.WriteString(replacement)
+			i += n
+			continue
+		}
+
+		_, size := utf8.DecodeRuneInString(original[i:])
+		for j := 0; j < size; j++ {
+			offsets.starts = append(offsets.starts, i+j)
+			offsets.ends = append(offsets.ends, i+j+1)
+		}
+		b.WriteString(original[i : i+size])
+		i += size
+	}
+
+	// The end of the text maps to the end of the original
+	offsets.starts = append(offsets.starts, len(original))
+	offsets.ends = append(offsets.ends, len(original))
+
+	return b.String(), offsets
+}
+
+// matchAt returns the longest phrase that occurs as whole words at position i
+func (l *language) matchAt(folded string, i int) string {
+	if i > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(folded[:i]); isWordRune(r) {
+			return ""
+		}
+	}
+
+	for _, phrase := range l.phrases {
+		if !strings.HasPrefix(folded[i:], phrase) {
+			continue
+		}
+		end := i + len(phrase)
+		if end < len(folded) {
+			if r, _ := utf8.DecodeRuneInString(folded[end:]); isWordRune(r) {
+				continue
+			}
+		}
+		return phrase
+	}
+
+	return ""
+}
+
+// isWordRune reports whether r can be part of a word
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// span maps a span of the normalized text to the original text
+func (m *offsetMap) span(span Span) Span {
+	if span.Start < 0 || span.End < span.Start || span.End >= len(m.ends) {
+		return span
+	}
+	start := m.starts[span.Start]
+	end := start
+	if span.End > span.Start {
+		end = m.ends[span.End-1]
+	}
+	return Span{Start: start, End: end}
+}
+
+// remap converts every span in parsed from normalized to original offsets
+func (m *offsetMap) remap(parsed *ParsedInput) {
+	for i := range parsed.Resources {
+		parsed.Resources[i].Span = m.span(parsed.Resources[i].Span)
+	}
+	for i := range parsed.Requirements {
+		parsed.Requirements[i].Span = m.span(parsed.Requirements[i].Span)
+	}
+	for i := range parsed.Frameworks {
+		parsed.Frameworks[i].Span = m.span(parsed.Frameworks[i].Span)
+	}
+	for i := range parsed.Corrections {
+		parsed.Corrections[i].Span = m.span(parsed.Corrections[i].Span)
+	}
+	if parsed.Budget != nil {
+		parsed.Budget.Span = m.span(parsed.Budget.Span)
+	}
+	if parsed.Workload != nil {
+		parsed.Workload.Span = m.span(parsed.Workload.Span)
+	}
+
+	entities := &parsed.Entities
+	for i := range entities.Regions {
+		entities.Regions[i].Span = m.span(entities.Regions[i].Span)
+	}
+	for i := range entities.AvailabilityZones {
+		entities.AvailabilityZones[i].Span = m.span(entities.AvailabilityZones[i].Span)
+	}
+	for i := range entities.CIDRBlocks {
+		entities.CIDRBlocks[i].Span = m.span(entities.CIDRBlocks[i].Span)
+	}
+	for i := range entities.InstanceTypes {
+		entities.InstanceTypes[i].Span = m.span(entities.InstanceTypes[i].Span)
+	}
+	for i := range entities.EngineVersions {
+		entities.EngineVersions[i].Span = m.span(entities.EngineVersions[i].Span)
+	}
+	for i := range entities.Ports {
+		entities.Ports[i].Span = m.span(entities.Ports[i].Span)
+	}
+	for i := range entities.Durations {
+		entities.Durations[i].Span = m.span(entities.Durations[i].Span)
+	}
+	for i := range entities.Environments {
+		entities.Environments[i].Span = m.span(entities.Environments[i].Span)
+	}
+	for i := range entities.Names {
+		entities.Names[i].Span = m.span(entities.Names[i].Span)
+	}
+	for i := range entities.Tags {
+		entities.Tags[i].Span = m.span(entities.Tags[i].Span)
+	}
+}
+
+// LanguageName returns the display name of a language code known to the
+// built-in vocabulary, or the code itself
+func LanguageName(code string) string {
+	if code == LanguageEnglish {
+		return "English"
+	}
+	for _, pack := range builtinLanguages() {
+		if pack.Code == code {
+			return pack.Name
+		}
+	}
+	return code
+}
+
+var (
+	builtinLanguagesOnce sync.Once
+	builtinLanguagePacks []LanguagePack
+)
+
+// builtinLanguages returns the language packs of the built-in vocabulary
+func builtinLanguages() []LanguagePack {
+	builtinLanguagesOnce.Do(func() {
+		builtinLanguagePacks = DefaultVocabulary().Languages
+	})
+	return builtinLanguagePacks
+}
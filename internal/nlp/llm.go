@@ -0,0 +1,407 @@
+package nlp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Parser modes accepted by NewParser
+const (
+	ParserKeyword = "keyword"
+	ParserLLM     = "llm"
+	ParserHybrid  = "hybrid"
+)
+
+// Completer sends a prompt to a language model and returns its reply
+type Completer interface {
+	Complete(ctx context.Context, prompt string) (string, error)
+}
+
+// NewParser creates a parser for the given mode around the keyword engine;
+// completer is only required for the llm and hybrid modes
+func NewParser(mode string, engine *Engine, completer Completer) (Parser, error) {
+	switch strings.ToLower(mode) {
+	case "", ParserKeyword:
+		return engine, nil
+	case ParserLLM:
+		if completer == nil {
+			return nil, fmt.Errorf("parser mode %q requires a language model", mode)
+		}
+		return NewLLMParser(completer), nil
+	case ParserHybrid:
+		if completer == nil {
+			return nil, fmt.Errorf("parser mode %q requires a language model", mode)
+		}
+		return NewHybridParser(engine, NewLLMParser(completer)), nil
+	default:
+		return nil, fmt.Errorf("unknown parser mode: %s (use keyword, llm or hybrid)", mode)
+	}
+}
+
+// LLMParser extracts structured information by asking a language model for JSON
+type LLMParser struct {
+	completer Completer
+	timeout   time.Duration
+}
+
+// llmExtraction is the JSON document the model is asked to return
+type llmExtraction struct {
+	CloudProvider string `json:"cloud_provider"`
+	Intent        string `json:"intent"`
+	Resources     []struct {
+		Type       string   `json:"type"`
+		Attributes []string `json:"attributes"`
+	} `json:"resources"`
+	Requirements []struct {
+		Category string `json:"category"`
+		Text     string `json:"text"`
+		Kind     string `json:"kind"`
+		Target   string `json:"target"`
+		Value    string `json:"value"`
+		Unit     string `json:"unit"`
+	} `json:"requirements"`
+	Relations []struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+		Kind string `json:"kind"`
+	} `json:"relations"`
+	Regions       []string `json:"regions"`
+	Environments  []string `json:"environments"`
+	Frameworks    []string `json:"frameworks"`
+	Language      string   `json:"language"`
+	MonthlyBudget float64  `json:"monthly_budget"`
+	WorkloadSize  string   `json:"workload_size"`
+	DailyUsers    int      `json:"daily_users"`
+}
+
+// NewLLMParser creates a parser backed by a language model
+func NewLLMParser(completer Completer) *LLMParser {
+	return &LLMParser{
+		completer: completer,
+		timeout:   30 * time.Second,
+	}
+}
+
+// Parse asks the language model to extract structured information from the
+// input. A provider or intent the model left out defaults to aws and create.
+func (p *LLMParser) Parse(input string) (*ParsedInput, error) {
+	parsed, err := p.extract(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if parsed.CloudProvider == "" {
+		parsed.CloudProvider = "aws"
+		for i := range parsed.Entities.Regions {
+			parsed.Entities.Regions[i].Provider = parsed.CloudProvider
+		}
+	}
+	if parsed.Intent == "" {
+		parsed.Intent = "create"
+	}
+
+	return parsed, nil
+}
+
+// extract asks the language model for its extraction, leaving the provider
+// and intent empty when the model did not return a known one
+func (p *LLMParser) extract(input string) (*ParsedInput, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	reply, err := p.completer.Complete(ctx, p.buildPrompt(input))
+	if err != nil {
+		return nil, fmt.Errorf("language model extraction failed: %w", err)
+	}
+
+	var extraction llmExtraction
+	if err := json.Unmarshal([]byte(extractJSON(reply)), &extraction); err != nil {
+		return nil, fmt.Errorf("failed to decode language model extraction: %w", err)
+	}
+
+	return extraction.toParsedInput(input), nil
+}
+
+// buildPrompt constructs the structured-extraction prompt
+func (p *LLMParser) buildPrompt(input string) string {
+	var prompt strings.Builder
+
+	prompt.WriteString("Extract the infrastructure requirements from the description below.\n")
+	prompt.WriteString("Respond with a single JSON object and nothing else, using this shape:\n")
+	prompt.WriteString(`{"cloud_provider": "aws|azure|gcp", "intent": "create|modify|delete", `)
+	prompt.WriteString(`"resources": [{"type": "compute|storage|network|database|container|serverless", "attributes": ["key:value"]}], `)
+	prompt.WriteString(`"requirements": [{"category": "Security|Scalability|Performance|Specification", "text": "words from the description", `)
+	prompt.WriteString(`"kind": "encryption_at_rest|encryption_in_transit|private_access|autoscaling|high_availability|multi_az|min_instances|cpu_count|memory_size|storage_size|...", `)
+	prompt.WriteString(`"target": "resource type or empty", "value": "number or empty", "unit": "gb|tb|mb or empty"}], `)
+	prompt.WriteString(`"relations": [{"from": "resource type", "to": "resource type", "kind": "behind|connects_to|in_subnet|replicates_to"}], `)
+	prompt.WriteString(`"regions": ["region"], "environments": ["dev|staging|prod"], "frameworks": ["hipaa|pci-dss|soc2|cis|fedramp"], "language": "ISO 639-1 code of the description", `)
+	prompt.WriteString(`"monthly_budget": 0, "workload_size": "small|medium|large|xlarge or empty", "daily_users": 0}` + "\n")
+	prompt.WriteString("Use attributes such as os:linux, access:private or engine:postgresql.\n")
+	prompt.WriteString("Set monthly_budget to the stated budget ceiling in USD per month and daily_users to the expected number of daily users, as numbers.\n\n")
+	prompt.WriteString("Description: " + input + "\n")
+
+	return prompt.String()
+}
+
+// toParsedInput converts the model's extraction, dropping values outside the
+// known vocabulary; an unknown provider or intent is left empty
+func (x *llmExtraction) toParsedInput(input string) *ParsedInput {
+	parsed := &ParsedInput{
+		OriginalText:  input,
+		CloudProvider: strings.ToLower(x.CloudProvider),
+		Resources:     []Resource{},
+		Requirements:  []Requirement{},
+		Intent:        strings.ToLower(x.Intent),
+		Language:      strings.ToLower(x.Language),
+	}
+
+	if !contains([]string{"aws", "azure", "gcp"}, parsed.CloudProvider) {
+		parsed.CloudProvider = ""
+	}
+	if !contains([]string{"create", "modify", "delete"}, parsed.Intent) {
+		parsed.Intent = ""
+	}
+	if len(parsed.Language) != 2 {
+		parsed.Language = LanguageEnglish
+	}
+
+	for _, r := range x.Resources {
+		resourceType := strings.ToLower(r.Type)
+		if !contains(knownResourceTypes, resourceType) || findResource(parsed, resourceType) != nil {
+			continue
+		}
+
+		attributes := []string{}
+		for _, attribute := range r.Attributes {
+			if strings.Contains(attribute, ":") {
+				attributes = append(attributes, strings.ToLower(attribute))
+			}
+		}
+
+		parsed.Resources = append(parsed.Resources, Resource{
+			Type:       resourceType,
+			Name:       defaultResourceName(resourceType),
+			Properties: make(map[string]string),
+			Attributes: attributes,
+			Span:       NoSpan,
+		})
+	}
+
+	for _, r := range x.Requirements {
+		requirement := Requirement{
+			Category: strings.TrimSpace(r.Category),
+			Text:     strings.TrimSpace(r.Text),
+			Kind:     strings.ToLower(strings.TrimSpace(r.Kind)),
+			Target:   strings.ToLower(strings.TrimSpace(r.Target)),
+			Value:    strings.TrimSpace(r.Value),
+			Unit:     strings.ToLower(strings.TrimSpace(r.Unit)),
+			Span:     NoSpan,
+		}
+		if requirement.Category == "" {
+			requirement.Category = "General"
+		}
+		if requirement.Kind == "" {
+			requirement.Kind = keywordKind(strings.ToLower(requirement.Text))
+		}
+		if validateRequirement(requirement) != nil {
+			continue
+		}
+		parsed.Requirements = append(parsed.Requirements, requirement)
+	}
+
+	kinds := []string{RelationBehind, RelationConnectsTo, RelationInSubnet, RelationReplicatesTo}
+	for _, r := range x.Relations {
+		from, to, kind := strings.ToLower(r.From), strings.ToLower(r.To), strings.ToLower(strings.TrimSpace(r.Kind))
+		if !contains(kinds, kind) || findResource(parsed, from) == nil || findResource(parsed, to) == nil || from == to {
+			continue
+		}
+		parsed.Relations = append(parsed.Relations, Relation{
+			From: defaultResourceName(from),
+			To:   defaultResourceName(to),
+			Kind: kind,
+		})
+	}
+
+	for _, region := range x.Regions {
+		parsed.Entities.Regions = append(parsed.Entities.Regions, Region{Name: strings.ToLower(region), Provider: parsed.CloudProvider, Span: NoSpan})
+	}
+	for _, environment := range x.Environments {
+		parsed.Entities.Environments = append(parsed.Entities.Environments, Environment{Name: strings.ToLower(environment), Span: NoSpan})
+	}
+
+	for _, id := range x.Frameworks {
+		id = strings.ToLower(id)
+		if name := FrameworkName(id); name != "" && !containsFramework(parsed.Frameworks, id) {
+			parsed.Frameworks = append(parsed.Frameworks, ComplianceFramework{ID: id, Name: name, Span: NoSpan})
+		}
+	}
+	expandFrameworks(parsed)
+
+	if x.MonthlyBudget > 0 {
+		parsed.Budget = &Budget{Amount: x.MonthlyBudget, Currency: "USD", Span: NoSpan}
+	}
+	if x.DailyUsers > 0 {
+		parsed.Workload = &Workload{Tier: tierForUsers(x.DailyUsers), DailyUsers: x.DailyUsers, Span: NoSpan}
+	} else if tier := strings.ToLower(x.WorkloadSize); contains(sizeTiers, tier) {
+		parsed.Workload = &Workload{Tier: tier, Span: NoSpan}
+	}
+
+	return parsed
+}
+
+// extractJSON returns the outermost JSON object in a model reply, ignoring code fences
+func extractJSON(reply string) string {
+	start := strings.Index(reply, "{")
+	end := strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return reply
+	}
+	return reply[start : end+1]
+}
+
+// HybridParser merges the output of a keyword parser with a language model parser
+type HybridParser struct {
+	keyword Parser
+	llm     *LLMParser
+}
+
+// NewHybridParser creates a parser that merges keyword and language model results
+func NewHybridParser(keyword Parser, llm *LLMParser) *HybridParser {
+	return &HybridParser{
+		keyword: keyword,
+		llm:     llm,
+	}
+}
+
+// Parse runs both parsers and merges their results. The keyword result is
+// returned on its own when the language model is unavailable.
+func (h *HybridParser) Parse(input string) (*ParsedInput, error) {
+	parsed, err := h.keyword.Parse(input)
+	if err != nil {
+		return nil, err
+	}
+
+	extracted, err := h.llm.extract(input)
+	if err != nil {
+		return parsed, nil
+	}
+
+	mergeParsedInput(parsed, extracted)
+	return parsed, nil
+}
+
+// mergeParsedInput folds the language model's extraction into the keyword result
+func mergeParsedInput(parsed, extracted *ParsedInput) {
+	// The model understands context better than keyword matching for the
+	// provider and intent, when it named them; entities stay with the
+	// deterministic extractors
+	if extracted.CloudProvider != "" {
+		parsed.CloudProvider = extracted.CloudProvider
+	}
+	if extracted.Intent != "" {
+		parsed.Intent = extracted.Intent
+	}
+
+	for _, resource := range extracted.Resources {
+		existing := findResource(parsed, resource.Type)
+		if existing == nil {
+			parsed.Resources = append(parsed.Resources, resource)
+			continue
+		}
+		for _, attribute := range resource.Attributes {
+			existing.Attributes = appendUnique(existing.Attributes, attribute)
+		}
+	}
+
+	for _, requirement := range extracted.Requirements {
+		if !containsRequirement(parsed.Requirements, requirement) {
+			parsed.Requirements = append(parsed.Requirements, requirement)
+		}
+	}
+
+	// Relations refer to resources by name, so only keep model relations
+	// whose endpoints exist in the merged result
+	names := make(map[string]bool)
+	for _, resource := range parsed.Resources {
+		names[resource.Name] = true
+	}
+	for _, relation := range extracted.Relations {
+		if names[relation.From] && names[relation.To] && !containsRelation(parsed.Relations, relation) {
+			parsed.Relations = append(parsed.Relations, relation)
+		}
+	}
+
+	if len(parsed.Entities.Regions) == 0 {
+		for _, region := range extracted.Entities.Regions {
+			region.Provider = parsed.CloudProvider
+			parsed.Entities.Regions = append(parsed.Entities.Regions, region)
+		}
+	}
+	for _, environment := range extracted.Entities.Environments {
+		if !containsEnvironment(parsed.Entities.Environments, environment.Name) {
+			parsed.Entities.Environments = append(parsed.Entities.Environments, environment)
+		}
+	}
+
+	// Frameworks are expanded again so resources the model added get their controls
+	for _, framework := range extracted.Frameworks {
+		if !containsFramework(parsed.Frameworks, framework.ID) {
+			parsed.Frameworks = append(parsed.Frameworks, framework)
+		}
+	}
+	expandFrameworks(parsed)
+
+	if parsed.Budget == nil {
+		parsed.Budget = extracted.Budget
+	}
+	if parsed.Workload == nil {
+		parsed.Workload = extracted.Workload
+	}
+}
+
+// containsRequirement reports whether an equivalent requirement is present:
+// the same category and text, or the same constraint on the same target
+func containsRequirement(requirements []Requirement, requirement Requirement) bool {
+	for _, r := range requirements {
+		if strings.EqualFold(r.Category, requirement.Category) && strings.EqualFold(r.Text, requirement.Text) {
+			return true
+		}
+		if r.Kind == requirement.Kind && r.Target == requirement.Target && r.Value == requirement.Value {
+			return true
+		}
+	}
+	return false
+}
+
+// containsEnvironment reports whether an environment with the given name is present
+func containsEnvironment(environments []Environment, name string) bool {
+	for _, environment := range environments {
+		if environment.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFramework reports whether a framework with the given identifier is present
+func containsFramework(frameworks []ComplianceFramework, id string) bool {
+	for _, framework := range frameworks {
+		if framework.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// containsRelation reports whether relation is in relations
+func containsRelation(relations []Relation, relation Relation) bool {
+	for _, r := range relations {
+		if r == relation {
+			return true
+		}
+	}
+	return false
+}
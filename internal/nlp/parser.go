@@ -3,59 +3,159 @@ package nlp
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 )
 
-// ParsedInput represents the structured output from natural language parsing
+// ParsedInput represents the structured output from natural language parsing.
+// OriginalText is kept verbatim; all spans are byte offsets into it.
+// Language is the ISO 639-1 code of the language the input was written in.
 type ParsedInput struct {
-	OriginalText  string
-	CloudProvider string
-	Resources     []Resource
-	Requirements  []string
-	Intent        string
+	OriginalText  string                `json:"original_text"`
+	Language      string                `json:"language"`
+	CloudProvider string                `json:"cloud_provider"`
+	Resources     []Resource            `json:"resources"`
+	Requirements  []Requirement         `json:"requirements"`
+	Intent        string                `json:"intent"`
+	Entities      Entities              `json:"entities"`
+	Relations     []Relation            `json:"relations,omitempty"`
+	Frameworks    []ComplianceFramework `json:"frameworks,omitempty"`
+	Corrections   []Correction          `json:"corrections,omitempty"`
+	Budget        *Budget               `json:"budget,omitempty"`
+	Workload      *Workload             `json:"workload,omitempty"`
 }
 
 // Resource represents an identified infrastructure resource
 type Resource struct {
-	Type       string
-	Name       string
-	Properties map[string]string
-	Attributes []string
+	Type       string            `json:"type"`
+	Name       string            `json:"name"`
+	Properties map[string]string `json:"properties,omitempty"`
+	Attributes []string          `json:"attributes,omitempty"`
+	Span       Span              `json:"span"`
 }
 
-// Engine handles natural language processing
+// Requirement represents a constraint found in the input. Text is the
+// keyword or specification that was matched ("encrypted", "3 instance"),
+// Kind the constraint it expresses and Target the resource type it applies
+// to, if any. Value and Unit are set for quantitative constraints such as
+// min_instances=3 or storage_size=100gb. Requirements expanded from a
+// compliance framework carry its identifier in Framework, and requirements
+// stated for a single environment ("prod multi-az") carry its name in
+// Environment.
+type Requirement struct {
+	Category    string `json:"category"`
+	Text        string `json:"text"`
+	Kind        string `json:"kind"`
+	Target      string `json:"target,omitempty"`
+	Value       string `json:"value,omitempty"`
+	Unit        string `json:"unit,omitempty"`
+	Framework   string `json:"framework,omitempty"`
+	Environment string `json:"environment,omitempty"`
+	Span        Span   `json:"span"`
+}
+
+// String renders the requirement as "Category: text"
+func (r Requirement) String() string {
+	return r.Category + ": " + r.Text
+}
+
+// Parser converts a natural language description into structured input
+type Parser interface {
+	Parse(input string) (*ParsedInput, error)
+}
+
+// Engine handles natural language processing using keyword matching
 type Engine struct {
-	cloudProviders map[string][]string
-	resourceTypes  map[string][]string
+	mu             sync.RWMutex
+	cloudProviders []KeywordGroup
+	resourceTypes  []KeywordGroup
+	requirements   []KeywordGroup
+	specifications []*regexp.Regexp
+	intents        []KeywordGroup
+	workloadSizes  []KeywordGroup
+	languages      []language
+	fuzzyTolerance int
 }
 
-// NewEngine creates a new NLP engine
+// NewEngine creates a new NLP engine using the built-in vocabulary
 func NewEngine() *Engine {
-	return &Engine{
-		cloudProviders: map[string][]string{
-			"aws":   {"aws", "amazon", "ec2", "s3", "rds", "vpc", "lambda"},
-			"azure": {"azure", "microsoft", "vm", "storage", "sql"},
-			"gcp":   {"gcp", "google", "compute", "storage", "cloud"},
-		},
-		resourceTypes: map[string][]string{
-			"compute":    {"vm", "instance", "server", "compute", "ec2"},
-			"storage":    {"storage", "bucket", "s3", "blob", "disk"},
-			"network":    {"vpc", "network", "subnet", "security group", "firewall", "load balancer", "alb", "nlb"},
-			"database":   {"database", "db", "rds", "sql", "mysql", "postgres", "mongodb"},
-			"container":  {"container", "kubernetes", "k8s", "docker", "ecs", "aks", "gke"},
-			"serverless": {"lambda", "function", "serverless", "azure functions", "cloud functions"},
-		},
+	engine := &Engine{fuzzyTolerance: DefaultFuzzyTolerance}
+	if err := engine.SetVocabulary(DefaultVocabulary()); err != nil {
+		panic(fmt.Sprintf("invalid built-in vocabulary: %v", err))
 	}
+	return engine
+}
+
+// SetVocabulary validates and installs a vocabulary. It is safe to call while
+// other goroutines are parsing.
+func (e *Engine) SetVocabulary(vocabulary *Vocabulary) error {
+	if err := vocabulary.Validate(); err != nil {
+		return err
+	}
+
+	specifications := make([]*regexp.Regexp, 0, len(vocabulary.Specifications))
+	for _, pattern := range vocabulary.Specifications {
+		specifications = append(specifications, regexp.MustCompile(pattern))
+	}
+
+	languages := make([]language, 0, len(vocabulary.Languages))
+	for _, pack := range vocabulary.Languages {
+		languages = append(languages, newLanguage(pack))
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.cloudProviders = vocabulary.CloudProviders
+	e.resourceTypes = vocabulary.ResourceTypes
+	e.requirements = vocabulary.Requirements
+	e.specifications = specifications
+	e.intents = vocabulary.Intents
+	e.workloadSizes = vocabulary.WorkloadSizes
+	e.languages = languages
+
+	return nil
+}
+
+// Reload loads the built-in vocabulary merged with the given files and
+// installs it. The current vocabulary is kept if loading fails.
+func (e *Engine) Reload(paths ...string) error {
+	vocabulary, err := LoadVocabulary(paths...)
+	if err != nil {
+		return err
+	}
+	return e.SetVocabulary(vocabulary)
 }
 
 // Parse processes natural language input and extracts structured information
 func (e *Engine) Parse(input string) (*ParsedInput, error) {
-	input = strings.ToLower(strings.TrimSpace(input))
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	original := strings.TrimSpace(input)
+
+	// Descriptions in another language are normalized to English first;
+	// extraction runs on the normalized text and spans are mapped back
+	text, language := original, LanguageEnglish
+	var offsets *offsetMap
+	if l := e.detectLanguage(foldCase(original)); l != nil {
+		text, offsets = l.translate(original)
+		language = l.code
+	}
+
+	// Misspelled keywords are corrected the same way
+	text, corrections, corrected := e.correct(text)
+
+	// Matching is case-insensitive; the folded copy keeps byte offsets aligned
+	// with the text so spans can be used on either
+	input = foldCase(text)
 
 	parsed := &ParsedInput{
-		OriginalText: input,
+		OriginalText: text,
+		Language:     language,
 		Resources:    []Resource{},
-		Requirements: []string{},
+		Requirements: []Requirement{},
 	}
 
 	// Detect cloud provider
@@ -64,119 +164,200 @@ func (e *Engine) Parse(input string) (*ParsedInput, error) {
 	// Extract resources
 	parsed.Resources = e.extractResources(input)
 
+	// Extract relationships between resources
+	parsed.Relations = e.extractRelations(input, parsed.Resources)
+
 	// Extract requirements
 	parsed.Requirements = e.extractRequirements(input)
 
+	// Expand compliance frameworks into explicit requirements
+	parsed.Frameworks = extractFrameworks(input)
+	expandFrameworks(parsed)
+
 	// Determine intent
 	parsed.Intent = e.determineIntent(input)
 
+	// Extract typed entities (regions, CIDRs, ports, ...)
+	parsed.Entities = e.extractEntities(input)
+
+	// Extract budget ceilings and workload size hints
+	parsed.Budget = extractBudget(input)
+	parsed.Workload = e.extractWorkload(input)
+
+	// Scope requirements stated for one environment to it
+	scopeRequirements(parsed, input)
+
+	// Extract user-provided names and tags with their original casing
+	parsed.Entities.Names = extractNames(text)
+	parsed.Entities.Tags = extractTags(text)
+	assignNames(parsed)
+
+	if corrected != nil {
+		corrected.remap(parsed)
+	}
+	parsed.Corrections = corrections
+	if offsets != nil {
+		offsets.remap(parsed)
+	}
+	parsed.OriginalText = original
+
 	return parsed, nil
 }
 
-// detectCloudProvider identifies the cloud provider from the input
+// detectCloudProvider identifies the cloud provider from the input. Each
+// provider scores the total length of its keywords found in the input, so
+// specific phrases ("oracle cloud") outweigh short ones ("cloud"). Keywords
+// shared by several providers ("storage") say nothing about the provider and
+// are ignored; ties go to the provider listed first in the vocabulary.
 func (e *Engine) detectCloudProvider(input string) string {
-	for provider, keywords := range e.cloudProviders {
-		for _, keyword := range keywords {
-			if strings.Contains(input, keyword) {
-				return provider
+	best, bestScore := "aws", 0 // Default to AWS
+
+	owners := e.providerKeywordOwners()
+	for _, provider := range e.cloudProviders {
+		score := 0
+		for _, keyword := range provider.Keywords {
+			if owners[keyword] == 1 && strings.Contains(input, keyword) {
+				score += len(keyword)
 			}
 		}
+		if score > bestScore {
+			best, bestScore = provider.Name, score
+		}
 	}
-	return "aws" // Default to AWS
-}
 
-// extractResources identifies infrastructure resources mentioned in the input
-func (e *Engine) extractResources(input string) []Resource {
-	var resources []Resource
+	return best
+}
 
-	for resourceType, keywords := range e.resourceTypes {
-		for _, keyword := range keywords {
-			if strings.Contains(input, keyword) {
-				resource := Resource{
-					Type:       resourceType,
-					Name:       e.generateResourceName(resourceType),
-					Properties: make(map[string]string),
-					Attributes: []string{},
-				}
-
-				// Extract specific attributes based on context
-				resource.Attributes = e.extractAttributes(input, resourceType)
-
-				resources = append(resources, resource)
-				break // Only add each resource type once
+// mentionsProvider reports whether input contains a keyword that identifies
+// provider, as detectCloudProvider would have found it
+func (e *Engine) mentionsProvider(input, provider string) bool {
+	owners := e.providerKeywordOwners()
+	for _, group := range e.cloudProviders {
+		if group.Name != provider {
+			continue
+		}
+		for _, keyword := range group.Keywords {
+			if owners[keyword] == 1 && strings.Contains(input, keyword) {
+				return true
 			}
 		}
 	}
-
-	return resources
+	return false
 }
 
-// extractRequirements identifies specific requirements from the input
-func (e *Engine) extractRequirements(input string) []string {
-	var requirements []string
-
-	// Security requirements
-	securityPatterns := []string{
-		"secure", "security", "encrypted", "ssl", "tls", "https",
-		"private", "public", "firewall", "access control",
-	}
-
-	for _, pattern := range securityPatterns {
-		if strings.Contains(input, pattern) {
-			requirements = append(requirements, "Security: "+pattern)
+// providerKeywordOwners counts the providers each keyword belongs to
+func (e *Engine) providerKeywordOwners() map[string]int {
+	owners := make(map[string]int)
+	for _, provider := range e.cloudProviders {
+		for _, keyword := range provider.Keywords {
+			owners[keyword]++
 		}
 	}
+	return owners
+}
 
-	// Scalability requirements
-	scalabilityPatterns := []string{
-		"scalable", "auto scaling", "high availability", "redundant",
-		"multi-az", "multi-region", "load balanced",
+// providerNames returns the cloud providers of the vocabulary in order
+func (e *Engine) providerNames() []string {
+	names := make([]string, 0, len(e.cloudProviders))
+	for _, provider := range e.cloudProviders {
+		names = append(names, provider.Name)
 	}
+	return names
+}
+
+// extractResources identifies infrastructure resources mentioned in the input,
+// ordered by where they are first mentioned
+func (e *Engine) extractResources(input string) []Resource {
+	var resources []Resource
 
-	for _, pattern := range scalabilityPatterns {
-		if strings.Contains(input, pattern) {
-			requirements = append(requirements, "Scalability: "+pattern)
+	for _, group := range e.resourceTypes {
+		resourceType := group.Name
+
+		// Only add each resource type once, spanning its first mention
+		span := Span{Start: -1}
+		for _, keyword := range group.Keywords {
+			if i := strings.Index(input, keyword); i >= 0 && (span.Start < 0 || i < span.Start) {
+				span = Span{Start: i, End: i + len(keyword)}
+			}
+		}
+		if span.Start < 0 {
+			continue
 		}
-	}
 
-	// Performance requirements
-	performancePatterns := []string{
-		"fast", "performance", "optimized", "cached", "cdn",
+		resource := Resource{
+			Type:       resourceType,
+			Name:       e.generateResourceName(resourceType),
+			Properties: make(map[string]string),
+			Attributes: []string{},
+			Span:       span,
+		}
+
+		// Extract specific attributes based on context
+		resource.Attributes = e.extractAttributes(input, resourceType)
+
+		resources = append(resources, resource)
 	}
 
-	for _, pattern := range performancePatterns {
-		if strings.Contains(input, pattern) {
-			requirements = append(requirements, "Performance: "+pattern)
-		}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].Span.Start < resources[j].Span.Start
+	})
+
+	return resources
+}
+
+// extractRequirements identifies specific requirements from the input
+func (e *Engine) extractRequirements(input string) []Requirement {
+	requirements := []Requirement{}
+
+	// Keyword requirements (security, scalability, performance, ...)
+	for _, group := range e.requirements {
+		requirements = append(requirements, findRequirements(input, group.Name, group.Keywords)...)
 	}
 
 	// Extract numerical requirements (e.g., "3 servers", "100GB storage")
 	numericRequirements := e.extractNumericRequirements(input)
 	requirements = append(requirements, numericRequirements...)
 
+	resolveTargets(requirements, e.findMentions(input))
+
 	return requirements
 }
 
-// extractNumericRequirements finds numerical specifications in the input
-func (e *Engine) extractNumericRequirements(input string) []string {
-	var requirements []string
-
-	// Pattern for numbers followed by units or resources
-	patterns := []string{
-		`(\d+)\s*(gb|tb|mb)\s*(storage|disk|memory|ram)`,
-		`(\d+)\s*(cpu|core|vcpu)`,
-		`(\d+)\s*(instance|server|vm|node)`,
-		`(\d+)\s*(port|ports)`,
-	}
+// findRequirements returns a requirement spanning the first occurrence of each pattern
+func findRequirements(input, category string, patterns []string) []Requirement {
+	var requirements []Requirement
 
 	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
-		matches := re.FindAllStringSubmatch(input, -1)
-		for _, match := range matches {
-			if len(match) >= 3 {
-				requirements = append(requirements,
-					fmt.Sprintf("Specification: %s %s %s", match[1], match[2], match[3]))
-			}
+		if i := strings.Index(input, pattern); i >= 0 {
+			requirements = append(requirements, Requirement{
+				Category: category,
+				Text:     pattern,
+				Kind:     keywordKind(pattern),
+				Span:     Span{Start: i, End: i + len(pattern)},
+			})
+		}
+	}
+
+	return requirements
+}
+
+// extractNumericRequirements finds numerical specifications in the input
+func (e *Engine) extractNumericRequirements(input string) []Requirement {
+	var requirements []Requirement
+
+	// Patterns for numbers followed by units or resources
+	for _, re := range e.specifications {
+		for _, idx := range re.FindAllStringSubmatchIndex(input, -1) {
+			match := submatches(input, idx)
+			kind, value, unit := specificationKind(match[1:])
+			requirements = append(requirements, Requirement{
+				Category: "Specification",
+				Text:     strings.Join(match[1:], " "),
+				Kind:     kind,
+				Value:    value,
+				Unit:     unit,
+				Span:     Span{Start: idx[0], End: idx[1]},
+			})
 		}
 	}
 
@@ -218,25 +399,11 @@ func (e *Engine) extractAttributes(input string, resourceType string) []string {
 
 // determineIntent identifies the primary intent of the request
 func (e *Engine) determineIntent(input string) string {
-	createWords := []string{"create", "setup", "build", "deploy", "provision"}
-	modifyWords := []string{"update", "modify", "change", "scale", "resize"}
-	deleteWords := []string{"delete", "remove", "destroy", "terminate"}
-
-	for _, word := range createWords {
-		if strings.Contains(input, word) {
-			return "create"
-		}
-	}
-
-	for _, word := range modifyWords {
-		if strings.Contains(input, word) {
-			return "modify"
-		}
-	}
-
-	for _, word := range deleteWords {
-		if strings.Contains(input, word) {
-			return "delete"
+	for _, intent := range e.intents {
+		for _, word := range intent.Keywords {
+			if strings.Contains(input, word) {
+				return intent.Name
+			}
 		}
 	}
 
@@ -246,6 +413,11 @@ func (e *Engine) determineIntent(input string) string {
 
 // generateResourceName creates a resource name based on the type
 func (e *Engine) generateResourceName(resourceType string) string {
+	return defaultResourceName(resourceType)
+}
+
+// defaultResourceName returns the conventional name for a resource type
+func defaultResourceName(resourceType string) string {
 	switch resourceType {
 	case "compute":
 		return "main_instance"
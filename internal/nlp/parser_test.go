@@ -1,6 +1,11 @@
 package nlp
 
 import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -40,7 +45,7 @@ func TestParse(t *testing.T) {
 		{
 			name:     "GCP request",
 			input:    "Deploy Google Cloud compute instances",
-			expected: "google",
+			expected: "gcp",
 		},
 	}
 
@@ -67,7 +72,7 @@ func TestDetectCloudProvider(t *testing.T) {
 	}{
 		{"create aws vpc", "aws"},
 		{"azure virtual machine", "azure"},
-		{"google cloud storage", "google"},
+		{"google cloud storage", "gcp"},
 		{"random infrastructure", "aws"}, // default fallback
 	}
 
@@ -147,3 +152,726 @@ func TestDetermineIntent(t *testing.T) {
 		}
 	}
 }
+
+func TestExtractEntities(t *testing.T) {
+	engine := NewEngine()
+
+	input := "deploy 2 m5.large servers in us-east-1a with postgres 15, " +
+		"a 10.0.0.0/16 vpc, ports 80, 443 and 8000-8100, 7-day backups for prod"
+
+	entities := engine.extractEntities(input)
+
+	if len(entities.Regions) != 1 || entities.Regions[0].Name != "us-east-1" {
+		t.Errorf("Regions = %v, want [us-east-1]", entities.Regions)
+	}
+	if len(entities.AvailabilityZones) != 1 || entities.AvailabilityZones[0].Name != "us-east-1a" {
+		t.Errorf("AvailabilityZones = %v, want [us-east-1a]", entities.AvailabilityZones)
+	}
+	if len(entities.CIDRBlocks) != 1 || entities.CIDRBlocks[0].Value != "10.0.0.0/16" {
+		t.Errorf("CIDRBlocks = %v, want [10.0.0.0/16]", entities.CIDRBlocks)
+	}
+	if len(entities.InstanceTypes) != 1 || entities.InstanceTypes[0].Name != "m5.large" {
+		t.Errorf("InstanceTypes = %v, want [m5.large]", entities.InstanceTypes)
+	}
+	if len(entities.EngineVersions) != 1 || entities.EngineVersions[0].Engine != "postgresql" || entities.EngineVersions[0].Version != "15" {
+		t.Errorf("EngineVersions = %v, want [postgresql 15]", entities.EngineVersions)
+	}
+
+	wantPorts := [][2]int{{80, 80}, {443, 443}, {8000, 8100}}
+	if len(entities.Ports) != len(wantPorts) {
+		t.Fatalf("Ports = %v, want %v", entities.Ports, wantPorts)
+	}
+	for i, port := range wantPorts {
+		if entities.Ports[i].From != port[0] || entities.Ports[i].To != port[1] {
+			t.Errorf("Ports[%d] = %v, want %v", i, entities.Ports[i], port)
+		}
+	}
+
+	if len(entities.Durations) != 1 {
+		t.Fatalf("Durations = %v, want [7 day backups]", entities.Durations)
+	}
+	if duration := entities.Durations[0]; duration.Value != 7 || duration.Unit != "day" || duration.Subject != "backups" {
+		t.Errorf("Durations = %v, want [7 day backups]", entities.Durations)
+	}
+	if len(entities.Environments) != 1 || entities.Environments[0].Name != "prod" {
+		t.Errorf("Environments = %v, want [prod]", entities.Environments)
+	}
+}
+
+func TestExtractRelations(t *testing.T) {
+	engine := NewEngine()
+
+	input := "web servers behind an alb talking to a postgres database in a private subnet"
+	resources := engine.extractResources(input)
+	relations := engine.extractRelations(input, resources)
+
+	expected := []Relation{
+		{From: "main_instance", To: "main_network", Kind: RelationBehind},
+		{From: "main_instance", To: "main_database", Kind: RelationConnectsTo},
+		{From: "main_database", To: "main_network", Kind: RelationInSubnet},
+	}
+
+	if len(relations) != len(expected) {
+		t.Fatalf("extractRelations(%q) = %v, want %v", input, relations, expected)
+	}
+	for i, relation := range expected {
+		if relations[i] != relation {
+			t.Errorf("extractRelations(%q)[%d] = %v, want %v", input, i, relations[i], relation)
+		}
+	}
+}
+
+func TestDependencyGraphDOT(t *testing.T) {
+	parsed := &ParsedInput{
+		Resources: []Resource{{Name: "main_instance"}, {Name: "main_network"}},
+		Relations: []Relation{{From: "main_instance", To: "main_network", Kind: RelationBehind}},
+	}
+
+	dot := parsed.Graph().DOT()
+	if !strings.Contains(dot, `"main_instance" -> "main_network" [label="behind"];`) {
+		t.Errorf("DOT() = %q, missing behind edge", dot)
+	}
+}
+
+func TestClarify(t *testing.T) {
+	engine := NewEngine()
+
+	parsed, err := engine.Parse("set up a public and private network with a database on aws in us-west-2")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	questions := engine.Clarify(parsed)
+	ids := make(map[string]bool)
+	for _, question := range questions {
+		ids[question.ID] = true
+	}
+
+	for _, id := range []string{QuestionDatabaseEngine, QuestionNetworkAccess} {
+		if !ids[id] {
+			t.Errorf("Clarify() missing question %q, got %v", id, questions)
+		}
+	}
+	for _, id := range []string{QuestionResources, QuestionCloudProvider, QuestionRegion} {
+		if ids[id] {
+			t.Errorf("Clarify() asked unexpected question %q", id)
+		}
+	}
+
+	if err := engine.ApplyAnswers(parsed, map[string]string{QuestionDatabaseEngine: "mysql", QuestionNetworkAccess: "private"}); err != nil {
+		t.Fatalf("ApplyAnswers() error = %v", err)
+	}
+	if remaining := engine.Clarify(parsed); len(remaining) != 0 {
+		t.Errorf("Clarify() after answers = %v, want none", remaining)
+	}
+
+	if err := engine.ApplyAnswers(parsed, map[string]string{QuestionDatabaseEngine: "oracle"}); err == nil {
+		t.Error("ApplyAnswers() accepted an invalid engine")
+	}
+}
+
+func TestClarifyCloudProvider(t *testing.T) {
+	engine := NewEngine()
+
+	tests := []struct {
+		name  string
+		input string
+		ask   bool
+	}{
+		{"service keyword", "Create an S3 bucket in us-east-1", false},
+		{"provider name", "Create a bucket on Azure in eastus", false},
+		{"no provider keyword", "Create a bucket in us-east-1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := engine.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			var question *Question
+			for _, q := range engine.Clarify(parsed) {
+				if q.ID == QuestionCloudProvider {
+					question = &q
+				}
+			}
+			if (question != nil) != tt.ask {
+				t.Fatalf("Clarify() asked for the provider = %v, want %v", question != nil, tt.ask)
+			}
+			if question != nil && (strings.Join(question.Options, ",") != "aws,azure,gcp" || question.Default != "aws") {
+				t.Errorf("provider question = %+v, want aws, azure and gcp with aws suggested", question)
+			}
+		})
+	}
+}
+
+type fakeCompleter struct {
+	reply string
+	err   error
+}
+
+func (f *fakeCompleter) Complete(ctx context.Context, prompt string) (string, error) {
+	return f.reply, f.err
+}
+
+func TestHybridParser(t *testing.T) {
+	completer := &fakeCompleter{reply: "```json\n" + `{"cloud_provider": "gcp", "intent": "create",
+		"resources": [{"type": "container", "attributes": []}, {"type": "database", "attributes": ["engine:postgresql"]}],
+		"relations": [{"from": "container", "to": "database", "kind": " Connects_To "}]}` + "\n```"}
+
+	parser, err := NewParser(ParserHybrid, NewEngine(), completer)
+	if err != nil {
+		t.Fatalf("NewParser() error = %v", err)
+	}
+
+	parsed, err := parser.Parse("run our app on a managed cluster next to a db")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if parsed.CloudProvider != "gcp" {
+		t.Errorf("CloudProvider = %v, want gcp", parsed.CloudProvider)
+	}
+	database := findResource(parsed, "database")
+	if findResource(parsed, "container") == nil || database == nil {
+		t.Fatalf("Resources = %v, want container and database", parsed.Resources)
+	}
+	if !contains(database.Attributes, "engine:postgresql") {
+		t.Errorf("database Attributes = %v, want engine:postgresql", database.Attributes)
+	}
+	if len(parsed.Relations) != 1 || parsed.Relations[0].From != "main_cluster" || parsed.Relations[0].Kind != RelationConnectsTo {
+		t.Errorf("Relations = %v, want main_cluster connects_to main_database", parsed.Relations)
+	}
+
+	// A provider or intent the model left out keeps the keyword result
+	completer.reply = `{"resources": [{"type": "storage", "attributes": []}]}`
+	parsed, err = parser.Parse("delete the old Azure blob store")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if parsed.CloudProvider != "azure" || parsed.Intent != "delete" {
+		t.Errorf("CloudProvider, Intent = %v, %v, want azure, delete", parsed.CloudProvider, parsed.Intent)
+	}
+
+	// The keyword result is used alone when the model fails
+	completer.err = context.DeadlineExceeded
+	parsed, err = parser.Parse("create a mysql database")
+	if err != nil {
+		t.Fatalf("Parse() with failing model error = %v", err)
+	}
+	if findResource(parsed, "database") == nil {
+		t.Errorf("Resources = %v, want keyword database", parsed.Resources)
+	}
+}
+
+func TestParsePreservesOriginalText(t *testing.T) {
+	engine := NewEngine()
+
+	input := "Create an S3 bucket named MyAppProd tagged Team=Payments in EU-West-1"
+	parsed, err := engine.Parse(input)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if parsed.OriginalText != input {
+		t.Errorf("OriginalText = %q, want %q", parsed.OriginalText, input)
+	}
+
+	storage := findResource(parsed, "storage")
+	if storage == nil {
+		t.Fatalf("Resources = %v, want storage", parsed.Resources)
+	}
+	if got := parsed.Text(storage.Span); got != "S3" {
+		t.Errorf("Text(storage.Span) = %q, want S3", got)
+	}
+	if storage.Properties["name"] != "MyAppProd" {
+		t.Errorf("storage name = %q, want MyAppProd", storage.Properties["name"])
+	}
+
+	if len(parsed.Entities.Tags) != 1 || parsed.Entities.Tags[0].Key != "Team" || parsed.Entities.Tags[0].Value != "Payments" {
+		t.Errorf("Tags = %v, want Team=Payments", parsed.Entities.Tags)
+	}
+
+	if len(parsed.Entities.Regions) != 1 || parsed.Text(parsed.Entities.Regions[0].Span) != "EU-West-1" {
+		t.Errorf("Regions = %v, want span over EU-West-1", parsed.Entities.Regions)
+	}
+
+	for _, requirement := range parsed.Requirements {
+		if !strings.EqualFold(parsed.Text(requirement.Span), requirement.Text) {
+			t.Errorf("requirement %v span covers %q", requirement, parsed.Text(requirement.Span))
+		}
+	}
+}
+
+func TestParseInvalidUTF8(t *testing.T) {
+	engine := NewEngine()
+
+	input := "\xe1enCrYpted"
+	if folded := foldCase(input); len(folded) != len(input) {
+		t.Fatalf("foldCase(%q) = %q, want %d bytes", input, folded, len(input))
+	}
+
+	parsed, err := engine.Parse(input)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(parsed.Requirements) != 1 || parsed.Requirements[0].Span != (Span{Start: 1, End: 10}) {
+		t.Fatalf("Requirements = %v, want encrypted at 1-10", parsed.Requirements)
+	}
+	if got := parsed.Text(parsed.Requirements[0].Span); got != "enCrYpted" {
+		t.Errorf("Text(span) = %q, want enCrYpted", got)
+	}
+}
+
+func TestVocabularyReload(t *testing.T) {
+	dir := t.TempDir()
+	pack := filepath.Join(dir, "oci.yaml")
+	writeFile(t, pack, `version: 1
+cloud_providers:
+  - name: oci
+    keywords: [oci, oracle cloud]
+resource_types:
+  - name: compute
+    keywords: [droplet]
+`)
+
+	engine := NewEngine()
+	if err := engine.Reload(pack); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	parsed, err := engine.Parse("Provision a droplet on OCI")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if parsed.CloudProvider != "oci" {
+		t.Errorf("CloudProvider = %v, want oci", parsed.CloudProvider)
+	}
+	if findResource(parsed, "compute") == nil {
+		t.Errorf("Resources = %v, want compute from synonym pack", parsed.Resources)
+	}
+
+	// A broken file is rejected and the current vocabulary stays in place
+	broken := filepath.Join(dir, "broken.yaml")
+	writeFile(t, broken, "version: 1\nintents:\n  - name: explode\n    keywords: [boom]\n")
+	if err := engine.Reload(broken); err == nil || !strings.Contains(err.Error(), "unknown intent") {
+		t.Errorf("Reload(broken) error = %v, want unknown intent", err)
+	}
+	if parsed, _ := engine.Parse("droplet on oci"); parsed.CloudProvider != "oci" {
+		t.Errorf("CloudProvider after failed reload = %v, want oci", parsed.CloudProvider)
+	}
+}
+
+func TestParseVocabularyValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"wrong version", "version: 2\n", "unsupported vocabulary version"},
+		{"unknown field", "version: 1\nproviders: []\n", "field providers not found"},
+		{"uppercase keyword", "version: 1\nresource_types:\n  - name: compute\n    keywords: [VM]\n", "must be lowercase"},
+		{"bad pattern", "version: 1\nspecifications: ['(\\d+']\n", "specifications[0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseVocabulary([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseVocabulary() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestClarifyVocabularyProviders(t *testing.T) {
+	vocabulary, err := LoadVocabulary("../../examples/vocabulary/digitalocean.yaml")
+	if err != nil {
+		t.Fatalf("LoadVocabulary() error = %v", err)
+	}
+	engine := NewEngine()
+	if err := engine.SetVocabulary(vocabulary); err != nil {
+		t.Fatalf("SetVocabulary() error = %v", err)
+	}
+
+	// A provider without a suggested region needs neither question
+	parsed, err := engine.Parse("Create a digitalocean droplet")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	questions := engine.Clarify(parsed)
+	if len(questions) != 0 {
+		t.Errorf("Clarify() = %v, want no questions", questions)
+	}
+	if err := engine.ApplyAnswers(parsed, DefaultAnswers(questions)); err != nil {
+		t.Errorf("ApplyAnswers() error = %v", err)
+	}
+
+	// Providers from the vocabulary are offered and accepted
+	parsed, err = engine.Parse("Create a server")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	questions = engine.Clarify(parsed)
+	if len(questions) == 0 || questions[0].ID != QuestionCloudProvider || strings.Join(questions[0].Options, ",") != "aws,azure,gcp,digitalocean,oci" {
+		t.Fatalf("Clarify() = %v, want a provider question with the vocabulary providers", questions)
+	}
+	if err := engine.ApplyAnswers(parsed, map[string]string{QuestionCloudProvider: "digitalocean", QuestionRegion: ""}); err != nil {
+		t.Fatalf("ApplyAnswers() error = %v", err)
+	}
+	if parsed.CloudProvider != "digitalocean" || len(parsed.Entities.Regions) != 0 {
+		t.Errorf("after answers provider = %s, regions = %v, want digitalocean without a region", parsed.CloudProvider, parsed.Entities.Regions)
+	}
+
+	if err := engine.ApplyAnswers(parsed, map[string]string{QuestionCloudProvider: "ibm"}); err == nil {
+		t.Error("ApplyAnswers() accepted a provider outside the vocabulary")
+	}
+	if err := engine.ApplyAnswers(parsed, map[string]string{QuestionCloudProvider: "aws", QuestionRegion: ""}); err == nil {
+		t.Error("ApplyAnswers() accepted an empty region for aws")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestEvaluateCorpus(t *testing.T) {
+	cases, err := LoadCorpus(filepath.Join("testdata", "corpus.jsonl"))
+	if err != nil {
+		t.Fatalf("LoadCorpus() error = %v", err)
+	}
+
+	report := Evaluate(NewEngine(), cases)
+	t.Logf("\n%s", report.Table())
+	for _, mismatch := range report.Mismatches {
+		t.Logf("%s %s: missing %v, spurious %v", mismatch.CaseID, mismatch.Field, mismatch.Missing, mismatch.Spurious)
+	}
+
+	// Raise these as the parser improves; lowering them needs a good reason
+	if failures := report.Failures(0.9, 0.9); len(failures) > 0 {
+		t.Errorf("parser accuracy regressed:\n%s", strings.Join(failures, "\n"))
+	}
+}
+
+func TestTypedRequirements(t *testing.T) {
+	engine := NewEngine()
+
+	parsed, err := engine.Parse("Create 3 ubuntu servers and an encrypted postgres database with 100gb storage")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		kind   string
+		target string
+		value  string
+		unit   string
+	}{
+		{KindEncryptionAtRest, "database", "", ""},
+		{KindMinInstances, "compute", "3", ""},
+		{KindStorageSize, "storage", "100", "gb"},
+	}
+
+	for _, tt := range tests {
+		found := false
+		for _, requirement := range parsed.Requirements {
+			if requirement.Kind == tt.kind {
+				found = true
+				if requirement.Target != tt.target || requirement.Value != tt.value || requirement.Unit != tt.unit {
+					t.Errorf("%s = %q, want %s", tt.kind, requirement.Constraint(), tt.kind+"="+tt.value+tt.unit+" on "+tt.target)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("no %s requirement in %v", tt.kind, parsed.Requirements)
+		}
+	}
+
+	if got := parsed.RequirementsFor("database"); len(got) != 1 || got[0].Kind != KindEncryptionAtRest {
+		t.Errorf("RequirementsFor(database) = %v", got)
+	}
+
+	// A description without requirements has an empty list, not nil
+	parsed, err = engine.Parse("Create a bucket")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if parsed.Requirements == nil || len(parsed.Requirements) != 0 {
+		t.Errorf("Requirements = %#v, want an empty list", parsed.Requirements)
+	}
+}
+
+func TestComplianceFrameworks(t *testing.T) {
+	engine := NewEngine()
+
+	parsed, err := engine.Parse("Create a HIPAA-compliant postgres database and an s3 bucket that meets PCI DSS")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	ids := parsed.FrameworkIDs()
+	if len(ids) != 2 || ids[0] != FrameworkHIPAA || ids[1] != FrameworkPCIDSS {
+		t.Fatalf("FrameworkIDs() = %v, want [hipaa pci-dss]", ids)
+	}
+
+	want := map[string]bool{
+		"hipaa encryption_at_rest on database":       false,
+		"hipaa encryption_at_rest on storage":        false,
+		"hipaa backup_retention=35 days on database": false,
+		"hipaa audit_logging":                        false,
+		"hipaa private_access on database":           false,
+	}
+	for _, requirement := range parsed.Requirements {
+		if requirement.Category != "Compliance" {
+			continue
+		}
+		key := requirement.Framework + " " + requirement.Constraint()
+		if _, ok := want[key]; ok {
+			want[key] = true
+		}
+	}
+	for key, found := range want {
+		if !found {
+			t.Errorf("missing requirement %q in %v", key, parsed.Requirements)
+		}
+	}
+
+	if parsed, _ := engine.Parse("Create a vpc with a public subnet"); len(parsed.Frameworks) != 0 {
+		t.Errorf("Frameworks = %v, want none", parsed.Frameworks)
+	}
+}
+
+func TestEnvironmentScopedRequirements(t *testing.T) {
+	engine := NewEngine()
+
+	parsed, err := engine.Parse("Deploy the same web server and postgres database for dev, staging and prod, with prod multi-AZ and 3 servers in prod")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if names := parsed.EnvironmentNames(); strings.Join(names, ",") != "dev,staging,prod" {
+		t.Fatalf("EnvironmentNames() = %v, want [dev staging prod]", names)
+	}
+
+	scoped := make(map[string]string)
+	for _, requirement := range parsed.Requirements {
+		scoped[requirement.Kind] = requirement.Environment
+	}
+	if scoped[KindMultiAZ] != "prod" {
+		t.Errorf("multi_az environment = %q, want prod", scoped[KindMultiAZ])
+	}
+	if scoped[KindMinInstances] != "prod" {
+		t.Errorf("min_instances environment = %q, want prod", scoped[KindMinInstances])
+	}
+
+	if got := len(parsed.RequirementsForEnvironment("dev")); got != len(parsed.Requirements)-2 {
+		t.Errorf("RequirementsForEnvironment(dev) has %d requirements, want %d", got, len(parsed.Requirements)-2)
+	}
+
+	// A single environment scopes nothing
+	parsed, _ = engine.Parse("Create a prod database that is multi-az")
+	for _, requirement := range parsed.Requirements {
+		if requirement.Environment != "" {
+			t.Errorf("requirement %v scoped to %q with a single environment", requirement, requirement.Environment)
+		}
+	}
+}
+
+func TestMultilingualParse(t *testing.T) {
+	engine := NewEngine()
+
+	english, err := engine.Parse("Create 3 ubuntu servers behind a load balancer and an encrypted private postgres database in AWS")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		language string
+		input    string
+	}{
+		{"es", "Crea 3 servidores Ubuntu detrás de un balanceador de carga y una base de datos Postgres privada cifrada en AWS"},
+		{"de", "Erstelle 3 Ubuntu Server hinter einem Lastverteiler und eine verschlüsselte private Postgres Datenbank in AWS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			parsed, err := engine.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if parsed.Language != tt.language {
+				t.Errorf("Language = %q, want %q", parsed.Language, tt.language)
+			}
+			if parsed.OriginalText != tt.input {
+				t.Errorf("OriginalText = %q, want the input verbatim", parsed.OriginalText)
+			}
+			if parsed.CloudProvider != english.CloudProvider || parsed.Intent != english.Intent {
+				t.Errorf("provider/intent = %s/%s, want %s/%s", parsed.CloudProvider, parsed.Intent, english.CloudProvider, english.Intent)
+			}
+
+			if len(parsed.Resources) != len(english.Resources) {
+				t.Fatalf("Resources = %v, want %v", parsed.Resources, english.Resources)
+			}
+			for i, resource := range parsed.Resources {
+				want := english.Resources[i]
+				if resource.Type != want.Type || strings.Join(resource.Attributes, ",") != strings.Join(want.Attributes, ",") {
+					t.Errorf("resource %d = %s %v, want %s %v", i, resource.Type, resource.Attributes, want.Type, want.Attributes)
+				}
+				if resource.Span.End > len(tt.input) || parsed.Text(resource.Span) == "" {
+					t.Errorf("resource %s span %v does not point into the input", resource.Type, resource.Span)
+				}
+			}
+
+			var got, want []string
+			for _, requirement := range parsed.Requirements {
+				got = append(got, requirement.Constraint())
+			}
+			for _, requirement := range english.Requirements {
+				want = append(want, requirement.Constraint())
+			}
+			if strings.Join(got, "; ") != strings.Join(want, "; ") {
+				t.Errorf("Requirements = %v, want %v", got, want)
+			}
+
+			if len(parsed.Relations) != len(english.Relations) {
+				t.Errorf("Relations = %v, want %v", parsed.Relations, english.Relations)
+			}
+		})
+	}
+
+	if english.Language != LanguageEnglish {
+		t.Errorf("Language = %q, want en", english.Language)
+	}
+}
+
+func TestFuzzyMatching(t *testing.T) {
+	engine := NewEngine()
+
+	parsed, err := engine.Parse("Deploy a postgrse database and a kubernets cluster behind a loadbalancer on Microsfot Azure")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if parsed.CloudProvider != "azure" {
+		t.Errorf("CloudProvider = %q, want azure", parsed.CloudProvider)
+	}
+	for _, resourceType := range []string{"database", "container", "network"} {
+		if findResource(parsed, resourceType) == nil {
+			t.Errorf("expected resource %s in %v", resourceType, parsed.Resources)
+		}
+	}
+
+	corrected := make(map[string]string)
+	for _, correction := range parsed.Corrections {
+		corrected[correction.From] = correction.To
+		if got := parsed.Text(correction.Span); got != correction.From {
+			t.Errorf("correction span %v = %q, want %q", correction.Span, got, correction.From)
+		}
+	}
+	for from, to := range map[string]string{"postgrse": "postgres", "kubernets": "kubernetes", "loadbalancer": "load balancer", "Microsfot": "microsoft"} {
+		if corrected[from] != to {
+			t.Errorf("correction of %q = %q, want %q", from, corrected[from], to)
+		}
+	}
+
+	// Without tolerance only spacing is normalized
+	engine.SetFuzzyTolerance(0)
+	parsed, err = engine.Parse("Deploy a postgrse database behind a load-balancer")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(parsed.Corrections) != 1 || parsed.Corrections[0].To != "load balancer" {
+		t.Errorf("Corrections = %v, want only load-balancer", parsed.Corrections)
+	}
+
+	// Ordinary words close to a keyword are left alone
+	engine.SetFuzzyTolerance(DefaultFuzzyTolerance)
+	parsed, err = engine.Parse("Create a bucket for the contained reports")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(parsed.Corrections) != 0 || findResource(parsed, "container") != nil {
+		t.Errorf("Corrections = %v, want none", parsed.Corrections)
+	}
+}
+
+func TestBudgetAndSizing(t *testing.T) {
+	engine := NewEngine()
+
+	tests := []struct {
+		input   string
+		budget  float64
+		tier    string
+		users   int
+		compute string
+	}{
+		{"Create a small web app on AWS with a postgres database under $200/month", 200, TierSmall, 0, "t3.micro"},
+		{"Production-grade kubernetes cluster on GCP for about 10k daily users", 0, TierMedium, 10000, "e2-medium"},
+		{"Production-grade web servers on AWS, budget of 1,200 dollars per year", 100, TierMedium, 0, "t3.medium"},
+		{"3 t3.small servers on AWS", 0, "", 0, ""},
+		{"Un servidor pequeño en AWS por menos de $50 al mes", 50, TierSmall, 0, "t3.micro"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			parsed, err := engine.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if tt.budget == 0 && parsed.Budget != nil {
+				t.Errorf("Budget = %v, want none", parsed.Budget)
+			}
+			if tt.budget > 0 {
+				if parsed.Budget == nil || parsed.Budget.Amount != tt.budget {
+					t.Fatalf("Budget = %v, want %v per month", parsed.Budget, tt.budget)
+				}
+				if parsed.Text(parsed.Budget.Span) == "" {
+					t.Errorf("budget span %v does not point into the input", parsed.Budget.Span)
+				}
+			}
+
+			if tt.users > 0 && (parsed.Workload == nil || parsed.Workload.DailyUsers != tt.users) {
+				t.Errorf("Workload = %+v, want %d daily users", parsed.Workload, tt.users)
+			}
+			if got := parsed.SizeTier(); got != tt.tier {
+				t.Errorf("SizeTier() = %q, want %q", got, tt.tier)
+			}
+			if sizes, _ := parsed.InstanceSizes(); sizes.Compute != tt.compute {
+				t.Errorf("InstanceSizes().Compute = %q, want %q", sizes.Compute, tt.compute)
+			}
+		})
+	}
+}
+
+func TestParsedInputJSON(t *testing.T) {
+	engine := NewEngine()
+
+	parsed, err := engine.Parse("Create an encrypted S3 bucket named MyLogs in us-west-2")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	data, err := json.Marshal(parsed)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	for _, key := range []string{`"original_text":`, `"cloud_provider":"aws"`, `"resources":[{"type":"storage"`, `"kind":"encryption_at_rest"`, `"span":{"start":`, `"regions":[{"name":"us-west-2"`} {
+		if !strings.Contains(string(data), key) {
+			t.Errorf("JSON %s does not contain %s", data, key)
+		}
+	}
+
+	var decoded ParsedInput
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if decoded.CloudProvider != parsed.CloudProvider || len(decoded.Requirements) != len(parsed.Requirements) || decoded.Resources[0].Span != parsed.Resources[0].Span {
+		t.Errorf("round trip = %+v, want %+v", decoded, parsed)
+	}
+}
@@ -0,0 +1,250 @@
+package nlp
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Relation kinds recognized by the parser
+const (
+	RelationBehind       = "behind"
+	RelationConnectsTo   = "connects_to"
+	RelationInSubnet     = "in_subnet"
+	RelationReplicatesTo = "replicates_to"
+)
+
+// Relation represents a directed edge between two identified resources
+type Relation struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// DependencyGraph represents the topology described in the input
+type DependencyGraph struct {
+	Nodes []string   `json:"nodes,omitempty"`
+	Edges []Relation `json:"edges,omitempty"`
+}
+
+// mention is an occurrence of a resource keyword in the input
+type mention struct {
+	start        int
+	end          int
+	resourceType string
+}
+
+// relationPatterns maps connecting phrases to relation kinds
+var relationPatterns = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{RelationBehind, regexp.MustCompile(`\b(?:behind|fronted by)\b`)},
+	{RelationInSubnet, regexp.MustCompile(`\b(?:in|inside|within|into)\s+(?:an?\s+|the\s+)?(?:private\s+|public\s+|isolated\s+)?subnets?\b`)},
+	{RelationReplicatesTo, regexp.MustCompile(`\b(?:replicat(?:es|ing|ed)?|mirror(?:s|ing|ed)?)\s+(?:data\s+)?(?:to|into)\b`)},
+	{RelationConnectsTo, regexp.MustCompile(`\b(?:talk(?:s|ing)?|connect(?:s|ing|ed)?|writ(?:es|ing)|read(?:s|ing)?|send(?:s|ing)?)\s+(?:to|with|from)\b|\b(?:access(?:es|ing)?|us(?:es|ing)|queries|querying)\b`)},
+}
+
+// relationWindow is the maximum distance in bytes between a phrase and its resources
+const relationWindow = 60
+
+// extractRelations identifies relationships between the resources mentioned in the input
+func (e *Engine) extractRelations(input string, resources []Resource) []Relation {
+	names := make(map[string]string)
+	for _, resource := range resources {
+		names[resource.Type] = resource.Name
+	}
+
+	mentions := e.findMentions(input)
+	if len(mentions) < 2 {
+		return nil
+	}
+
+	var relations []Relation
+	seen := make(map[Relation]bool)
+	// Resources that are the target of a modifier clause ("X behind Y") point
+	// back to the clause subject so "X behind Y talking to Z" links X to Z.
+	subjects := make(map[string]string)
+
+	type phrase struct {
+		start int
+		end   int
+		kind  string
+	}
+
+	var phrases []phrase
+	for _, pattern := range relationPatterns {
+		for _, loc := range pattern.re.FindAllStringIndex(input, -1) {
+			phrases = append(phrases, phrase{start: loc[0], end: loc[1], kind: pattern.kind})
+		}
+	}
+	sort.Slice(phrases, func(i, j int) bool { return phrases[i].start < phrases[j].start })
+
+	for _, p := range phrases {
+		from := nearestMentionBefore(mentions, p.start)
+		if from == nil {
+			continue
+		}
+
+		var to *mention
+		if p.kind == RelationInSubnet {
+			to = &mention{resourceType: "network"}
+		} else {
+			to = nearestMentionAfter(mentions, p.end)
+		}
+		if to == nil {
+			continue
+		}
+
+		fromName, toName := names[from.resourceType], names[to.resourceType]
+		if p.kind == RelationConnectsTo || p.kind == RelationReplicatesTo {
+			if subject, ok := subjects[fromName]; ok {
+				fromName = subject
+			}
+		}
+
+		if fromName == "" || toName == "" || fromName == toName {
+			continue
+		}
+
+		if p.kind == RelationBehind || p.kind == RelationInSubnet {
+			subjects[toName] = fromName
+		}
+
+		relation := Relation{From: fromName, To: toName, Kind: p.kind}
+		if !seen[relation] {
+			seen[relation] = true
+			relations = append(relations, relation)
+		}
+	}
+
+	return relations
+}
+
+// findMentions locates every resource keyword occurrence, preferring the longest match
+func (e *Engine) findMentions(input string) []mention {
+	var mentions []mention
+
+	for _, group := range e.resourceTypes {
+		resourceType := group.Name
+		for _, keyword := range group.Keywords {
+			offset := 0
+			for {
+				i := strings.Index(input[offset:], keyword)
+				if i < 0 {
+					break
+				}
+				start := offset + i
+				end := start + len(keyword)
+				offset = end
+
+				if start > 0 && !isWordSeparator(rune(input[start-1])) {
+					continue
+				}
+				mentions = append(mentions, mention{start: start, end: end, resourceType: resourceType})
+			}
+		}
+	}
+
+	sort.Slice(mentions, func(i, j int) bool {
+		if mentions[i].start != mentions[j].start {
+			return mentions[i].start < mentions[j].start
+		}
+		return mentions[i].end > mentions[j].end
+	})
+
+	// Drop mentions nested inside a longer one ("sql" within "mysql")
+	var result []mention
+	for _, m := range mentions {
+		if len(result) > 0 && m.start < result[len(result)-1].end {
+			continue
+		}
+		result = append(result, m)
+	}
+
+	return result
+}
+
+// nearestMentionBefore returns the closest mention ending before pos
+func nearestMentionBefore(mentions []mention, pos int) *mention {
+	for i := len(mentions) - 1; i >= 0; i-- {
+		if mentions[i].end <= pos {
+			if pos-mentions[i].end > relationWindow {
+				return nil
+			}
+			return &mentions[i]
+		}
+	}
+	return nil
+}
+
+// nearestMentionAfter returns the closest mention starting after pos
+func nearestMentionAfter(mentions []mention, pos int) *mention {
+	for i := range mentions {
+		if mentions[i].start >= pos {
+			if mentions[i].start-pos > relationWindow {
+				return nil
+			}
+			return &mentions[i]
+		}
+	}
+	return nil
+}
+
+// Graph builds the dependency graph of the parsed resources and relations
+func (p *ParsedInput) Graph() *DependencyGraph {
+	graph := &DependencyGraph{}
+
+	for _, resource := range p.Resources {
+		graph.Nodes = append(graph.Nodes, resource.Name)
+	}
+	graph.Edges = append(graph.Edges, p.Relations...)
+
+	return graph
+}
+
+// Text renders the graph as one edge per line
+func (g *DependencyGraph) Text() string {
+	var b strings.Builder
+
+	for _, edge := range g.Edges {
+		b.WriteString(fmt.Sprintf("%s --%s--> %s\n", edge.From, edge.Kind, edge.To))
+	}
+
+	// Resources that take part in no relation are listed on their own
+	for _, node := range g.Nodes {
+		if !g.hasEdge(node) {
+			b.WriteString(node + "\n")
+		}
+	}
+
+	return b.String()
+}
+
+// DOT renders the graph in Graphviz DOT format
+func (g *DependencyGraph) DOT() string {
+	var b strings.Builder
+
+	b.WriteString("digraph infrastructure {\n")
+	b.WriteString("  rankdir=LR;\n")
+	for _, node := range g.Nodes {
+		b.WriteString(fmt.Sprintf("  %q;\n", node))
+	}
+	for _, edge := range g.Edges {
+		b.WriteString(fmt.Sprintf("  %q -> %q [label=%q];\n", edge.From, edge.To, edge.Kind))
+	}
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// hasEdge reports whether node is an endpoint of any edge
+func (g *DependencyGraph) hasEdge(node string) bool {
+	for _, edge := range g.Edges {
+		if edge.From == node || edge.To == node {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,218 @@
+package nlp
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Constraint kinds a requirement can express
+const (
+	KindEncryptionAtRest    = "encryption_at_rest"
+	KindEncryptionInTransit = "encryption_in_transit"
+	KindSecurityHardening   = "security_hardening"
+	KindPrivateAccess       = "private_access"
+	KindPublicAccess        = "public_access"
+	KindAccessControl       = "access_control"
+	KindAutoscaling         = "autoscaling"
+	KindHighAvailability    = "high_availability"
+	KindMultiAZ             = "multi_az"
+	KindMultiRegion         = "multi_region"
+	KindLoadBalancing       = "load_balancing"
+	KindPerformance         = "performance"
+	KindCaching             = "caching"
+	KindCDN                 = "cdn"
+	KindMinInstances        = "min_instances"
+	KindCPUCount            = "cpu_count"
+	KindMemorySize          = "memory_size"
+	KindStorageSize         = "storage_size"
+	KindPortCount           = "port_count"
+	KindSpecification       = "specification"
+)
+
+// requirementKinds maps requirement keywords to the constraint they express.
+// Keywords added by vocabulary packs that are not listed here get a kind
+// derived from the keyword itself.
+var requirementKinds = map[string]string{
+	"secure":            KindSecurityHardening,
+	"security":          KindSecurityHardening,
+	"encrypted":         KindEncryptionAtRest,
+	"encryption":        KindEncryptionAtRest,
+	"ssl":               KindEncryptionInTransit,
+	"tls":               KindEncryptionInTransit,
+	"https":             KindEncryptionInTransit,
+	"private":           KindPrivateAccess,
+	"public":            KindPublicAccess,
+	"firewall":          KindAccessControl,
+	"access control":    KindAccessControl,
+	"scalable":          KindAutoscaling,
+	"auto scaling":      KindAutoscaling,
+	"autoscaling":       KindAutoscaling,
+	"high availability": KindHighAvailability,
+	"highly available":  KindHighAvailability,
+	"redundant":         KindHighAvailability,
+	"multi-az":          KindMultiAZ,
+	"multi-region":      KindMultiRegion,
+	"load balanced":     KindLoadBalancing,
+	"fast":              KindPerformance,
+	"performance":       KindPerformance,
+	"optimized":         KindPerformance,
+	"cached":            KindCaching,
+	"cdn":               KindCDN,
+}
+
+// kindTargets lists, in order of preference, the resource types each kind can
+// apply to. Kinds that are not listed apply to the deployment as a whole.
+var kindTargets = map[string][]string{
+	KindEncryptionAtRest:    {"database", "storage", "compute"},
+	KindEncryptionInTransit: {"network", "compute", "container", "serverless", "database"},
+	KindPrivateAccess:       {"network", "database", "storage", "compute"},
+	KindPublicAccess:        {"network", "storage", "compute"},
+	KindAccessControl:       {"network", "compute"},
+	KindAutoscaling:         {"compute", "container"},
+	KindHighAvailability:    {"database", "compute", "container"},
+	KindMultiAZ:             {"database", "compute", "network"},
+	KindMultiRegion:         {"storage", "database"},
+	KindLoadBalancing:       {"compute", "container"},
+	KindCDN:                 {"storage"},
+	KindMinInstances:        {"compute", "container"},
+	KindCPUCount:            {"compute", "container", "database"},
+	KindMemorySize:          {"compute", "container", "database"},
+	KindStorageSize:         {"storage", "database", "compute"},
+	KindPortCount:           {"network"},
+	KindBackupRetention:     {"database", "storage"},
+}
+
+var numberPattern = regexp.MustCompile(`^\d+(?:\.\d+)?$`)
+
+// Constraint renders the typed form of the requirement, e.g.
+// "encryption_at_rest on database" or "min_instances=3 on compute"
+func (r Requirement) Constraint() string {
+	constraint := r.Kind
+	if r.Value != "" {
+		constraint += "=" + r.Value
+		if contains([]string{"gb", "tb", "mb"}, r.Unit) {
+			constraint += r.Unit
+		} else if r.Unit != "" {
+			constraint += " " + r.Unit
+		}
+	}
+	if r.Target != "" {
+		constraint += " on " + r.Target
+	}
+	return constraint
+}
+
+// keywordKind returns the constraint kind for a requirement keyword
+func keywordKind(keyword string) string {
+	if kind, ok := requirementKinds[keyword]; ok {
+		return kind
+	}
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(keyword)
+}
+
+// specificationKind classifies the capture groups of a specification match
+// into a kind, a numeric value and a unit. Resource nouns such as "server"
+// are not units; memory and storage sizes keep theirs.
+func specificationKind(groups []string) (kind, value, unit string) {
+	kind = KindSpecification
+	var noun string
+
+	for _, group := range groups {
+		group = strings.TrimSpace(group)
+		switch {
+		case numberPattern.MatchString(group):
+			if value == "" {
+				value = group
+			}
+		case contains([]string{"gb", "tb", "mb"}, group):
+			unit = group
+		case contains([]string{"cpu", "cpus", "core", "cores", "vcpu", "vcpus"}, group):
+			kind = KindCPUCount
+		case contains([]string{"instance", "instances", "server", "servers", "vm", "vms", "node", "nodes"}, group):
+			kind = KindMinInstances
+		case contains([]string{"port", "ports"}, group):
+			kind = KindPortCount
+		case group != "":
+			noun = group
+		}
+	}
+
+	if unit != "" {
+		kind = KindStorageSize
+		if noun == "memory" || noun == "ram" {
+			kind = KindMemorySize
+		}
+	}
+
+	return kind, value, unit
+}
+
+// resolveTargets sets the target of each requirement to a mention of a
+// resource type its kind can apply to. Modifiers usually precede their noun
+// ("an encrypted database"), so the closest mention after the requirement
+// wins if it is within relationWindow; otherwise the closest one overall.
+func resolveTargets(requirements []Requirement, mentions []mention) {
+	for i := range requirements {
+		requirement := &requirements[i]
+		candidates := kindTargets[requirement.Kind]
+		if requirement.Target != "" || len(candidates) == 0 {
+			continue
+		}
+
+		best, bestDistance := "", -1
+		for _, m := range mentions {
+			if !contains(candidates, m.resourceType) {
+				continue
+			}
+			distance := spanDistance(requirement.Span, Span{Start: m.start, End: m.end})
+			if m.start >= requirement.Span.Start && distance <= relationWindow {
+				best = m.resourceType
+				break
+			}
+			if bestDistance < 0 || distance < bestDistance {
+				best, bestDistance = m.resourceType, distance
+			}
+		}
+
+		requirement.Target = best
+	}
+}
+
+// spanDistance returns the number of bytes between two spans, 0 if they overlap
+func spanDistance(a, b Span) int {
+	switch {
+	case a.End <= b.Start:
+		return b.Start - a.End
+	case b.End <= a.Start:
+		return a.Start - b.End
+	default:
+		return 0
+	}
+}
+
+// RequirementsFor returns the requirements that target the given resource type
+func (p *ParsedInput) RequirementsFor(resourceType string) []Requirement {
+	var requirements []Requirement
+	for _, requirement := range p.Requirements {
+		if requirement.Target == resourceType {
+			requirements = append(requirements, requirement)
+		}
+	}
+	return requirements
+}
+
+// validateRequirement checks a requirement produced outside the keyword
+// engine, such as a language model extraction
+func validateRequirement(requirement Requirement) error {
+	if requirement.Kind == "" {
+		return fmt.Errorf("requirement %q has no kind", requirement.Text)
+	}
+	if requirement.Target != "" && !contains(knownResourceTypes, requirement.Target) {
+		return fmt.Errorf("requirement %q targets unknown resource type %q", requirement.Text, requirement.Target)
+	}
+	if requirement.Value != "" && !numberPattern.MatchString(requirement.Value) {
+		return fmt.Errorf("requirement %q has non-numeric value %q", requirement.Text, requirement.Value)
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package nlp
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Workload size tiers, smallest first
+const (
+	TierSmall  = "small"
+	TierMedium = "medium"
+	TierLarge  = "large"
+	TierXLarge = "xlarge"
+)
+
+// sizeTiers lists the tiers in increasing order
+var sizeTiers = []string{TierSmall, TierMedium, TierLarge, TierXLarge}
+
+// Budget is a monthly spending ceiling found in the input, e.g. "under
+// $200/month". Amounts stated per year, day or hour are converted to a
+// monthly amount.
+type Budget struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+	Span     Span    `json:"span"`
+}
+
+// Workload is a size hint found in the input: a tier named by words such as
+// "small" or "production-grade", or derived from an expected number of daily
+// users ("for about 10k daily users")
+type Workload struct {
+	Tier       string `json:"tier"`
+	DailyUsers int    `json:"daily_users,omitempty"`
+	Span       Span   `json:"span"`
+}
+
+// InstanceSizes are the instance types suggested for a size tier
+type InstanceSizes struct {
+	Compute  string `json:"compute"`
+	Database string `json:"database"`
+}
+
+var (
+	// budgetPattern matches an amount of money with a qualifier ("under",
+	// "budget of") or a period ("/month", "per year", "monthly")
+	budgetPattern = regexp.MustCompile(`(?:\b(under|below|less than|max(?:imum)?(?: of)?|at most|up to|no more than|within|budget(?: of| is)?|around|about|approximately|roughly)\s*)?` +
+		`(?:([$€£])\s*(\d[\d,]*(?:\.\d+)?)\s*(k)?\b|\b(\d[\d,]*(?:\.\d+)?)\s*(k)?\s*(usd|eur|gbp|dollars?|euros?|pounds?)\b)` +
+		`(?:\s*(?:/|per|a|an|each|every)\s*(month|mo|year|yr|day|hour|hr)\b|\s+(monthly|yearly|annually|daily|hourly)\b)?`)
+	// usersPattern matches an expected audience such as "10k daily users"
+	usersPattern = regexp.MustCompile(`\b(\d[\d,]*(?:\.\d+)?)\s*(k|m|thousand|million)?\s+((?:(?:daily|monthly|active|concurrent)\s+)*)(?:users|visitors|customers)\b`)
+)
+
+// currencySymbols maps currency symbols and words to ISO 4217 codes
+var currencySymbols = map[string]string{
+	"$": "USD", "usd": "USD", "dollar": "USD", "dollars": "USD",
+	"€": "EUR", "eur": "EUR", "euro": "EUR", "euros": "EUR",
+	"£": "GBP", "gbp": "GBP", "pound": "GBP", "pounds": "GBP",
+}
+
+// monthlyFactors converts an amount per period to an amount per month
+var monthlyFactors = map[string]float64{
+	"": 1, "month": 1, "mo": 1, "monthly": 1,
+	"year": 1.0 / 12, "yr": 1.0 / 12, "yearly": 1.0 / 12, "annually": 1.0 / 12,
+	"day": 30, "daily": 30,
+	"hour": 730, "hr": 730, "hourly": 730,
+}
+
+// instanceSizes are the instance types suggested per provider and tier
+var instanceSizes = map[string]map[string]InstanceSizes{
+	"aws": {
+		TierSmall:  {Compute: "t3.micro", Database: "db.t3.micro"},
+		TierMedium: {Compute: "t3.medium", Database: "db.t3.medium"},
+		TierLarge:  {Compute: "m5.large", Database: "db.m5.large"},
+		TierXLarge: {Compute: "m5.2xlarge", Database: "db.m5.2xlarge"},
+	},
+	"azure": {
+		TierSmall:  {Compute: "Standard_B1s", Database: "B_Standard_B1ms"},
+		TierMedium: {Compute: "Standard_B2s", Database: "GP_Standard_D2s_v3"},
+		TierLarge:  {Compute: "Standard_D2s_v3", Database: "GP_Standard_D4s_v3"},
+		TierXLarge: {Compute: "Standard_D8s_v3", Database: "GP_Standard_D8s_v3"},
+	},
+	"gcp": {
+		TierSmall:  {Compute: "e2-micro", Database: "db-f1-micro"},
+		TierMedium: {Compute: "e2-medium", Database: "db-custom-2-7680"},
+		TierLarge:  {Compute: "n2-standard-2", Database: "db-custom-4-15360"},
+		TierXLarge: {Compute: "n2-standard-8", Database: "db-custom-8-30720"},
+	},
+}
+
+// String renders the budget as e.g. "$200/month"
+func (b Budget) String() string {
+	amount := strconv.FormatFloat(b.Amount, 'f', -1, 64)
+	if b.Amount != float64(int(b.Amount)) {
+		amount = fmt.Sprintf("%.2f", b.Amount)
+	}
+	switch b.Currency {
+	case "USD":
+		return "$" + amount + "/month"
+	case "EUR":
+		return "€" + amount + "/month"
+	case "GBP":
+		return "£" + amount + "/month"
+	default:
+		return amount + " " + b.Currency + "/month"
+	}
+}
+
+// extractBudget finds the first spending limit in the input
+func extractBudget(input string) *Budget {
+	for _, idx := range budgetPattern.FindAllStringSubmatchIndex(input, -1) {
+		match := submatches(input, idx)
+		qualifier, period := match[1], match[8]+match[9]
+		if qualifier == "" && period == "" {
+			continue
+		}
+
+		symbol, number, thousands := match[2], match[3], match[4]
+		if symbol == "" {
+			symbol, number, thousands = match[7], match[5], match[6]
+		}
+		amount, err := strconv.ParseFloat(strings.ReplaceAll(number, ",", ""), 64)
+		if err != nil || amount <= 0 {
+			continue
+		}
+		if thousands != "" {
+			amount *= 1000
+		}
+
+		return &Budget{
+			Amount:   amount * monthlyFactors[period],
+			Currency: currencySymbols[symbol],
+			Span:     Span{Start: idx[0], End: idx[1]},
+		}
+	}
+	return nil
+}
+
+// extractWorkload finds a size hint in the input. An audience size is more
+// specific than a size word and wins over it.
+func (e *Engine) extractWorkload(input string) *Workload {
+	if idx := usersPattern.FindStringSubmatchIndex(input); idx != nil {
+		match := submatches(input, idx)
+		if users, err := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", ""), 64); err == nil && users > 0 {
+			switch match[2] {
+			case "k", "thousand":
+				users *= 1000
+			case "m", "million":
+				users *= 1000000
+			}
+			if strings.Contains(match[3], "monthly") {
+				users /= 30
+			}
+			return &Workload{
+				Tier:       tierForUsers(int(users)),
+				DailyUsers: int(users),
+				Span:       Span{Start: idx[0], End: idx[1]},
+			}
+		}
+	}
+
+	var workload *Workload
+	for _, group := range e.workloadSizes {
+		for _, keyword := range group.Keywords {
+			if i := indexWord(input, keyword); i >= 0 && (workload == nil || i < workload.Span.Start) {
+				workload = &Workload{Tier: group.Name, Span: Span{Start: i, End: i + len(keyword)}}
+			}
+		}
+	}
+	return workload
+}
+
+// indexWord returns the index of the first occurrence of keyword as whole
+// words, not counting instance type suffixes such as "t3.small"
+func indexWord(input, keyword string) int {
+	for offset := 0; offset < len(input); {
+		i := strings.Index(input[offset:], keyword)
+		if i < 0 {
+			return -1
+		}
+		start, end := offset+i, offset+i+len(keyword)
+		previous, _ := utf8.DecodeLastRuneInString(input[:start])
+		next, _ := utf8.DecodeRuneInString(input[end:])
+		if (start == 0 || !isWordRune(previous) && previous != '.') && (end == len(input) || !isWordRune(next)) {
+			return start
+		}
+		offset = start + 1
+	}
+	return -1
+}
+
+// tierForUsers maps an expected number of daily users to a size tier
+func tierForUsers(users int) string {
+	switch {
+	case users < 1000:
+		return TierSmall
+	case users < 50000:
+		return TierMedium
+	case users < 500000:
+		return TierLarge
+	default:
+		return TierXLarge
+	}
+}
+
+// tierForBudget returns the largest tier a monthly budget usually affords
+func tierForBudget(amount float64) string {
+	switch {
+	case amount < 100:
+		return TierSmall
+	case amount < 500:
+		return TierMedium
+	case amount < 2500:
+		return TierLarge
+	default:
+		return TierXLarge
+	}
+}
+
+// tierIndex returns the position of a tier in sizeTiers, or -1
+func tierIndex(tier string) int {
+	for i, t := range sizeTiers {
+		if t == tier {
+			return i
+		}
+	}
+	return -1
+}
+
+// SizeTier returns the tier to size instances for: the workload's tier,
+// capped by what the budget affords. It is empty when the input gives
+// neither.
+func (p *ParsedInput) SizeTier() string {
+	tier := ""
+	if p.Workload != nil {
+		tier = p.Workload.Tier
+	}
+	if p.Budget != nil {
+		affordable := tierForBudget(p.Budget.Amount)
+		if tier == "" || tierIndex(affordable) < tierIndex(tier) {
+			tier = affordable
+		}
+	}
+	return tier
+}
+
+// InstanceSizes returns the instance types suggested for the input's
+// provider and size tier
+func (p *ParsedInput) InstanceSizes() (InstanceSizes, bool) {
+	sizes, ok := instanceSizes[p.CloudProvider][p.SizeTier()]
+	return sizes, ok
+}
@@ -0,0 +1,119 @@
+package nlp
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Span is a half-open byte range [Start, End) into ParsedInput.OriginalText
+type Span struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// NoSpan marks values that did not come from the input text, such as
+// clarification answers or language model extractions
+var NoSpan = Span{Start: -1, End: -1}
+
+// Name represents an identifier the user provided, e.g. a bucket called "MyAppProd"
+type Name struct {
+	Value string `json:"value"`
+	Span  Span   `json:"span"`
+}
+
+// Tag represents a key/value tag the user asked for, e.g. "tagged Team=Payments"
+type Tag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Span  Span   `json:"span"`
+}
+
+var (
+	namedPattern  = regexp.MustCompile(`(?i)\b(?:named|called)\s+["'` + "`" + `]?([A-Za-z0-9][\w.-]*[A-Za-z0-9_])["'` + "`" + `]?`)
+	quotedPattern = regexp.MustCompile(`["` + "`" + `]([A-Za-z0-9][\w.-]*[A-Za-z0-9_])["` + "`" + `]`)
+	tagPattern    = regexp.MustCompile(`(?i)\btag(?:ged|s)?\s+(?:with\s+)?([A-Za-z][\w-]*)\s*[=:]\s*["']?([\w.@/-]+)["']?`)
+)
+
+// Text returns the original text covered by span
+func (p *ParsedInput) Text(span Span) string {
+	if span.Start < 0 || span.End > len(p.OriginalText) || span.Start > span.End {
+		return ""
+	}
+	return p.OriginalText[span.Start:span.End]
+}
+
+// foldCase lowercases s without changing its byte length, so offsets found
+// in the result are valid in s. Runes whose lowercase form has a different
+// encoded length, and bytes that are not valid UTF-8, are left unchanged.
+func foldCase(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for i, r := range s {
+		if _, size := utf8.DecodeRuneInString(s[i:]); r == utf8.RuneError && size == 1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		lower := unicode.ToLower(r)
+		if utf8.RuneLen(lower) != utf8.RuneLen(r) {
+			lower = r
+		}
+		b.WriteRune(lower)
+	}
+
+	return b.String()
+}
+
+// extractNames finds identifiers introduced by "named"/"called" or written in quotes
+func extractNames(original string) []Name {
+	var names []Name
+	seen := make(map[string]bool)
+
+	for _, re := range []*regexp.Regexp{namedPattern, quotedPattern} {
+		for _, idx := range re.FindAllStringSubmatchIndex(original, -1) {
+			value := original[idx[2]:idx[3]]
+			if !seen[value] {
+				seen[value] = true
+				names = append(names, Name{Value: value, Span: Span{Start: idx[2], End: idx[3]}})
+			}
+		}
+	}
+
+	return names
+}
+
+// extractTags finds "tag key=value" style tags
+func extractTags(original string) []Tag {
+	var tags []Tag
+
+	for _, idx := range tagPattern.FindAllStringSubmatchIndex(original, -1) {
+		tags = append(tags, Tag{
+			Key:   original[idx[2]:idx[3]],
+			Value: original[idx[4]:idx[5]],
+			Span:  Span{Start: idx[0], End: idx[1]},
+		})
+	}
+
+	return tags
+}
+
+// assignNames attaches each user-provided name to the closest resource mentioned before it
+func assignNames(parsed *ParsedInput) {
+	for _, name := range parsed.Entities.Names {
+		var closest *Resource
+		for i := range parsed.Resources {
+			resource := &parsed.Resources[i]
+			if resource.Span.End <= name.Span.Start && (closest == nil || resource.Span.End > closest.Span.End) {
+				closest = resource
+			}
+		}
+
+		if closest != nil {
+			if _, ok := closest.Properties["name"]; !ok {
+				closest.Properties["name"] = name.Value
+			}
+		}
+	}
+}
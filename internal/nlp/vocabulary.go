@@ -0,0 +1,319 @@
+package nlp
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// VocabularyVersion is the vocabulary file format version this build understands
+const VocabularyVersion = 1
+
+//go:embed vocabulary/default.yaml
+var defaultVocabulary []byte
+
+//go:embed vocabulary/languages/*.yaml
+var languageVocabularies embed.FS
+
+// Vocabulary holds the keywords and patterns the keyword engine matches against
+type Vocabulary struct {
+	Version        int            `yaml:"version"`
+	CloudProviders []KeywordGroup `yaml:"cloud_providers"`
+	ResourceTypes  []KeywordGroup `yaml:"resource_types"`
+	Requirements   []KeywordGroup `yaml:"requirements"`
+	Specifications []string       `yaml:"specifications"`
+	Intents        []KeywordGroup `yaml:"intents"`
+	WorkloadSizes  []KeywordGroup `yaml:"workload_sizes"`
+	Languages      []LanguagePack `yaml:"languages"`
+}
+
+// KeywordGroup maps a name (provider, resource type, category, intent, size) to its keywords
+type KeywordGroup struct {
+	Name     string   `yaml:"name"`
+	Keywords []string `yaml:"keywords"`
+}
+
+// LanguagePack normalizes descriptions written in another language to the
+// English vocabulary. Stopwords are used to detect the language; each
+// translation maps a lowercase phrase to the English words that replace it.
+type LanguagePack struct {
+	Code         string            `yaml:"code"`
+	Name         string            `yaml:"name"`
+	Stopwords    []string          `yaml:"stopwords"`
+	Translations map[string]string `yaml:"translations"`
+}
+
+// validIntents are the intents the rest of the pipeline understands
+var validIntents = []string{"create", "modify", "delete"}
+
+// DefaultVocabulary returns the built-in vocabulary, including the built-in
+// language packs
+func DefaultVocabulary() *Vocabulary {
+	vocabulary, err := ParseVocabulary(defaultVocabulary)
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in vocabulary: %v", err))
+	}
+
+	entries, err := languageVocabularies.ReadDir("vocabulary/languages")
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in language packs: %v", err))
+	}
+	for _, entry := range entries {
+		data, err := languageVocabularies.ReadFile(path.Join("vocabulary/languages", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("invalid built-in language pack %s: %v", entry.Name(), err))
+		}
+		pack, err := ParseVocabulary(data)
+		if err != nil {
+			panic(fmt.Sprintf("invalid built-in language pack %s: %v", entry.Name(), err))
+		}
+		vocabulary.Merge(pack)
+	}
+
+	return vocabulary
+}
+
+// ParseVocabulary decodes and validates a vocabulary document
+func ParseVocabulary(data []byte) (*Vocabulary, error) {
+	var vocabulary Vocabulary
+
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&vocabulary); err != nil {
+		return nil, fmt.Errorf("failed to decode vocabulary: %w", err)
+	}
+
+	if err := vocabulary.validateGroups(); err != nil {
+		return nil, err
+	}
+
+	return &vocabulary, nil
+}
+
+// LoadVocabulary returns the built-in vocabulary merged with the given files in order
+func LoadVocabulary(paths ...string) (*Vocabulary, error) {
+	vocabulary := DefaultVocabulary()
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read vocabulary %s: %w", path, err)
+		}
+
+		pack, err := ParseVocabulary(data)
+		if err != nil {
+			return nil, fmt.Errorf("vocabulary %s: %w", path, err)
+		}
+
+		vocabulary.Merge(pack)
+	}
+
+	if err := vocabulary.Validate(); err != nil {
+		return nil, err
+	}
+
+	return vocabulary, nil
+}
+
+// Merge appends the groups and patterns of other. Keywords of groups that
+// already exist are appended to them; new groups are added at the end.
+func (v *Vocabulary) Merge(other *Vocabulary) {
+	v.CloudProviders = mergeGroups(v.CloudProviders, other.CloudProviders)
+	v.ResourceTypes = mergeGroups(v.ResourceTypes, other.ResourceTypes)
+	v.Requirements = mergeGroups(v.Requirements, other.Requirements)
+	v.Intents = mergeGroups(v.Intents, other.Intents)
+	v.WorkloadSizes = mergeGroups(v.WorkloadSizes, other.WorkloadSizes)
+
+	for _, pattern := range other.Specifications {
+		v.Specifications = appendUnique(v.Specifications, pattern)
+	}
+
+	v.Languages = mergeLanguages(v.Languages, other.Languages)
+}
+
+// Validate checks that the vocabulary is complete enough to drive the engine
+func (v *Vocabulary) Validate() error {
+	if err := v.validateGroups(); err != nil {
+		return err
+	}
+
+	if len(v.CloudProviders) == 0 {
+		return fmt.Errorf("vocabulary has no cloud providers")
+	}
+	if len(v.ResourceTypes) == 0 {
+		return fmt.Errorf("vocabulary has no resource types")
+	}
+	if len(v.Intents) == 0 {
+		return fmt.Errorf("vocabulary has no intents")
+	}
+
+	return nil
+}
+
+// validateGroups checks the parts of the vocabulary that every file, including
+// partial synonym packs, must get right
+func (v *Vocabulary) validateGroups() error {
+	if v.Version != VocabularyVersion {
+		return fmt.Errorf("unsupported vocabulary version %d (expected %d)", v.Version, VocabularyVersion)
+	}
+
+	sections := []struct {
+		name   string
+		groups []KeywordGroup
+	}{
+		{"cloud_providers", v.CloudProviders},
+		{"resource_types", v.ResourceTypes},
+		{"requirements", v.Requirements},
+		{"intents", v.Intents},
+		{"workload_sizes", v.WorkloadSizes},
+	}
+
+	for _, section := range sections {
+		seen := make(map[string]bool)
+		for i, group := range section.groups {
+			if group.Name == "" {
+				return fmt.Errorf("%s[%d]: name is required", section.name, i)
+			}
+			if seen[group.Name] {
+				return fmt.Errorf("%s[%d]: duplicate name %q", section.name, i, group.Name)
+			}
+			seen[group.Name] = true
+
+			if len(group.Keywords) == 0 {
+				return fmt.Errorf("%s.%s: at least one keyword is required", section.name, group.Name)
+			}
+			for _, keyword := range group.Keywords {
+				if strings.TrimSpace(keyword) == "" {
+					return fmt.Errorf("%s.%s: empty keyword", section.name, group.Name)
+				}
+				if keyword != strings.ToLower(keyword) {
+					return fmt.Errorf("%s.%s: keyword %q must be lowercase", section.name, group.Name, keyword)
+				}
+			}
+		}
+	}
+
+	for _, intent := range v.Intents {
+		if !contains(validIntents, intent.Name) {
+			return fmt.Errorf("intents.%s: unknown intent (use %s)", intent.Name, strings.Join(validIntents, ", "))
+		}
+	}
+
+	for _, size := range v.WorkloadSizes {
+		if !contains(sizeTiers, size.Name) {
+			return fmt.Errorf("workload_sizes.%s: unknown size (use %s)", size.Name, strings.Join(sizeTiers, ", "))
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, language := range v.Languages {
+		if language.Code == "" {
+			return fmt.Errorf("languages[%d]: code is required", i)
+		}
+		if language.Code != strings.ToLower(language.Code) || language.Code == "en" {
+			return fmt.Errorf("languages[%d]: invalid code %q", i, language.Code)
+		}
+		if seen[language.Code] {
+			return fmt.Errorf("languages[%d]: duplicate code %q", i, language.Code)
+		}
+		seen[language.Code] = true
+
+		for phrase, english := range language.Translations {
+			if strings.TrimSpace(phrase) == "" || strings.TrimSpace(english) == "" {
+				return fmt.Errorf("languages.%s: empty translation %q: %q", language.Code, phrase, english)
+			}
+			if phrase != foldCase(phrase) {
+				return fmt.Errorf("languages.%s: phrase %q must be lowercase", language.Code, phrase)
+			}
+		}
+	}
+
+	for i, pattern := range v.Specifications {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("specifications[%d]: %w", i, err)
+		}
+		if re.NumSubexp() == 0 {
+			return fmt.Errorf("specifications[%d]: pattern must have at least one capture group", i)
+		}
+	}
+
+	return nil
+}
+
+// mergeGroups merges keyword groups by name, preserving order
+func mergeGroups(groups, others []KeywordGroup) []KeywordGroup {
+	merged := make([]KeywordGroup, len(groups))
+	for i, group := range groups {
+		merged[i] = KeywordGroup{Name: group.Name, Keywords: append([]string{}, group.Keywords...)}
+	}
+
+	for _, other := range others {
+		found := false
+		for i := range merged {
+			if merged[i].Name == other.Name {
+				for _, keyword := range other.Keywords {
+					merged[i].Keywords = appendUnique(merged[i].Keywords, keyword)
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, KeywordGroup{Name: other.Name, Keywords: append([]string{}, other.Keywords...)})
+		}
+	}
+
+	return merged
+}
+
+// mergeLanguages merges language packs by code; translations of other win
+func mergeLanguages(languages, others []LanguagePack) []LanguagePack {
+	merged := make([]LanguagePack, 0, len(languages)+len(others))
+	for _, language := range languages {
+		merged = append(merged, copyLanguage(language))
+	}
+
+	for _, other := range others {
+		found := false
+		for i := range merged {
+			if merged[i].Code == other.Code {
+				if other.Name != "" {
+					merged[i].Name = other.Name
+				}
+				for _, stopword := range other.Stopwords {
+					merged[i].Stopwords = appendUnique(merged[i].Stopwords, stopword)
+				}
+				for phrase, english := range other.Translations {
+					merged[i].Translations[phrase] = english
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, copyLanguage(other))
+		}
+	}
+
+	return merged
+}
+
+// copyLanguage returns a deep copy of a language pack
+func copyLanguage(language LanguagePack) LanguagePack {
+	translations := make(map[string]string, len(language.Translations))
+	for phrase, english := range language.Translations {
+		translations[phrase] = english
+	}
+	return LanguagePack{
+		Code:         language.Code,
+		Name:         language.Name,
+		Stopwords:    append([]string{}, language.Stopwords...),
+		Translations: translations,
+	}
+}
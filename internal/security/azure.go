@@ -0,0 +1,130 @@
+package security
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// azureOpenSources are network security rule source prefixes that match any
+// address, compared case-insensitively
+var azureOpenSources = []string{"*", "0.0.0.0/0", "::/0", "internet", "any"}
+
+// azureHTTPSOnly are the storage account settings that require HTTPS: the
+// current name and the one used before azurerm 4.0
+var azureHTTPSOnly = []string{"https_traffic_only_enabled", "enable_https_traffic_only"}
+
+// azureRules returns the rule pack for azurerm resources
+func azureRules() []SecurityRule {
+	return []SecurityRule{
+		{
+			ID:            "AZU001",
+			Name:          "Storage Account Allows HTTP",
+			Severity:      "HIGH",
+			Provider:      ProviderAzure,
+			ResourceTypes: []string{"azurerm_storage_account"},
+			Message:       "Storage account accepts unencrypted HTTP traffic",
+			Remediation:   "Set https_traffic_only_enabled to true",
+			Controls: map[string][]string{
+				ControlsNIST80053: {"SC-8"},
+				ControlsPCIDSS:    {"4.2.1"},
+				ControlsHIPAA:     {"164.312(e)(1)"},
+			},
+			check: checkStorageHTTPOnly,
+			fix:   fixStorageHTTPSOnly,
+		},
+		{
+			ID:            "AZU002",
+			Name:          "Open Network Security Group",
+			Severity:      "CRITICAL",
+			Provider:      ProviderAzure,
+			ResourceTypes: []string{"azurerm_network_security_group", "azurerm_network_security_rule"},
+			Message:       "Network security group allows inbound traffic from any source",
+			Remediation:   "Restrict source_address_prefix to specific address ranges or service tags",
+			Controls: map[string][]string{
+				ControlsNIST80053: {"SC-7"},
+				ControlsPCIDSS:    {"1.3.1"},
+			},
+			check: checkOpenSecurityRule,
+		},
+		{
+			ID:            "AZU003",
+			Name:          "Key Vault Without Purge Protection",
+			Severity:      "MEDIUM",
+			Provider:      ProviderAzure,
+			ResourceTypes: []string{"azurerm_key_vault"},
+			Message:       "Key Vault does not have purge protection enabled",
+			Remediation:   "Set purge_protection_enabled to true so deleted vaults and keys cannot be purged",
+			Controls: map[string][]string{
+				ControlsNIST80053: {"CP-9"},
+			},
+			check: checkSettingEnabled("purge_protection_enabled"),
+			fix:   fixAttribute("purge_protection_enabled", cty.True),
+		},
+	}
+}
+
+// checkStorageHTTPOnly flags storage accounts that turn off HTTPS-only
+// traffic. It is on by default, so only an explicit false is reported.
+func checkStorageHTTPOnly(c *parsedConfig, b *block) []hcl.Range {
+	var ranges []hcl.Range
+	for _, name := range azureHTTPSOnly {
+		ranges = append(ranges, checkAttributeEquals(name, "false")(c, b)...)
+	}
+	return ranges
+}
+
+// fixStorageHTTPSOnly turns HTTPS-only traffic back on with the setting
+// the account uses
+func fixStorageHTTPSOnly(r *remediation, b *block, w *hclwrite.Block) []string {
+	var fixes []string
+	for _, name := range azureHTTPSOnly {
+		if value, ok := attributeValue(b.Body, name); ok && value == "false" {
+			fixes = append(fixes, fixAttribute(name, cty.True)(r, b, w)...)
+		}
+	}
+	return fixes
+}
+
+// checkOpenSecurityRule flags inbound allow rules open to any source, in a
+// network security group or as a standalone rule
+func checkOpenSecurityRule(c *parsedConfig, b *block) []hcl.Range {
+	if b.Type == "azurerm_network_security_rule" {
+		return openSourceAttributes(b.Body)
+	}
+
+	var ranges []hcl.Range
+	for _, rule := range nestedBlocks(b.Body, "security_rule") {
+		ranges = append(ranges, openSourceAttributes(rule.Body)...)
+	}
+	return ranges
+}
+
+// openSourceAttributes returns the ranges of the source prefixes of an
+// inbound allow rule that match any address
+func openSourceAttributes(body *hclsyntax.Body) []hcl.Range {
+	direction, _ := attributeValue(body, "direction")
+	access, _ := attributeValue(body, "access")
+	if !strings.EqualFold(direction, "Inbound") || !strings.EqualFold(access, "Allow") {
+		return nil
+	}
+
+	var ranges []hcl.Range
+	for _, name := range []string{"source_address_prefix", "source_address_prefixes"} {
+		attr, ok := body.Attributes[name]
+		if !ok {
+			continue
+		}
+		values, _ := literalStrings(attr.Expr)
+		for _, value := range values {
+			if contains(azureOpenSources, strings.ToLower(value)) {
+				ranges = append(ranges, attr.SrcRange)
+				break
+			}
+		}
+	}
+	return ranges
+}
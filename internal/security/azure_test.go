@@ -0,0 +1,184 @@
+package security
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAzureRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   string
+		config string
+		want   []string
+	}{
+		{
+			name: "storage account with HTTP allowed",
+			rule: "AZU001",
+			config: `resource "azurerm_storage_account" "data" {
+  account_tier               = "Standard"
+  https_traffic_only_enabled = false
+}
+`,
+			want: []string{"azurerm_storage_account.data 3:3"},
+		},
+		{
+			name: "storage account with HTTP allowed by the pre-4.0 setting",
+			rule: "AZU001",
+			config: `resource "azurerm_storage_account" "data" {
+  enable_https_traffic_only = false
+}
+`,
+			want: []string{"azurerm_storage_account.data 2:3"},
+		},
+		{
+			name: "storage account with the HTTPS-only default",
+			rule: "AZU001",
+			config: `resource "azurerm_storage_account" "data" {
+  account_tier = "Standard"
+}
+`,
+		},
+		{
+			name: "security group open to any source",
+			rule: "AZU002",
+			config: `resource "azurerm_network_security_group" "web" {
+  security_rule {
+    direction             = "Outbound"
+    access                = "Allow"
+    source_address_prefix = "*"
+  }
+
+  security_rule {
+    direction             = "Inbound"
+    access                = "Allow"
+    source_address_prefix = "*"
+  }
+}
+`,
+			want: []string{"azurerm_network_security_group.web 11:5"},
+		},
+		{
+			name: "standalone rule open to the internet",
+			rule: "AZU002",
+			config: `resource "azurerm_network_security_rule" "ssh" {
+  direction               = "inbound"
+  access                  = "allow"
+  source_address_prefixes = ["10.0.0.0/8", "Internet"]
+}
+`,
+			want: []string{"azurerm_network_security_rule.ssh 4:3"},
+		},
+		{
+			name: "inbound rules that deny or name a range",
+			rule: "AZU002",
+			config: `resource "azurerm_network_security_group" "web" {
+  security_rule {
+    direction             = "Inbound"
+    access                = "Deny"
+    source_address_prefix = "*"
+  }
+
+  security_rule {
+    direction             = "Inbound"
+    access                = "Allow"
+    source_address_prefix = "10.0.0.0/16"
+  }
+}
+`,
+		},
+		{
+			name: "key vault without purge protection",
+			rule: "AZU003",
+			config: `resource "azurerm_key_vault" "main" {
+  sku_name = "standard"
+}
+`,
+			want: []string{"azurerm_key_vault.main 1:1"},
+		},
+		{
+			name: "key vault with purge protection",
+			rule: "AZU003",
+			config: `resource "azurerm_key_vault" "main" {
+  purge_protection_enabled = true
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reported(scan(t, NewScanner(), tt.config).Issues, tt.rule)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("%s issues = %v, want %v", tt.rule, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemediateStorageHTTPSOnly(t *testing.T) {
+	result := remediate(t, `resource "azurerm_storage_account" "data" {
+  enable_https_traffic_only = false
+}
+`)
+
+	if got := fixDescriptions(result.Fixes, "AZU001"); strings.Join(got, ",") != "azurerm_storage_account.data: set enable_https_traffic_only = true" {
+		t.Errorf("AZU001 fixes = %v, want the setting the account uses", got)
+	}
+	if strings.Contains(result.Configuration, "https_traffic_only_enabled") {
+		t.Errorf("configuration gained the other setting:\n%s", result.Configuration)
+	}
+}
+
+func TestProviderRulesSkipOtherClouds(t *testing.T) {
+	scanner := NewScanner()
+	scanner.AddAdvancedSecurityRules()
+
+	// The aws provider is in use, but only its own resources are checked
+	config := `provider "aws" {
+  region = "us-east-1"
+}
+
+resource "aws_db_instance" "main" {
+  storage_encrypted       = true
+  publicly_accessible     = true
+  backup_retention_period = 0
+}
+
+resource "azurerm_mssql_server" "main" {
+  publicly_accessible     = true
+  backup_retention_period = 0
+  policy                  = "{\"Statement\": [{\"Effect\": \"Allow\", \"Action\": \"*\", \"Resource\": \"*\"}]}"
+}
+
+resource "google_sql_database_instance" "main" {
+  publicly_accessible     = true
+  backup_retention_period = 0
+  policy                  = "{\"Statement\": [{\"Effect\": \"Allow\", \"Action\": \"*\", \"Resource\": \"*\"}]}"
+}
+
+resource "azurerm_storage_account" "data" {
+  https_traffic_only_enabled = false
+}
+`
+	issues := scan(t, scanner, config).Issues
+
+	providers := make(map[string]string)
+	for _, rule := range scanner.GetRules() {
+		providers[rule.ID] = rule.Provider
+	}
+	for _, issue := range issues {
+		if providers[issue.Rule] == ProviderAWS && !strings.HasPrefix(issue.Resource, "aws_") {
+			t.Errorf("AWS rule %s reported on %s", issue.Rule, issue.Resource)
+		}
+		if providers[issue.Rule] == ProviderAzure && !strings.HasPrefix(issue.Resource, "azurerm_") {
+			t.Errorf("Azure rule %s reported on %s", issue.Rule, issue.Resource)
+		}
+	}
+
+	for _, rule := range []string{"SEC005", "SEC010", "AZU001"} {
+		if len(reported(issues, rule)) != 1 {
+			t.Errorf("%s issues = %v, want one on its own provider's resource", rule, reported(issues, rule))
+		}
+	}
+}
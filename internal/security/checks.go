@@ -0,0 +1,282 @@
+package security
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// openCIDRs are the CIDR blocks that match any address
+var openCIDRs = []string{"0.0.0.0/0", "::/0"}
+
+// storageEncryption maps storage resource types to the setting that enables
+// encryption at rest
+var storageEncryption = map[string]string{
+	"aws_efs_file_system":               "encrypted",
+	"aws_redshift_cluster":              "encrypted",
+	"aws_elasticache_replication_group": "at_rest_encryption_enabled",
+}
+
+// secretAttributeSuffixes are attribute names that hold credentials
+var secretAttributeSuffixes = []string{"password", "passwd", "secret", "secret_key", "secret_string", "access_key", "private_key", "api_key", "apikey", "token"}
+
+// wildcardResourcePattern matches a policy statement granting access to all
+// resources, in JSON ("Resource": "*") or jsonencode (Resource = "*") form
+var wildcardResourcePattern = regexp.MustCompile(`"?Resource"?\s*[:=]\s*"\*"`)
+
+// storageTypes returns the resource types checked by SEC002
+func storageTypes() []string {
+	types := make([]string, 0, len(storageEncryption))
+	for resourceType := range storageEncryption {
+		types = append(types, resourceType)
+	}
+	return types
+}
+
+// checkAttributeEquals flags the attribute if its literal value is value
+func checkAttributeEquals(name, value string) func(c *parsedConfig, b *block) []hcl.Range {
+	return func(c *parsedConfig, b *block) []hcl.Range {
+		if v, ok := attributeValue(b.Body, name); ok && v == value {
+			return []hcl.Range{b.Body.Attributes[name].SrcRange}
+		}
+		return nil
+	}
+}
+
+// checkAttributeMissing flags blocks that do not set the attribute
+func checkAttributeMissing(name string) func(c *parsedConfig, b *block) []hcl.Range {
+	return func(c *parsedConfig, b *block) []hcl.Range {
+		if _, ok := b.Body.Attributes[name]; !ok {
+			return []hcl.Range{b.Range}
+		}
+		return nil
+	}
+}
+
+// checkSettingEnabled flags blocks where a boolean setting is missing or false
+func checkSettingEnabled(name string) func(c *parsedConfig, b *block) []hcl.Range {
+	return func(c *parsedConfig, b *block) []hcl.Range {
+		if notEnabled(b.Body, name) {
+			return []hcl.Range{attributeRange(b.Body, name, b.Range)}
+		}
+		return nil
+	}
+}
+
+// checkPublicACL flags canned ACLs that grant public read access
+func checkPublicACL(c *parsedConfig, b *block) []hcl.Range {
+	if acl, ok := attributeValue(b.Body, "acl"); ok && (acl == "public-read" || acl == "public-read-write") {
+		return []hcl.Range{b.Body.Attributes["acl"].SrcRange}
+	}
+	return nil
+}
+
+// checkStorageEncryption flags storage resources without encryption at rest
+func checkStorageEncryption(c *parsedConfig, b *block) []hcl.Range {
+	return checkSettingEnabled(storageEncryption[b.Type])(c, b)
+}
+
+// checkOpenIngress flags ingress rules open to any address. Egress to
+// anywhere is the normal default and is not reported.
+func checkOpenIngress(c *parsedConfig, b *block) []hcl.Range {
+	switch b.Type {
+	case "aws_security_group":
+		var ranges []hcl.Range
+		for _, ingress := range nestedBlocks(b.Body, "ingress") {
+			ranges = append(ranges, openCIDRAttributes(ingress.Body, "cidr_blocks", "ipv6_cidr_blocks")...)
+		}
+		return ranges
+
+	case "aws_security_group_rule":
+		if ruleType, _ := attributeValue(b.Body, "type"); ruleType != "ingress" {
+			return nil
+		}
+		return openCIDRAttributes(b.Body, "cidr_blocks", "ipv6_cidr_blocks")
+
+	default:
+		return openCIDRAttributes(b.Body, "cidr_ipv4", "cidr_ipv6")
+	}
+}
+
+// openCIDRAttributes returns the ranges of the named attributes that
+// include an open CIDR block
+func openCIDRAttributes(body *hclsyntax.Body, names ...string) []hcl.Range {
+	var ranges []hcl.Range
+	for _, name := range names {
+		attr, ok := body.Attributes[name]
+		if !ok {
+			continue
+		}
+		values, _ := literalStrings(attr.Expr)
+		for _, value := range values {
+			if contains(openCIDRs, value) {
+				ranges = append(ranges, attr.SrcRange)
+				break
+			}
+		}
+	}
+	return ranges
+}
+
+// checkVolumeEncryption flags EBS volumes, and the block devices declared on
+// instances, that are not encrypted
+func checkVolumeEncryption(c *parsedConfig, b *block) []hcl.Range {
+	if b.Type == "aws_ebs_volume" {
+		return checkSettingEnabled("encrypted")(c, b)
+	}
+
+	var ranges []hcl.Range
+	for _, device := range b.Body.Blocks {
+		if device.Type != "root_block_device" && device.Type != "ebs_block_device" {
+			continue
+		}
+		if notEnabled(device.Body, "encrypted") {
+			ranges = append(ranges, attributeRange(device.Body, "encrypted", device.DefRange()))
+		}
+	}
+	return ranges
+}
+
+// checkShortPasswords flags literal string passwords shorter than 8
+// characters; booleans such as manage_master_user_password = true are settings
+func checkShortPasswords(c *parsedConfig, b *block) []hcl.Range {
+	var ranges []hcl.Range
+	walkAttributes(b.Body, func(attr *hclsyntax.Attribute) {
+		if !strings.HasSuffix(attr.Name, "password") {
+			return
+		}
+		value, ok := literalValue(attr.Expr)
+		if !ok || value.Type() != cty.String {
+			return
+		}
+		if length := len(strings.TrimSpace(value.AsString())); length > 0 && length < 8 {
+			ranges = append(ranges, attr.SrcRange)
+		}
+	})
+	return ranges
+}
+
+// checkPlainHTTPListener flags HTTP listeners that do not redirect to HTTPS
+func checkPlainHTTPListener(c *parsedConfig, b *block) []hcl.Range {
+	if protocol, ok := attributeValue(b.Body, "protocol"); !ok || protocol != "HTTP" || redirects(b.Body) {
+		return nil
+	}
+	return []hcl.Range{b.Body.Attributes["protocol"].SrcRange}
+}
+
+// redirects reports whether a listener's default action is a redirect
+func redirects(body *hclsyntax.Body) bool {
+	for _, action := range nestedBlocks(body, "default_action") {
+		if actionType, _ := attributeValue(action.Body, "type"); actionType == "redirect" || len(nestedBlocks(action.Body, "redirect")) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDefaultVPC flags use of the account's default VPC
+func checkDefaultVPC(c *parsedConfig, b *block) []hcl.Range {
+	switch {
+	case b.Kind == "resource" && b.Type == "aws_default_vpc":
+		return []hcl.Range{b.Range}
+	case b.Kind == "data" && b.Type == "aws_vpc":
+		return checkAttributeEquals("default", "true")(c, b)
+	default:
+		return nil
+	}
+}
+
+// checkBucketEncryption flags buckets without server-side encryption
+func checkBucketEncryption(c *parsedConfig, b *block) []hcl.Range {
+	if !bucketEncrypted(c, b) {
+		return []hcl.Range{b.Range}
+	}
+	return nil
+}
+
+// bucketEncrypted reports whether a bucket has server-side encryption, set
+// inline or by an aws_s3_bucket_server_side_encryption_configuration
+func bucketEncrypted(c *parsedConfig, bucket *block) bool {
+	return len(nestedBlocks(bucket.Body, "server_side_encryption_configuration")) > 0 ||
+		len(c.referencedBy(bucket, "bucket", "aws_s3_bucket_server_side_encryption_configuration")) > 0
+}
+
+// checkBucketVersioning flags buckets without versioning, set inline or by
+// an aws_s3_bucket_versioning resource
+func checkBucketVersioning(c *parsedConfig, b *block) []hcl.Range {
+	for _, versioning := range nestedBlocks(b.Body, "versioning") {
+		if !notEnabled(versioning.Body, "enabled") {
+			return nil
+		}
+	}
+	for _, versioning := range c.referencedBy(b, "bucket", "aws_s3_bucket_versioning") {
+		for _, configuration := range nestedBlocks(versioning.Body, "versioning_configuration") {
+			if status, ok := attributeValue(configuration.Body, "status"); !ok || status == "Enabled" {
+				return nil
+			}
+		}
+	}
+	return []hcl.Range{b.Range}
+}
+
+// checkBucketMFADelete flags buckets without MFA delete
+func checkBucketMFADelete(c *parsedConfig, b *block) []hcl.Range {
+	for _, versioning := range nestedBlocks(b.Body, "versioning") {
+		if !notEnabled(versioning.Body, "mfa_delete") {
+			return nil
+		}
+	}
+	for _, versioning := range c.referencedBy(b, "bucket", "aws_s3_bucket_versioning") {
+		for _, configuration := range nestedBlocks(versioning.Body, "versioning_configuration") {
+			if _, ok := configuration.Body.Attributes["mfa_delete"]; !ok {
+				continue
+			}
+			if status, ok := attributeValue(configuration.Body, "mfa_delete"); !ok || status == "Enabled" {
+				return nil
+			}
+		}
+	}
+	return []hcl.Range{b.Range}
+}
+
+// checkInstanceSecurityGroups flags instances without security groups
+func checkInstanceSecurityGroups(c *parsedConfig, b *block) []hcl.Range {
+	for _, name := range []string{"security_groups", "vpc_security_group_ids"} {
+		if _, ok := b.Body.Attributes[name]; ok {
+			return nil
+		}
+	}
+	// Groups attached to a network interface apply to the instance
+	if len(nestedBlocks(b.Body, "network_interface")) > 0 {
+		return nil
+	}
+	return []hcl.Range{b.Range}
+}
+
+// checkWildcardResources flags policy documents that apply to every
+// resource, whether written as JSON, jsonencode or an
+// aws_iam_policy_document statement
+func checkWildcardResources(c *parsedConfig, b *block) []hcl.Range {
+	var ranges []hcl.Range
+
+	if b.Kind == "data" && b.Type == "aws_iam_policy_document" {
+		for _, statement := range nestedBlocks(b.Body, "statement") {
+			if attr, ok := statement.Body.Attributes["resources"]; ok {
+				if values, _ := literalStrings(attr.Expr); contains(values, "*") {
+					ranges = append(ranges, attr.SrcRange)
+				}
+			}
+		}
+		return ranges
+	}
+
+	walkAttributes(b.Body, func(attr *hclsyntax.Attribute) {
+		if wildcardResourcePattern.MatchString(c.sourceText(attr.Expr.Range())) {
+			ranges = append(ranges, attr.SrcRange)
+		}
+	})
+	return ranges
+}
@@ -0,0 +1,263 @@
+package security
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// Statuses of a control in a compliance report
+const (
+	ControlPass          = "pass"
+	ControlFail          = "fail"
+	ControlNotApplicable = "not_applicable"
+)
+
+// ComplianceReport is the status of each control of a control framework for
+// one configuration
+type ComplianceReport struct {
+	Framework string            `json:"framework"`
+	Name      string            `json:"name"`
+	File      string            `json:"file,omitempty"`
+	Summary   ComplianceSummary `json:"summary"`
+	Controls  []ControlResult   `json:"controls"`
+}
+
+// ComplianceSummary counts the controls per status
+type ComplianceSummary struct {
+	Passed        int `json:"passed"`
+	Failed        int `json:"failed"`
+	NotApplicable int `json:"not_applicable"`
+}
+
+// ControlResult is the status of one control. A control fails when one of
+// its rules reports an issue, passes when its rules inspected at least one
+// block without issues, and is not applicable when none of its rules
+// applies to the configuration (e.g. RDS controls without databases).
+type ControlResult struct {
+	ID         string           `json:"id"`
+	Title      string           `json:"title"`
+	Status     string           `json:"status"`
+	Rules      []string         `json:"rules"`
+	Findings   []ControlFinding `json:"findings,omitempty"`
+	Suppressed []ControlFinding `json:"suppressed,omitempty"`
+}
+
+// ControlFinding is an issue reported against a control; Reason is set for
+// suppressed issues
+type ControlFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Resource string `json:"resource,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// ComplianceReport scans the configuration in the file at path and reports
+// pass, fail or not applicable for every control of the framework, from the
+// rules mapped to each control. Suppressed issues are listed but do not fail
+// a control.
+func (s *Scanner) ComplianceReport(config, path, framework string) (*ComplianceReport, error) {
+	catalog, err := controlFramework(framework)
+	if err != nil {
+		return nil, err
+	}
+
+	scanned, err := s.Scan(config)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	providers := parsed.providers()
+
+	report := &ComplianceReport{Framework: catalog.ID, Name: catalog.Name, File: path}
+	for _, control := range catalog.Controls {
+		result := ControlResult{ID: control.ID, Title: control.Title, Status: ControlNotApplicable, Rules: []string{}}
+
+		var rules []SecurityRule
+		for _, rule := range s.rules {
+			if !contains(rule.Controls[catalog.ID], control.ID) {
+				continue
+			}
+			rules = append(rules, rule)
+			if !contains(result.Rules, rule.ID) {
+				result.Rules = append(result.Rules, rule.ID)
+			}
+			if rule.inspects(parsed, providers) {
+				result.Status = ControlPass
+			}
+		}
+
+		for _, issue := range scanned.Issues {
+			if reportedBy(rules, issue) {
+				result.Findings = append(result.Findings, controlFinding(issue, ""))
+				result.Status = ControlFail
+			}
+		}
+		for _, issue := range scanned.Suppressed {
+			if reportedBy(rules, issue.Issue) {
+				result.Suppressed = append(result.Suppressed, controlFinding(issue.Issue, issue.Reason))
+			}
+		}
+
+		switch result.Status {
+		case ControlPass:
+			report.Summary.Passed++
+		case ControlFail:
+			report.Summary.Failed++
+		default:
+			report.Summary.NotApplicable++
+		}
+		report.Controls = append(report.Controls, result)
+	}
+
+	return report, nil
+}
+
+// inspects reports whether the rule runs against any block of the
+// configuration
+func (r SecurityRule) inspects(c *parsedConfig, providers map[string]bool) bool {
+	if r.Provider != "" && !providers[r.Provider] {
+		return false
+	}
+	for _, b := range c.blocks {
+		if r.appliesTo(b) {
+			return true
+		}
+	}
+	return false
+}
+
+// reportedBy reports whether one of the rules produced the issue. Rule IDs
+// are not unique (SEC015), so the message is compared too.
+func reportedBy(rules []SecurityRule, issue Issue) bool {
+	for _, rule := range rules {
+		if rule.ID == issue.Rule && rule.Message == issue.Message {
+			return true
+		}
+	}
+	return false
+}
+
+// controlFinding converts an issue for the report
+func controlFinding(issue Issue, reason string) ControlFinding {
+	return ControlFinding{
+		Rule:     issue.Rule,
+		Severity: issue.Severity,
+		Message:  issue.Message,
+		Resource: issue.Resource,
+		Line:     issue.Line,
+		Reason:   reason,
+	}
+}
+
+// JSON encodes the report
+func (r *ComplianceReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// Markdown renders the report as a summary and a table of controls
+func (r *ComplianceReport) Markdown() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", r.Name)
+	if r.File != "" {
+		fmt.Fprintf(&b, "Configuration: `%s`\n\n", r.File)
+	}
+	fmt.Fprintf(&b, "**%d passed, %d failed, %d not applicable**\n\n", r.Summary.Passed, r.Summary.Failed, r.Summary.NotApplicable)
+
+	b.WriteString("| Control | Title | Status | Rules | Findings |\n")
+	b.WriteString("|---------|-------|--------|-------|----------|\n")
+	for _, control := range r.Controls {
+		var findings []string
+		for _, finding := range control.Findings {
+			findings = append(findings, markdownCell(finding.String()))
+		}
+		for _, finding := range control.Suppressed {
+			findings = append(findings, markdownCell(finding.String()))
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n",
+			control.ID, markdownCell(control.Title), statusLabel(control.Status), strings.Join(control.Rules, ", "), strings.Join(findings, "<br>"))
+	}
+
+	return b.String()
+}
+
+// HTML renders the report as a standalone HTML page
+func (r *ComplianceReport) HTML() (string, error) {
+	var buf bytes.Buffer
+	if err := complianceHTML.Execute(&buf, r); err != nil {
+		return "", fmt.Errorf("failed to render report: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// String renders a finding as "RULE: message (resource, line N)", with the
+// reason of a suppressed finding
+func (f ControlFinding) String() string {
+	text := fmt.Sprintf("%s: %s", f.Rule, f.Message)
+	if location := (Issue{Resource: f.Resource, Line: f.Line}).Location(); location != "" {
+		text += " (" + location + ")"
+	}
+	if f.Reason != "" {
+		text += " [suppressed: " + f.Reason + "]"
+	}
+	return text
+}
+
+// statusLabel renders a control status for reports
+func statusLabel(status string) string {
+	switch status {
+	case ControlPass:
+		return "PASS"
+	case ControlFail:
+		return "FAIL"
+	default:
+		return "N/A"
+	}
+}
+
+// markdownCell escapes text for a Markdown table cell
+func markdownCell(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
+
+var complianceHTML = template.Must(template.New("compliance").Funcs(template.FuncMap{"status": statusLabel}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Name}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 0.4em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f3f3f3; }
+.pass { color: #1a7f37; font-weight: bold; }
+.fail { color: #cf222e; font-weight: bold; }
+.not_applicable { color: #6e7781; }
+.suppressed { color: #6e7781; }
+</style>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+{{if .File}}<p>Configuration: <code>{{.File}}</code></p>{{end}}
+<p><strong>{{.Summary.Passed}} passed, {{.Summary.Failed}} failed, {{.Summary.NotApplicable}} not applicable</strong></p>
+<table>
+<tr><th>Control</th><th>Title</th><th>Status</th><th>Rules</th><th>Findings</th></tr>
+{{range .Controls}}<tr>
+<td>{{.ID}}</td>
+<td>{{.Title}}</td>
+<td class="{{.Status}}">{{status .Status}}</td>
+<td>{{range $i, $rule := .Rules}}{{if $i}}, {{end}}{{$rule}}{{end}}</td>
+<td>{{range .Findings}}<div>{{.String}}</div>{{end}}{{range .Suppressed}}<div class="suppressed">{{.String}}</div>{{end}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
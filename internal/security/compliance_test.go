@@ -0,0 +1,179 @@
+package security
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComplianceReportStatuses(t *testing.T) {
+	config := `# tfnlp:ignore SEC005 reason="reporting replica"
+resource "aws_db_instance" "main" {
+  storage_encrypted   = true
+  publicly_accessible = true
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+}
+`
+	report, err := NewScanner().ComplianceReport(config, "main.tf", "CIS-AWS")
+	if err != nil {
+		t.Fatalf("ComplianceReport() error = %v", err)
+	}
+	if report.Framework != ControlsCISAWS || report.File != "main.tf" {
+		t.Errorf("report = %s for %s, want cis-aws for main.tf", report.Framework, report.File)
+	}
+
+	tests := []struct {
+		control        string
+		wantStatus     string
+		wantRules      string
+		wantFindings   []string
+		wantSuppressed []string
+	}{
+		{"2.1.1", ControlFail, "SEC011", []string{"SEC011 aws_s3_bucket.logs"}, nil},
+		{"2.1.3", ControlFail, "SEC013", []string{"SEC013 aws_s3_bucket.logs"}, nil},
+		{"2.1.5", ControlFail, "SEC001,SEC017", []string{"SEC017 aws_s3_bucket.logs"}, nil},
+		{"2.2.1", ControlNotApplicable, "SEC004", nil, nil},
+		{"2.3.1", ControlPass, "SEC015", nil, nil},
+		{"2.3.3", ControlPass, "SEC005", nil, []string{"SEC005 aws_db_instance.main reporting replica"}},
+		{"2.4.1", ControlNotApplicable, "SEC002", nil, nil},
+		{"5.2", ControlNotApplicable, "SEC019", nil, nil},
+	}
+	if len(report.Controls) != len(tests) {
+		t.Fatalf("report has %d controls, want %d", len(report.Controls), len(tests))
+	}
+
+	for i, tt := range tests {
+		control := report.Controls[i]
+		if control.ID != tt.control {
+			t.Errorf("controls[%d] = %s, want %s", i, control.ID, tt.control)
+			continue
+		}
+		if control.Status != tt.wantStatus {
+			t.Errorf("%s status = %s, want %s", control.ID, control.Status, tt.wantStatus)
+		}
+		if got := strings.Join(control.Rules, ","); got != tt.wantRules {
+			t.Errorf("%s rules = %s, want %s", control.ID, got, tt.wantRules)
+		}
+
+		var findings, suppressed []string
+		for _, f := range control.Findings {
+			findings = append(findings, f.Rule+" "+f.Resource)
+		}
+		for _, f := range control.Suppressed {
+			suppressed = append(suppressed, f.Rule+" "+f.Resource+" "+f.Reason)
+		}
+		if strings.Join(findings, ",") != strings.Join(tt.wantFindings, ",") {
+			t.Errorf("%s findings = %v, want %v", control.ID, findings, tt.wantFindings)
+		}
+		if strings.Join(suppressed, ",") != strings.Join(tt.wantSuppressed, ",") {
+			t.Errorf("%s suppressed = %v, want %v", control.ID, suppressed, tt.wantSuppressed)
+		}
+	}
+
+	if report.Summary != (ComplianceSummary{Passed: 2, Failed: 3, NotApplicable: 3}) {
+		t.Errorf("summary = %+v, want 2 passed, 3 failed, 3 not applicable", report.Summary)
+	}
+	if markdown := report.Markdown(); !strings.Contains(markdown, "**2 passed, 3 failed, 3 not applicable**") ||
+		!strings.Contains(markdown, "| 2.4.1 | Ensure that encryption is enabled for EFS file systems | N/A | SEC002 |  |") {
+		t.Errorf("Markdown() =\n%s", markdown)
+	}
+}
+
+func TestComplianceReportOtherProvider(t *testing.T) {
+	config := `resource "azurerm_storage_account" "data" {
+  https_traffic_only_enabled = false
+}
+`
+	report, err := NewScanner().ComplianceReport(config, "", ControlsNIST80053)
+	if err != nil {
+		t.Fatalf("ComplianceReport() error = %v", err)
+	}
+
+	statuses := make(map[string]string)
+	for _, control := range report.Controls {
+		statuses[control.ID] = control.Status
+	}
+	tests := []struct {
+		control string
+		want    string
+	}{
+		// AZU001 reports the storage account
+		{"SC-8", ControlFail},
+		// SEC007 has no provider and inspects every block
+		{"IA-5(7)", ControlPass},
+		// The AWS rules without resource types do not apply without AWS
+		{"CP-9", ControlNotApplicable},
+		{"AC-17", ControlNotApplicable},
+	}
+	for _, tt := range tests {
+		if statuses[tt.control] != tt.want {
+			t.Errorf("%s status = %s, want %s", tt.control, statuses[tt.control], tt.want)
+		}
+	}
+}
+
+func TestComplianceReportUnknownFramework(t *testing.T) {
+	_, err := NewScanner().ComplianceReport(`resource "aws_s3_bucket" "logs" {}`, "main.tf", "iso-27001")
+	if err == nil || !strings.Contains(err.Error(), "unknown control framework: iso-27001 (use cis-aws, nist-800-53, pci-dss, hipaa)") {
+		t.Errorf("ComplianceReport() error = %v, want an unknown framework error", err)
+	}
+}
+
+func TestRuleControls(t *testing.T) {
+	scanner := NewScanner()
+	scanner.AddAdvancedSecurityRules()
+	if err := scanner.LoadRules("../../examples/rules/tagging.yaml"); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	covered := make(map[string]bool)
+	for _, rule := range scanner.GetRules() {
+		if err := validateControls(rule.Controls); err != nil {
+			t.Errorf("rule %s: %v", rule.ID, err)
+		}
+		for framework, controls := range rule.Controls {
+			for _, control := range controls {
+				covered[framework+"/"+control] = true
+			}
+		}
+	}
+
+	// A control without rules could only ever be reported as not applicable
+	for _, framework := range controlFrameworks {
+		for _, control := range framework.Controls {
+			if !covered[framework.ID+"/"+control.ID] {
+				t.Errorf("%s control %s is not mapped to any rule", framework.ID, control.ID)
+			}
+		}
+	}
+}
+
+func TestValidateControls(t *testing.T) {
+	tests := []struct {
+		name     string
+		controls map[string][]string
+		wantErr  string
+	}{
+		{"known controls", map[string][]string{ControlsCISAWS: {"2.1.1"}, ControlsHIPAA: {"164.312(d)"}}, ""},
+		{"no controls", nil, ""},
+		{"unknown framework", map[string][]string{"iso-27001": {"A.8.24"}}, "unknown control framework: iso-27001"},
+		{"unknown control", map[string][]string{ControlsPCIDSS: {"1.3.1", "12.1"}}, `unknown pci-dss control "12.1"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateControls(tt.controls)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateControls() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateControls() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
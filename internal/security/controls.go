@@ -0,0 +1,139 @@
+package security
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Control frameworks that rules are mapped to
+const (
+	ControlsCISAWS    = "cis-aws"
+	ControlsNIST80053 = "nist-800-53"
+	ControlsPCIDSS    = "pci-dss"
+	ControlsHIPAA     = "hipaa"
+)
+
+// ControlFramework is a catalog of controls, such as a benchmark or a
+// regulation, that rules provide evidence for
+type ControlFramework struct {
+	ID       string
+	Name     string
+	Controls []Control
+}
+
+// Control is one requirement of a control framework
+type Control struct {
+	ID    string
+	Title string
+}
+
+// controlFrameworks lists the controls rules are mapped to, in report order
+var controlFrameworks = []ControlFramework{
+	{
+		ID:   ControlsCISAWS,
+		Name: "CIS Amazon Web Services Foundations Benchmark v1.5.0",
+		Controls: []Control{
+			{"2.1.1", "Ensure all S3 buckets employ encryption-at-rest"},
+			{"2.1.3", "Ensure MFA Delete is enabled on S3 buckets"},
+			{"2.1.5", "Ensure that S3 Buckets are configured with 'Block public access (bucket settings)'"},
+			{"2.2.1", "Ensure EBS Volume Encryption is Enabled in all Regions"},
+			{"2.3.1", "Ensure that encryption is enabled for RDS Instances"},
+			{"2.3.3", "Ensure that public access is not given to RDS Instance"},
+			{"2.4.1", "Ensure that encryption is enabled for EFS file systems"},
+			{"5.2", "Ensure no security groups allow ingress from 0.0.0.0/0 to remote server administration ports"},
+		},
+	},
+	{
+		ID:   ControlsNIST80053,
+		Name: "NIST SP 800-53 Rev. 5",
+		Controls: []Control{
+			{"AC-3", "Access Enforcement"},
+			{"AC-6", "Least Privilege"},
+			{"AC-17", "Remote Access"},
+			{"CP-9", "System Backup"},
+			{"IA-5", "Authenticator Management"},
+			{"IA-5(7)", "No Embedded Unencrypted Static Authenticators"},
+			{"SC-7", "Boundary Protection"},
+			{"SC-8", "Transmission Confidentiality and Integrity"},
+			{"SC-28", "Protection of Information at Rest"},
+		},
+	},
+	{
+		ID:   ControlsPCIDSS,
+		Name: "PCI DSS v4.0",
+		Controls: []Control{
+			{"1.3.1", "Inbound traffic to the CDE is restricted"},
+			{"1.4.4", "System components that store cardholder data are not directly accessible from untrusted networks"},
+			{"3.5.1", "PAN is rendered unreadable anywhere it is stored"},
+			{"4.2.1", "Strong cryptography protects PAN during transmission over open, public networks"},
+			{"7.2.2", "Access is assigned based on job classification and least privileges"},
+			{"8.3.6", "Passwords meet minimum length and complexity"},
+			{"8.6.2", "Passwords for application and system accounts are not hard coded"},
+		},
+	},
+	{
+		ID:   ControlsHIPAA,
+		Name: "HIPAA Security Rule",
+		Controls: []Control{
+			{"164.308(a)(4)", "Information Access Management"},
+			{"164.308(a)(5)(ii)(D)", "Password Management"},
+			{"164.308(a)(7)(ii)(A)", "Data Backup Plan"},
+			{"164.312(a)(1)", "Access Control"},
+			{"164.312(a)(2)(iv)", "Encryption and Decryption"},
+			{"164.312(d)", "Person or Entity Authentication"},
+			{"164.312(e)(1)", "Transmission Security"},
+		},
+	},
+}
+
+// ControlFrameworks returns the identifiers of the control frameworks
+func ControlFrameworks() []string {
+	ids := make([]string, 0, len(controlFrameworks))
+	for _, framework := range controlFrameworks {
+		ids = append(ids, framework.ID)
+	}
+	return ids
+}
+
+// controlFramework returns the control framework with the given identifier
+func controlFramework(id string) (ControlFramework, error) {
+	for _, framework := range controlFrameworks {
+		if framework.ID == strings.ToLower(id) {
+			return framework, nil
+		}
+	}
+	return ControlFramework{}, fmt.Errorf("unknown control framework: %s (use %s)", id, strings.Join(ControlFrameworks(), ", "))
+}
+
+// validateControls checks that a rule's controls exist in their frameworks
+func validateControls(controls map[string][]string) error {
+	frameworks := make([]string, 0, len(controls))
+	for id := range controls {
+		frameworks = append(frameworks, id)
+	}
+	sort.Strings(frameworks)
+
+	for _, id := range frameworks {
+		framework, err := controlFramework(id)
+		if err != nil {
+			return err
+		}
+		for _, controlID := range controls[id] {
+			if !framework.has(controlID) {
+				return fmt.Errorf("unknown %s control %q", framework.ID, controlID)
+			}
+		}
+	}
+	return nil
+}
+
+// has reports whether the framework defines the control
+func (f ControlFramework) has(controlID string) bool {
+	for _, control := range f.Controls {
+		if control.ID == controlID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,396 @@
+package security
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"gopkg.in/yaml.v3"
+)
+
+// Condition operators of declarative rules
+const (
+	OperatorEquals    = "equals"
+	OperatorNotEquals = "not_equals"
+	OperatorMissing   = "missing"
+	OperatorPresent   = "present"
+	OperatorMatches   = "matches"
+	OperatorIn        = "in"
+	OperatorNotIn     = "not_in"
+	OperatorLess      = "lt"
+	OperatorLessEq    = "lte"
+	OperatorGreater   = "gt"
+	OperatorGreaterEq = "gte"
+)
+
+// operators lists the supported condition operators
+var operators = []string{
+	OperatorEquals, OperatorNotEquals, OperatorMissing, OperatorPresent, OperatorMatches,
+	OperatorIn, OperatorNotIn, OperatorLess, OperatorLessEq, OperatorGreater, OperatorGreaterEq,
+}
+
+// severities lists the valid issue severities, lowest first
+var severities = []string{"LOW", "MEDIUM", "HIGH", "CRITICAL"}
+
+// RuleFile is a YAML or JSON document of declarative security rules
+type RuleFile struct {
+	Rules []CustomRule `yaml:"rules"`
+}
+
+// CustomRule is a declarative security rule. A resource of one of the
+// resource types violates the rule when all of its conditions hold.
+type CustomRule struct {
+	ID            string      `yaml:"id"`
+	Name          string      `yaml:"name"`
+	Severity      string      `yaml:"severity"`
+	ResourceTypes []string    `yaml:"resource_types"`
+	Conditions    []Condition `yaml:"conditions"`
+	Message       string      `yaml:"message"`
+	Remediation   string      `yaml:"remediation"`
+	// Controls maps control frameworks to the controls the rule covers,
+	// e.g. nist-800-53: [SC-28]
+	Controls map[string][]string `yaml:"controls"`
+}
+
+// Condition tests the attribute at a dotted path. Leading segments name
+// nested blocks (root_block_device.encrypted) and trailing segments index
+// into object values (tags.Owner). Conditions on values computed from
+// variables or other resources do not hold, except missing and present.
+type Condition struct {
+	Attribute string   `yaml:"attribute"`
+	Operator  string   `yaml:"operator"`
+	Value     string   `yaml:"value"`
+	Values    []string `yaml:"values"`
+
+	pattern *regexp.Regexp
+	number  float64
+}
+
+// target is an attribute value reached by a condition's path. Found is false
+// when the path does not exist; Known is false when the value is not a literal.
+type target struct {
+	Values []string
+	Found  bool
+	Known  bool
+	Range  hcl.Range
+}
+
+// ParseRules decodes and validates a rule document. JSON is accepted as well
+// as YAML.
+func ParseRules(data []byte) ([]CustomRule, error) {
+	var file RuleFile
+
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to decode rules: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for i := range file.Rules {
+		rule := &file.Rules[i]
+		if err := rule.validate(); err != nil {
+			if rule.ID != "" {
+				return nil, fmt.Errorf("rules[%d] %s: %w", i, rule.ID, err)
+			}
+			return nil, fmt.Errorf("rules[%d]: %w", i, err)
+		}
+		if seen[rule.ID] {
+			return nil, fmt.Errorf("rules[%d]: duplicate id %q", i, rule.ID)
+		}
+		seen[rule.ID] = true
+	}
+
+	return file.Rules, nil
+}
+
+// LoadRules reads the rule files at paths and adds their rules to the
+// scanner. Nothing is added unless every file is valid and no rule ID is
+// already in use.
+func (s *Scanner) LoadRules(paths ...string) error {
+	ids := make(map[string]string)
+	for _, rule := range s.rules {
+		ids[rule.ID] = "built-in rules"
+	}
+
+	var rules []SecurityRule
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read security rules %s: %w", path, err)
+		}
+
+		custom, err := ParseRules(data)
+		if err != nil {
+			return fmt.Errorf("security rules %s: %w", path, err)
+		}
+
+		for _, rule := range custom {
+			if other, ok := ids[rule.ID]; ok {
+				return fmt.Errorf("security rules %s: rule %s is already defined in %s", path, rule.ID, other)
+			}
+			ids[rule.ID] = path
+			rules = append(rules, rule.SecurityRule())
+		}
+	}
+
+	s.rules = append(s.rules, rules...)
+	return nil
+}
+
+// validate checks a rule and prepares its conditions for matching
+func (r *CustomRule) validate() error {
+	if r.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	if r.Message == "" {
+		return fmt.Errorf("message is required")
+	}
+
+	r.Severity = strings.ToUpper(r.Severity)
+	if !contains(severities, r.Severity) {
+		return fmt.Errorf("invalid severity %q (use %s)", r.Severity, strings.Join(severities, ", "))
+	}
+
+	if len(r.ResourceTypes) == 0 {
+		return fmt.Errorf("at least one resource type is required")
+	}
+	for _, resourceType := range r.ResourceTypes {
+		if resourceType == "" {
+			return fmt.Errorf("empty resource type")
+		}
+	}
+
+	if len(r.Conditions) == 0 {
+		return fmt.Errorf("at least one condition is required")
+	}
+	for i := range r.Conditions {
+		if err := r.Conditions[i].validate(); err != nil {
+			return fmt.Errorf("conditions[%d]: %w", i, err)
+		}
+	}
+
+	if err := validateControls(r.Controls); err != nil {
+		return fmt.Errorf("controls: %w", err)
+	}
+
+	return nil
+}
+
+// validate checks a condition and compiles its pattern or number
+func (c *Condition) validate() error {
+	if c.Attribute == "" {
+		return fmt.Errorf("attribute is required")
+	}
+	for _, segment := range strings.Split(c.Attribute, ".") {
+		if segment == "" {
+			return fmt.Errorf("invalid attribute path %q", c.Attribute)
+		}
+	}
+
+	switch c.Operator {
+	case OperatorMissing, OperatorPresent:
+		if c.Value != "" || len(c.Values) > 0 {
+			return fmt.Errorf("%s takes no value", c.Operator)
+		}
+
+	case OperatorEquals, OperatorNotEquals:
+		if len(c.Values) > 0 {
+			return fmt.Errorf("%s takes a single value, not values", c.Operator)
+		}
+
+	case OperatorMatches:
+		pattern, err := regexp.Compile(c.Value)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", c.Value, err)
+		}
+		c.pattern = pattern
+
+	case OperatorIn, OperatorNotIn:
+		if len(c.Values) == 0 {
+			return fmt.Errorf("%s requires values", c.Operator)
+		}
+
+	case OperatorLess, OperatorLessEq, OperatorGreater, OperatorGreaterEq:
+		number, err := strconv.ParseFloat(c.Value, 64)
+		if err != nil {
+			return fmt.Errorf("%s requires a numeric value, got %q", c.Operator, c.Value)
+		}
+		c.number = number
+
+	case "":
+		return fmt.Errorf("operator is required (use %s)", strings.Join(operators, ", "))
+
+	default:
+		return fmt.Errorf("unknown operator %q (use %s)", c.Operator, strings.Join(operators, ", "))
+	}
+
+	return nil
+}
+
+// SecurityRule converts a validated declarative rule for the scanner
+func (r CustomRule) SecurityRule() SecurityRule {
+	name := r.Name
+	if name == "" {
+		name = r.ID
+	}
+
+	return SecurityRule{
+		ID:            r.ID,
+		Name:          name,
+		Severity:      r.Severity,
+		ResourceTypes: r.ResourceTypes,
+		Message:       r.Message,
+		Remediation:   r.Remediation,
+		Controls:      r.Controls,
+		check:         r.check,
+	}
+}
+
+// check reports the block when every condition holds, at the range of the
+// first condition's matching attribute
+func (r CustomRule) check(c *parsedConfig, b *block) []hcl.Range {
+	var first hcl.Range
+	for i, condition := range r.Conditions {
+		rng, ok := condition.holds(b)
+		if !ok {
+			return nil
+		}
+		if i == 0 {
+			first = rng
+		}
+	}
+	return []hcl.Range{first}
+}
+
+// holds reports whether the condition holds for any value the path reaches
+func (c Condition) holds(b *block) (hcl.Range, bool) {
+	for _, t := range resolve(b.Body, strings.Split(c.Attribute, "."), b.Range) {
+		if c.matches(t) {
+			return t.Range, true
+		}
+	}
+	return hcl.Range{}, false
+}
+
+// matches applies the operator to one target
+func (c Condition) matches(t target) bool {
+	switch c.Operator {
+	case OperatorMissing:
+		return !t.Found
+	case OperatorPresent:
+		return t.Found
+	}
+	if !t.Found || !t.Known {
+		return false
+	}
+
+	for _, value := range t.Values {
+		switch c.Operator {
+		case OperatorEquals:
+			if value == c.Value {
+				return true
+			}
+		case OperatorNotEquals:
+			if value != c.Value {
+				return true
+			}
+		case OperatorMatches:
+			if c.pattern.MatchString(value) {
+				return true
+			}
+		case OperatorIn:
+			if contains(c.Values, value) {
+				return true
+			}
+		case OperatorNotIn:
+			if !contains(c.Values, value) {
+				return true
+			}
+		default:
+			number, err := strconv.ParseFloat(value, 64)
+			if err == nil && compare(number, c.Operator, c.number) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compare applies a numeric operator
+func compare(a float64, operator string, b float64) bool {
+	switch operator {
+	case OperatorLess:
+		return a < b
+	case OperatorLessEq:
+		return a <= b
+	case OperatorGreater:
+		return a > b
+	case OperatorGreaterEq:
+		return a >= b
+	default:
+		return false
+	}
+}
+
+// resolve follows a path through nested blocks, an attribute and keys of
+// its object value. A block type that repeats yields a target per block; a
+// path that does not exist yields one target with Found unset, at the range
+// of the closest existing block.
+func resolve(body *hclsyntax.Body, path []string, rng hcl.Range) []target {
+	name := path[0]
+
+	if attr, ok := body.Attributes[name]; ok {
+		return []target{attributeTarget(attr, path[1:])}
+	}
+
+	if len(path) > 1 {
+		var targets []target
+		for _, nested := range nestedBlocks(body, name) {
+			targets = append(targets, resolve(nested.Body, path[1:], nested.DefRange())...)
+		}
+		if len(targets) > 0 {
+			return targets
+		}
+	}
+
+	return []target{{Range: rng}}
+}
+
+// attributeTarget evaluates an attribute and indexes into its value by keys
+func attributeTarget(attr *hclsyntax.Attribute, keys []string) target {
+	t := target{Found: true, Range: attr.SrcRange}
+
+	if len(keys) == 0 {
+		t.Values, t.Known = literalStrings(attr.Expr)
+		return t
+	}
+
+	value, ok := literalValue(attr.Expr)
+	if !ok {
+		return t
+	}
+	for _, key := range keys {
+		switch {
+		case value.Type().IsObjectType() && value.Type().HasAttribute(key):
+			value = value.GetAttr(key)
+		case value.Type().IsMapType() && value.HasIndex(cty.StringVal(key)).True():
+			value = value.Index(cty.StringVal(key))
+		default:
+			return target{Range: attr.SrcRange}
+		}
+		if value.IsNull() {
+			return target{Range: attr.SrcRange}
+		}
+	}
+
+	if values, ok := ctyStrings(value); ok {
+		t.Values, t.Known = values, true
+	}
+	return t
+}
@@ -0,0 +1,232 @@
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadRules(t *testing.T) {
+	scanner := NewScanner()
+	if err := scanner.LoadRules("../../examples/rules/tagging.yaml"); err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+
+	config := `resource "aws_instance" "web" {
+  ami                    = "ami-0c55b159cbfafe1f0"
+  instance_type          = "m5.xlarge"
+  vpc_security_group_ids = ["sg-123"]
+
+  root_block_device {
+    encrypted = false
+  }
+}
+
+resource "aws_instance" "worker" {
+  ami                    = "ami-0c55b159cbfafe1f0"
+  instance_type          = "t3.small"
+  vpc_security_group_ids = ["sg-123"]
+
+  root_block_device {
+    encrypted = true
+  }
+
+  tags = {
+    Owner = "platform"
+  }
+}
+
+resource "aws_cloudwatch_log_group" "short" {
+  retention_in_days = 30
+}
+
+resource "aws_cloudwatch_log_group" "long" {
+  retention_in_days = 365
+}
+
+resource "aws_cloudwatch_log_group" "computed" {
+  retention_in_days = var.retention
+}
+`
+	issues := scan(t, scanner, config).Issues
+
+	tests := []struct {
+		rule string
+		want []string
+	}{
+		{"ORG001", []string{"aws_instance.web 1:1"}},
+		{"ORG002", []string{"aws_cloudwatch_log_group.short 26:3"}},
+		{"ORG003", []string{"aws_instance.web 3:3"}},
+		{"ORG004", []string{"aws_instance.web 7:5"}},
+	}
+	for _, tt := range tests {
+		if got := reported(issues, tt.rule); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s issues = %v, want %v", tt.rule, got, tt.want)
+		}
+	}
+
+	for _, issue := range issues {
+		if issue.Rule == "ORG003" && (issue.Severity != "MEDIUM" || issue.Message != "Instance type is not on the approved list") {
+			t.Errorf("ORG003 issue = %+v, want the rule's severity and message", issue)
+		}
+	}
+}
+
+func TestParseRulesJSON(t *testing.T) {
+	rules, err := ParseRules([]byte(`{"rules": [{"id": "ORG100", "severity": "high", "resource_types": ["aws_s3_bucket"],
+		"conditions": [{"attribute": "bucket", "operator": "matches", "value": "^tmp-"}], "message": "Temporary bucket",
+		"controls": {"nist-800-53": ["SC-28"]}}]}`))
+	if err != nil {
+		t.Fatalf("ParseRules() error = %v", err)
+	}
+	if len(rules) != 1 || rules[0].Severity != "HIGH" || rules[0].SecurityRule().Name != "ORG100" {
+		t.Errorf("ParseRules() = %+v, want one HIGH rule named after its id", rules)
+	}
+}
+
+func TestParseRulesErrors(t *testing.T) {
+	valid := `
+    severity: LOW
+    resource_types: [aws_s3_bucket]
+    message: Bucket rule`
+
+	tests := []struct {
+		name    string
+		rules   string
+		wantErr string
+	}{
+		{
+			name:    "unknown field",
+			rules:   "  - id: ORG001\n    severty: LOW\n    resource_types: [aws_s3_bucket]\n    message: m\n    conditions:\n      - {attribute: acl, operator: present}",
+			wantErr: "field severty not found",
+		},
+		{
+			name:    "missing id",
+			rules:   "  - message: m\n    severity: LOW\n    resource_types: [aws_s3_bucket]\n    conditions:\n      - {attribute: acl, operator: present}",
+			wantErr: "rules[0]: id is required",
+		},
+		{
+			name:    "missing message",
+			rules:   "  - id: ORG001\n    severity: LOW\n    resource_types: [aws_s3_bucket]\n    conditions:\n      - {attribute: acl, operator: present}",
+			wantErr: "rules[0] ORG001: message is required",
+		},
+		{
+			name:    "invalid severity",
+			rules:   "  - id: ORG001\n    severity: URGENT\n    resource_types: [aws_s3_bucket]\n    message: m\n    conditions:\n      - {attribute: acl, operator: present}",
+			wantErr: `invalid severity "URGENT"`,
+		},
+		{
+			name:    "no resource types",
+			rules:   "  - id: ORG001\n    severity: LOW\n    message: m\n    conditions:\n      - {attribute: acl, operator: present}",
+			wantErr: "at least one resource type is required",
+		},
+		{
+			name:    "no conditions",
+			rules:   "  - id: ORG001" + valid,
+			wantErr: "at least one condition is required",
+		},
+		{
+			name:    "missing operator",
+			rules:   "  - id: ORG001" + valid + "\n    conditions:\n      - {attribute: acl}",
+			wantErr: "conditions[0]: operator is required",
+		},
+		{
+			name:    "unknown operator",
+			rules:   "  - id: ORG001" + valid + "\n    conditions:\n      - {attribute: acl, operator: contains}",
+			wantErr: `conditions[0]: unknown operator "contains"`,
+		},
+		{
+			name:    "value on missing",
+			rules:   "  - id: ORG001" + valid + "\n    conditions:\n      - {attribute: acl, operator: missing, value: private}",
+			wantErr: "missing takes no value",
+		},
+		{
+			name:    "values on equals",
+			rules:   "  - id: ORG001" + valid + "\n    conditions:\n      - {attribute: acl, operator: equals, values: [private]}",
+			wantErr: "equals takes a single value",
+		},
+		{
+			name:    "in without values",
+			rules:   "  - id: ORG001" + valid + "\n    conditions:\n      - {attribute: acl, operator: in}",
+			wantErr: "in requires values",
+		},
+		{
+			name:    "non-numeric comparison",
+			rules:   "  - id: ORG001" + valid + "\n    conditions:\n      - {attribute: retention_in_days, operator: lt, value: ninety}",
+			wantErr: `lt requires a numeric value, got "ninety"`,
+		},
+		{
+			name:    "invalid pattern",
+			rules:   "  - id: ORG001" + valid + "\n    conditions:\n      - {attribute: bucket, operator: matches, value: \"[\"}",
+			wantErr: "invalid pattern",
+		},
+		{
+			name:    "empty path segment",
+			rules:   "  - id: ORG001" + valid + "\n    conditions:\n      - {attribute: tags..Owner, operator: missing}",
+			wantErr: `invalid attribute path "tags..Owner"`,
+		},
+		{
+			name:    "unknown control",
+			rules:   "  - id: ORG001" + valid + "\n    conditions:\n      - {attribute: acl, operator: present}\n    controls:\n      nist-800-53: [XX-1]",
+			wantErr: `controls: unknown nist-800-53 control "XX-1"`,
+		},
+		{
+			name:    "duplicate id",
+			rules:   "  - id: ORG001" + valid + "\n    conditions:\n      - {attribute: acl, operator: present}\n  - id: ORG001" + valid + "\n    conditions:\n      - {attribute: acl, operator: present}",
+			wantErr: `rules[1]: duplicate id "ORG001"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRules([]byte("rules:\n" + tt.rules + "\n"))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseRules() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadRulesRejectsConflicts(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	rule := "rules:\n  - id: %s\n    severity: LOW\n    resource_types: [aws_s3_bucket]\n    message: m\n    conditions:\n      - {attribute: acl, operator: present}\n"
+	first := write("first.yaml", strings.Replace(rule, "%s", "ORG001", 1))
+	second := write("second.yaml", strings.Replace(rule, "%s", "ORG001", 1))
+	builtin := write("builtin.yaml", strings.Replace(rule, "%s", "SEC001", 1))
+	invalid := write("invalid.yaml", "rules: [")
+
+	tests := []struct {
+		name    string
+		paths   []string
+		wantErr string
+	}{
+		{"id of a built-in rule", []string{builtin}, "rule SEC001 is already defined in built-in rules"},
+		{"id defined in two files", []string{first, second}, "rule ORG001 is already defined in " + first},
+		{"malformed file", []string{first, invalid}, "security rules " + invalid + ": failed to decode rules"},
+		{"missing file", []string{filepath.Join(dir, "missing.yaml")}, "failed to read security rules"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := NewScanner()
+			before := len(scanner.GetRules())
+
+			err := scanner.LoadRules(tt.paths...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadRules() error = %v, want %q", err, tt.wantErr)
+			}
+			if after := len(scanner.GetRules()); after != before {
+				t.Errorf("LoadRules() added %d rules despite the error", after-before)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package security
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ComplianceRuleSet lists the rules a compliance framework enforces
+type ComplianceRuleSet struct {
+	Name                string
+	Rules               []string
+	MinBackupRetention  int
+	RequireAuditLogging bool
+	RequireDatabaseLogs bool
+}
+
+// frameworkRuleSets maps framework identifiers (as reported by the NLP
+// parser) to the rules enforced for them. CMP rules are compliance checks
+// that only run for frameworks.
+var frameworkRuleSets = map[string]ComplianceRuleSet{
+	"hipaa": {
+		Name:                "HIPAA",
+		Rules:               []string{"SEC001", "SEC002", "SEC003", "SEC004", "SEC005", "SEC008", "SEC010", "SEC011", "SEC015", "SEC016"},
+		MinBackupRetention:  35,
+		RequireAuditLogging: true,
+		RequireDatabaseLogs: true,
+	},
+	"pci-dss": {
+		Name:                "PCI-DSS",
+		Rules:               []string{"SEC001", "SEC002", "SEC003", "SEC004", "SEC005", "SEC006", "SEC007", "SEC008", "SEC010", "SEC011", "SEC014", "SEC015"},
+		MinBackupRetention:  30,
+		RequireAuditLogging: true,
+		RequireDatabaseLogs: true,
+	},
+	"soc2": {
+		Name:                "SOC 2",
+		Rules:               []string{"SEC001", "SEC003", "SEC005", "SEC008", "SEC010", "SEC011", "SEC012", "SEC015"},
+		MinBackupRetention:  14,
+		RequireAuditLogging: true,
+	},
+	"cis": {
+		Name:                "CIS",
+		Rules:               []string{"SEC001", "SEC003", "SEC004", "SEC005", "SEC009", "SEC011", "SEC012", "SEC013", "SEC014", "SEC015"},
+		MinBackupRetention:  7,
+		RequireAuditLogging: true,
+	},
+	"fedramp": {
+		Name:                "FedRAMP",
+		Rules:               []string{"SEC001", "SEC002", "SEC003", "SEC004", "SEC005", "SEC006", "SEC007", "SEC008", "SEC009", "SEC010", "SEC011", "SEC012", "SEC013", "SEC014", "SEC015", "SEC016"},
+		MinBackupRetention:  35,
+		RequireAuditLogging: true,
+		RequireDatabaseLogs: true,
+	},
+}
+
+// Frameworks returns the identifiers of the supported compliance frameworks
+func Frameworks() []string {
+	ids := make([]string, 0, len(frameworkRuleSets))
+	for id := range frameworkRuleSets {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// CheckCompliance scans the configuration and returns the violations of the
+// rule sets of the given frameworks. Violations are reported at HIGH
+// severity or above and prefixed with the framework name.
+func (s *Scanner) CheckCompliance(config string, frameworks []string) ([]Issue, error) {
+	if len(frameworks) == 0 {
+		return nil, nil
+	}
+
+	scanned, err := s.Scan(config)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	for _, id := range frameworks {
+		ruleSet, ok := frameworkRuleSets[strings.ToLower(id)]
+		if !ok {
+			return nil, fmt.Errorf("unknown compliance framework: %s (use %s)", id, strings.Join(Frameworks(), ", "))
+		}
+
+		for _, issue := range scanned.Issues {
+			if contains(ruleSet.Rules, issue.Rule) {
+				issues = append(issues, complianceIssue(ruleSet, issue))
+			}
+		}
+		for _, issue := range ruleSet.check(parsed) {
+			issues = append(issues, complianceIssue(ruleSet, issue))
+		}
+	}
+
+	return issues, nil
+}
+
+// check runs the compliance-only checks of the rule set
+func (r ComplianceRuleSet) check(parsed *parsedConfig) []Issue {
+	var issues []Issue
+
+	if r.RequireAuditLogging && len(parsed.resources()) > 0 && len(parsed.resources("aws_cloudtrail")) == 0 {
+		issues = append(issues, Issue{
+			Message:     "No CloudTrail trail records API activity for audit logging",
+			Rule:        "CMP001",
+			Remediation: "Add an aws_cloudtrail resource with log file validation enabled",
+		})
+	}
+
+	for _, block := range parsed.resources("aws_db_instance", "aws_rds_cluster") {
+		value, _ := attributeValue(block.Body, "backup_retention_period")
+		retention, err := strconv.Atoi(value)
+		if err != nil || retention < r.MinBackupRetention {
+			issues = append(issues, Issue{
+				Message:     fmt.Sprintf("Database backup retention is below %d days", r.MinBackupRetention),
+				Resource:    block.Address(),
+				Line:        block.Range.Start.Line,
+				Column:      block.Range.Start.Column,
+				Rule:        "CMP002",
+				Remediation: fmt.Sprintf("Set backup_retention_period = %d or more", r.MinBackupRetention),
+			})
+		}
+
+		if r.RequireDatabaseLogs && block.Body.Attributes["enabled_cloudwatch_logs_exports"] == nil {
+			issues = append(issues, Issue{
+				Message:     "Database does not export audit logs",
+				Resource:    block.Address(),
+				Line:        block.Range.Start.Line,
+				Column:      block.Range.Start.Column,
+				Rule:        "CMP003",
+				Remediation: "Set enabled_cloudwatch_logs_exports to include the engine's audit or error logs",
+			})
+		}
+	}
+
+	return issues
+}
+
+// complianceIssue tags an issue with the framework and raises it to at least HIGH
+func complianceIssue(ruleSet ComplianceRuleSet, issue Issue) Issue {
+	issue.Message = fmt.Sprintf("[%s] %s", ruleSet.Name, issue.Message)
+	if issue.Severity != "CRITICAL" {
+		issue.Severity = "HIGH"
+	}
+	return issue
+}
+
+// contains reports whether value is in values
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package security
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// googlePublicMembers are the IAM members and ACL entities that grant access
+// to everyone
+var googlePublicMembers = []string{"allUsers", "allAuthenticatedUsers"}
+
+// googleRules returns the rule pack for google resources
+func googleRules() []SecurityRule {
+	return []SecurityRule{
+		{
+			ID:       "GCP001",
+			Name:     "Public Cloud Storage Bucket",
+			Severity: "HIGH",
+			Provider: ProviderGoogle,
+			ResourceTypes: []string{
+				"google_storage_bucket_iam_member",
+				"google_storage_bucket_iam_binding",
+				"google_storage_bucket_access_control",
+				"google_storage_bucket_acl",
+				"google_storage_default_object_access_control",
+			},
+			Message:     "Cloud Storage bucket grants access to allUsers or allAuthenticatedUsers",
+			Remediation: "Grant bucket access to specific users, groups or service accounts",
+			Controls: map[string][]string{
+				ControlsNIST80053: {"AC-3"},
+				ControlsPCIDSS:    {"1.4.4"},
+				ControlsHIPAA:     {"164.312(a)(1)"},
+			},
+			check: checkPublicBucketMembers,
+		},
+		{
+			ID:            "GCP002",
+			Name:          "GKE Legacy ABAC",
+			Severity:      "HIGH",
+			Provider:      ProviderGoogle,
+			ResourceTypes: []string{"google_container_cluster"},
+			Message:       "GKE cluster has legacy ABAC authorization enabled",
+			Remediation:   "Set enable_legacy_abac to false and grant access with Kubernetes RBAC",
+			Controls: map[string][]string{
+				ControlsNIST80053: {"AC-6"},
+				ControlsPCIDSS:    {"7.2.2"},
+				ControlsHIPAA:     {"164.308(a)(4)"},
+			},
+			check: checkAttributeEquals("enable_legacy_abac", "true"),
+			fix:   fixAttribute("enable_legacy_abac", cty.False),
+		},
+		{
+			ID:            "GCP003",
+			Name:          "Open Firewall Rule",
+			Severity:      "CRITICAL",
+			Provider:      ProviderGoogle,
+			ResourceTypes: []string{"google_compute_firewall"},
+			Message:       "Firewall rule allows ingress from anywhere (0.0.0.0/0)",
+			Remediation:   "Restrict source_ranges to specific IP ranges",
+			Controls: map[string][]string{
+				ControlsNIST80053: {"SC-7"},
+				ControlsPCIDSS:    {"1.3.1"},
+			},
+			check: checkOpenFirewall,
+		},
+		{
+			ID:            "GCP004",
+			Name:          "Public GKE Nodes",
+			Severity:      "MEDIUM",
+			Provider:      ProviderGoogle,
+			ResourceTypes: []string{"google_container_cluster"},
+			Message:       "GKE cluster is not private; nodes have public IP addresses",
+			Remediation:   "Add a private_cluster_config with enable_private_nodes = true",
+			Controls: map[string][]string{
+				ControlsNIST80053: {"SC-7"},
+				ControlsPCIDSS:    {"1.3.1"},
+			},
+			check: checkPrivateCluster,
+		},
+	}
+}
+
+// checkPublicBucketMembers flags bucket IAM members and ACL entities that
+// grant access to everyone. ACL entries are written as ROLE:entity.
+func checkPublicBucketMembers(c *parsedConfig, b *block) []hcl.Range {
+	var ranges []hcl.Range
+	for _, name := range []string{"member", "members", "entity", "role_entity"} {
+		attr, ok := b.Body.Attributes[name]
+		if !ok {
+			continue
+		}
+		values, _ := literalStrings(attr.Expr)
+		for _, value := range values {
+			if contains(googlePublicMembers, value[strings.LastIndex(value, ":")+1:]) {
+				ranges = append(ranges, attr.SrcRange)
+				break
+			}
+		}
+	}
+	return ranges
+}
+
+// checkOpenFirewall flags ingress firewall rules that allow traffic from any
+// address. Deny rules open to anywhere are left alone.
+func checkOpenFirewall(c *parsedConfig, b *block) []hcl.Range {
+	if direction, ok := attributeValue(b.Body, "direction"); ok && direction != "INGRESS" {
+		return nil
+	}
+	if len(nestedBlocks(b.Body, "allow")) == 0 {
+		return nil
+	}
+	return openCIDRAttributes(b.Body, "source_ranges")
+}
+
+// checkPrivateCluster flags clusters without private nodes
+func checkPrivateCluster(c *parsedConfig, b *block) []hcl.Range {
+	configs := nestedBlocks(b.Body, "private_cluster_config")
+	if len(configs) == 0 {
+		return []hcl.Range{b.Range}
+	}
+	if notEnabled(configs[0].Body, "enable_private_nodes") {
+		return []hcl.Range{attributeRange(configs[0].Body, "enable_private_nodes", configs[0].DefRange())}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package security
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGoogleRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   string
+		config string
+		want   []string
+	}{
+		{
+			name: "bucket member granting allUsers",
+			rule: "GCP001",
+			config: `resource "google_storage_bucket_iam_member" "public" {
+  bucket = "assets"
+  role   = "roles/storage.objectViewer"
+  member = "allUsers"
+}
+`,
+			want: []string{"google_storage_bucket_iam_member.public 4:3"},
+		},
+		{
+			name: "bucket ACL granting allAuthenticatedUsers",
+			rule: "GCP001",
+			config: `resource "google_storage_bucket_acl" "assets" {
+  bucket      = "assets"
+  role_entity = ["OWNER:project-owners-123", "READER:allAuthenticatedUsers"]
+}
+`,
+			want: []string{"google_storage_bucket_acl.assets 3:3"},
+		},
+		{
+			name: "bucket member granting a user",
+			rule: "GCP001",
+			config: `resource "google_storage_bucket_iam_binding" "readers" {
+  bucket  = "assets"
+  role    = "roles/storage.objectViewer"
+  members = ["user:jane@example.com", "group:allUsers@example.com"]
+}
+`,
+		},
+		{
+			name: "cluster with legacy ABAC",
+			rule: "GCP002",
+			config: `resource "google_container_cluster" "main" {
+  enable_legacy_abac = true
+}
+`,
+			want: []string{"google_container_cluster.main 2:3"},
+		},
+		{
+			name: "cluster without legacy ABAC",
+			rule: "GCP002",
+			config: `resource "google_container_cluster" "main" {
+  enable_legacy_abac = false
+}
+`,
+		},
+		{
+			name: "ingress firewall open to anywhere",
+			rule: "GCP003",
+			config: `resource "google_compute_firewall" "ssh" {
+  network       = "default"
+  source_ranges = ["0.0.0.0/0"]
+
+  allow {
+    protocol = "tcp"
+    ports    = ["22"]
+  }
+}
+`,
+			want: []string{"google_compute_firewall.ssh 3:3"},
+		},
+		{
+			name: "deny and egress firewalls open to anywhere",
+			rule: "GCP003",
+			config: `resource "google_compute_firewall" "deny" {
+  source_ranges = ["0.0.0.0/0"]
+
+  deny {
+    protocol = "tcp"
+  }
+}
+
+resource "google_compute_firewall" "egress" {
+  direction     = "EGRESS"
+  source_ranges = ["0.0.0.0/0"]
+
+  allow {
+    protocol = "tcp"
+  }
+}
+`,
+		},
+		{
+			name: "cluster without private nodes",
+			rule: "GCP004",
+			config: `resource "google_container_cluster" "main" {
+  name = "main"
+}
+
+resource "google_container_cluster" "partial" {
+  private_cluster_config {
+    enable_private_endpoint = true
+  }
+}
+`,
+			want: []string{"google_container_cluster.main 1:1", "google_container_cluster.partial 6:3"},
+		},
+		{
+			name: "private cluster",
+			rule: "GCP004",
+			config: `resource "google_container_cluster" "main" {
+  private_cluster_config {
+    enable_private_nodes = true
+  }
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reported(scan(t, NewScanner(), tt.config).Issues, tt.rule)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("%s issues = %v, want %v", tt.rule, got, tt.want)
+			}
+		})
+	}
+}
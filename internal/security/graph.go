@@ -0,0 +1,255 @@
+package security
+
+import (
+	"strconv"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// publicAccessSettings are the settings of an aws_s3_bucket_public_access_block
+// that together keep a bucket private
+var publicAccessSettings = []string{"block_public_acls", "block_public_policy", "ignore_public_acls", "restrict_public_buckets"}
+
+// resourceGraph holds the references between the resources and data
+// sources of a configuration, taken from the traversals in their attributes
+type resourceGraph struct {
+	references []reference
+}
+
+// reference is an attribute of one block that refers to another, e.g.
+// aws_db_instance.main's db_subnet_group_name = aws_db_subnet_group.main.name
+type reference struct {
+	from *block
+	to   *block
+	// attribute is the path of the referring attribute below from, with
+	// nested blocks separated by dots, e.g. route.gateway_id
+	attribute string
+}
+
+// graph returns the references between the configuration's blocks, built on
+// first use
+func (c *parsedConfig) graph() *resourceGraph {
+	if c.references != nil {
+		return c.references
+	}
+
+	addresses := make(map[string]*block)
+	for _, b := range c.blocks {
+		if b.Kind == "resource" || b.Kind == "data" {
+			addresses[b.Address()] = b
+		}
+	}
+
+	g := &resourceGraph{}
+	for _, b := range c.blocks {
+		if b.Kind == "resource" || b.Kind == "data" {
+			g.addReferences(b, b.Body, "", addresses)
+		}
+	}
+
+	c.references = g
+	return g
+}
+
+// addReferences records the references from the attributes of body, found
+// at path below block from, and from its nested blocks
+func (g *resourceGraph) addReferences(from *block, body *hclsyntax.Body, path string, addresses map[string]*block) {
+	for _, attr := range sortedAttributes(body) {
+		seen := make(map[*block]bool)
+		for _, traversal := range attr.Expr.Variables() {
+			to, ok := addresses[traversalAddress(traversal)]
+			if !ok || to == from || seen[to] {
+				continue
+			}
+			seen[to] = true
+			g.references = append(g.references, reference{from: from, to: to, attribute: path + attr.Name})
+		}
+	}
+	for _, nested := range body.Blocks {
+		g.addReferences(from, nested.Body, path+nested.Type+".", addresses)
+	}
+}
+
+// traversalAddress returns the address of the resource or data source a
+// traversal starts with, e.g. aws_subnet.private for aws_subnet.private[0].id
+func traversalAddress(traversal hcl.Traversal) string {
+	attrName := func(i int) string {
+		if i < len(traversal) {
+			if attr, ok := traversal[i].(hcl.TraverseAttr); ok {
+				return attr.Name
+			}
+		}
+		return ""
+	}
+
+	switch root := traversal.RootName(); root {
+	case "var", "local", "module", "each", "count", "path", "self", "terraform":
+		return ""
+	case "data":
+		if attrName(1) == "" || attrName(2) == "" {
+			return ""
+		}
+		return "data." + attrName(1) + "." + attrName(2)
+	default:
+		if attrName(1) == "" {
+			return ""
+		}
+		return root + "." + attrName(1)
+	}
+}
+
+// dependencies returns the blocks of the given types (any type when none are
+// given) that b refers to through attribute, or through any attribute when
+// attribute is empty
+func (g *resourceGraph) dependencies(b *block, attribute string, types ...string) []*block {
+	var result []*block
+	for _, ref := range g.references {
+		if ref.from == b && (attribute == "" || ref.attribute == attribute) && (len(types) == 0 || contains(types, ref.to.Type)) {
+			result = append(result, ref.to)
+		}
+	}
+	return result
+}
+
+// dependents returns the blocks of the given types that refer to b through
+// attribute, or through any attribute when attribute is empty
+func (g *resourceGraph) dependents(b *block, attribute string, types ...string) []*block {
+	var result []*block
+	for _, ref := range g.references {
+		if ref.to == b && (attribute == "" || ref.attribute == attribute) && (len(types) == 0 || contains(types, ref.from.Type)) {
+			result = append(result, ref.from)
+		}
+	}
+	return result
+}
+
+// checkBucketPublicAccessBlock flags buckets without an
+// aws_s3_bucket_public_access_block that turns on all four settings
+func checkBucketPublicAccessBlock(c *parsedConfig, b *block) []hcl.Range {
+	for _, access := range c.referencedBy(b, "bucket", "aws_s3_bucket_public_access_block") {
+		if publicAccessBlocked(access) {
+			return nil
+		}
+	}
+	return []hcl.Range{b.Range}
+}
+
+// publicAccessBlocked reports whether a public access block turns on all
+// four settings
+func publicAccessBlocked(access *block) bool {
+	for _, setting := range publicAccessSettings {
+		if notEnabled(access.Body, setting) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkDatabaseInPublicSubnet flags databases whose subnet group contains a
+// subnet with a route to an internet gateway
+func checkDatabaseInPublicSubnet(c *parsedConfig, b *block) []hcl.Range {
+	g := c.graph()
+	for _, group := range g.dependencies(b, "db_subnet_group_name", "aws_db_subnet_group") {
+		for _, subnet := range g.dependencies(group, "subnet_ids", "aws_subnet") {
+			if g.publicSubnet(subnet) {
+				return []hcl.Range{b.Body.Attributes["db_subnet_group_name"].SrcRange}
+			}
+		}
+	}
+	return nil
+}
+
+// publicSubnet reports whether a subnet is associated with a route table
+// that routes to an internet gateway
+func (g *resourceGraph) publicSubnet(subnet *block) bool {
+	for _, association := range g.dependents(subnet, "subnet_id", "aws_route_table_association") {
+		for _, table := range g.dependencies(association, "route_table_id", "aws_route_table") {
+			if g.routesToInternet(table) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// routesToInternet reports whether a route table has a route to an internet
+// gateway, inline or as an aws_route
+func (g *resourceGraph) routesToInternet(table *block) bool {
+	if len(g.dependencies(table, "route.gateway_id", "aws_internet_gateway")) > 0 {
+		return true
+	}
+	for _, route := range g.dependents(table, "route_table_id", "aws_route") {
+		if len(g.dependencies(route, "gateway_id", "aws_internet_gateway")) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// checkReachableSSH flags instances and launch templates attached to a
+// security group that allows SSH from anywhere
+func checkReachableSSH(c *parsedConfig, b *block) []hcl.Range {
+	g := c.graph()
+	var ranges []hcl.Range
+	for _, name := range []string{"vpc_security_group_ids", "security_groups"} {
+		for _, group := range g.dependencies(b, name, "aws_security_group") {
+			if g.openSSH(group) {
+				ranges = append(ranges, b.Body.Attributes[name].SrcRange)
+				break
+			}
+		}
+	}
+	return ranges
+}
+
+// openSSH reports whether a security group allows port 22 from anywhere,
+// through its ingress blocks or standalone rules
+func (g *resourceGraph) openSSH(group *block) bool {
+	for _, ingress := range nestedBlocks(group.Body, "ingress") {
+		if allowsPort(ingress.Body, "protocol", 22) && len(openCIDRAttributes(ingress.Body, "cidr_blocks", "ipv6_cidr_blocks")) > 0 {
+			return true
+		}
+	}
+
+	for _, rule := range g.dependents(group, "security_group_id", "aws_security_group_rule", "aws_vpc_security_group_ingress_rule") {
+		if rule.Type == "aws_security_group_rule" {
+			if ruleType, _ := attributeValue(rule.Body, "type"); ruleType != "ingress" {
+				continue
+			}
+			if allowsPort(rule.Body, "protocol", 22) && len(openCIDRAttributes(rule.Body, "cidr_blocks", "ipv6_cidr_blocks")) > 0 {
+				return true
+			}
+			continue
+		}
+		if allowsPort(rule.Body, "ip_protocol", 22) && len(openCIDRAttributes(rule.Body, "cidr_ipv4", "cidr_ipv6")) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsPort reports whether a rule's literal protocol and port range cover
+// a TCP port. All protocols ("-1") cover every port.
+func allowsPort(body *hclsyntax.Body, protocolAttribute string, port int) bool {
+	protocol, _ := attributeValue(body, protocolAttribute)
+	switch protocol {
+	case "-1", "all":
+		return true
+	case "tcp", "6":
+	default:
+		return false
+	}
+
+	fromValue, _ := attributeValue(body, "from_port")
+	toValue, _ := attributeValue(body, "to_port")
+	from, err := strconv.Atoi(fromValue)
+	if err != nil {
+		return false
+	}
+	to, err := strconv.Atoi(toValue)
+	if err != nil {
+		return false
+	}
+	return from <= port && port <= to
+}
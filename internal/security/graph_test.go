@@ -0,0 +1,314 @@
+package security
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGraphReferences(t *testing.T) {
+	parsed, err := parseConfig(`data "aws_ami" "ubuntu" {
+  most_recent = true
+}
+
+resource "aws_instance" "web" {
+  ami                    = data.aws_ami.ubuntu.id
+  subnet_id              = aws_subnet.public[0].id
+  vpc_security_group_ids = [aws_security_group.web.id, aws_security_group.web.arn, var.extra_group]
+  iam_instance_profile   = aws_iam_instance_profile.missing.name
+
+  network_interface {
+    network_interface_id = aws_network_interface.web.id
+  }
+}
+
+resource "aws_subnet" "public" {
+  count  = 2
+  vpc_id = aws_vpc.main.id
+}
+
+resource "aws_security_group" "web" {
+  name = "${local.prefix}-web"
+}
+
+resource "aws_network_interface" "web" {
+  subnet_id = aws_subnet.public[1].id
+}
+
+resource "aws_vpc" "main" {
+  cidr_block = "10.0.0.0/16"
+}
+`)
+	if err != nil {
+		t.Fatalf("parseConfig() error = %v", err)
+	}
+
+	var got []string
+	for _, ref := range parsed.graph().references {
+		got = append(got, ref.from.Address()+" "+ref.attribute+" -> "+ref.to.Address())
+	}
+	want := []string{
+		"aws_instance.web ami -> data.aws_ami.ubuntu",
+		"aws_instance.web subnet_id -> aws_subnet.public",
+		"aws_instance.web vpc_security_group_ids -> aws_security_group.web",
+		"aws_instance.web network_interface.network_interface_id -> aws_network_interface.web",
+		"aws_subnet.public vpc_id -> aws_vpc.main",
+		"aws_network_interface.web subnet_id -> aws_subnet.public",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("references =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// publicNetwork is a VPC with a public subnet, routed to an internet gateway,
+// and a private one
+const publicNetwork = `resource "aws_vpc" "main" {
+  cidr_block = "10.0.0.0/16"
+}
+
+resource "aws_internet_gateway" "main" {
+  vpc_id = aws_vpc.main.id
+}
+
+resource "aws_subnet" "public" {
+  vpc_id = aws_vpc.main.id
+}
+
+resource "aws_subnet" "private" {
+  vpc_id = aws_vpc.main.id
+}
+
+resource "aws_route_table" "public" {
+  vpc_id = aws_vpc.main.id
+
+  route {
+    cidr_block = "0.0.0.0/0"
+    gateway_id = aws_internet_gateway.main.id
+  }
+}
+
+resource "aws_route_table_association" "public" {
+  subnet_id      = aws_subnet.public.id
+  route_table_id = aws_route_table.public.id
+}
+`
+
+func TestDatabaseInPublicSubnet(t *testing.T) {
+	database := `
+resource "aws_db_instance" "main" {
+  storage_encrypted    = true
+  db_subnet_group_name = %s
+}
+`
+
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{
+			name: "subnet group with a public subnet",
+			config: publicNetwork + `
+resource "aws_db_subnet_group" "main" {
+  subnet_ids = [aws_subnet.private.id, aws_subnet.public.id]
+}
+` + strings.Replace(database, "%s", "aws_db_subnet_group.main.name", 1),
+			want: []string{"aws_db_instance.main 37:3"},
+		},
+		{
+			name: "subnet group with private subnets only",
+			config: publicNetwork + `
+resource "aws_db_subnet_group" "main" {
+  subnet_ids = [aws_subnet.private.id]
+}
+` + strings.Replace(database, "%s", "aws_db_subnet_group.main.name", 1),
+		},
+		{
+			name: "subnet routed through a standalone route",
+			config: strings.Replace(publicNetwork, `
+  route {
+    cidr_block = "0.0.0.0/0"
+    gateway_id = aws_internet_gateway.main.id
+  }
+`, "", 1) + `
+resource "aws_route" "internet" {
+  route_table_id         = aws_route_table.public.id
+  destination_cidr_block = "0.0.0.0/0"
+  gateway_id             = aws_internet_gateway.main.id
+}
+
+resource "aws_db_subnet_group" "main" {
+  subnet_ids = [aws_subnet.public.id]
+}
+` + strings.Replace(database, "%s", "aws_db_subnet_group.main.name", 1),
+			want: []string{"aws_db_instance.main 38:3"},
+		},
+		{
+			name:   "subnet group reference that does not resolve",
+			config: publicNetwork + strings.Replace(database, "%s", "aws_db_subnet_group.missing.name", 1),
+		},
+		{
+			name:   "subnet group given by name",
+			config: publicNetwork + strings.Replace(database, "%s", `"shared-db-subnets"`, 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reported(scan(t, NewScanner(), tt.config).Issues, "SEC018")
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("SEC018 issues = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReachableSSH(t *testing.T) {
+	instance := `
+resource "aws_instance" "web" {
+  ami = "ami-0c55b159cbfafe1f0"
+  %s
+}
+`
+	openSSH := `resource "aws_security_group" "ssh" {
+  ingress {
+    from_port   = 22
+    to_port     = 22
+    protocol    = "tcp"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+`
+
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{
+			name:   "attached through vpc_security_group_ids",
+			config: openSSH + strings.Replace(instance, "%s", "vpc_security_group_ids = [aws_security_group.ssh.id]", 1),
+			want:   []string{"aws_instance.web 12:3"},
+		},
+		{
+			name:   "attached through security_groups",
+			config: openSSH + strings.Replace(instance, "%s", "security_groups = [aws_security_group.ssh.name]", 1),
+			want:   []string{"aws_instance.web 12:3"},
+		},
+		{
+			name: "port range and standalone rule",
+			config: `resource "aws_security_group" "admin" {
+  name = "admin"
+}
+
+resource "aws_security_group_rule" "admin" {
+  type              = "ingress"
+  security_group_id = aws_security_group.admin.id
+  from_port         = 0
+  to_port           = 1024
+  protocol          = "tcp"
+  ipv6_cidr_blocks  = ["::/0"]
+}
+` + strings.Replace(instance, "%s", "vpc_security_group_ids = [aws_security_group.admin.id]", 1),
+			want: []string{"aws_instance.web 16:3"},
+		},
+		{
+			name: "ingress rule resource for all protocols",
+			config: `resource "aws_security_group" "admin" {
+  name = "admin"
+}
+
+resource "aws_vpc_security_group_ingress_rule" "all" {
+  security_group_id = aws_security_group.admin.id
+  ip_protocol       = "-1"
+  cidr_ipv4         = "0.0.0.0/0"
+}
+` + strings.Replace(instance, "%s", "vpc_security_group_ids = [aws_security_group.admin.id]", 1),
+			want: []string{"aws_instance.web 13:3"},
+		},
+		{
+			name: "SSH from a known range and HTTPS from anywhere",
+			config: `resource "aws_security_group" "web" {
+  ingress {
+    from_port   = 22
+    to_port     = 22
+    protocol    = "tcp"
+    cidr_blocks = ["10.0.0.0/8"]
+  }
+
+  ingress {
+    from_port   = 443
+    to_port     = 443
+    protocol    = "tcp"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+
+resource "aws_security_group_rule" "ssh_egress" {
+  type              = "egress"
+  security_group_id = aws_security_group.web.id
+  from_port         = 22
+  to_port           = 22
+  protocol          = "tcp"
+  cidr_blocks       = ["0.0.0.0/0"]
+}
+` + strings.Replace(instance, "%s", "vpc_security_group_ids = [aws_security_group.web.id]", 1),
+		},
+		{
+			name:   "security group reference that does not resolve",
+			config: openSSH + strings.Replace(instance, "%s", "vpc_security_group_ids = [aws_security_group.missing.id]", 1),
+		},
+		{
+			name:   "open group not attached",
+			config: openSSH + strings.Replace(instance, "%s", `vpc_security_group_ids = ["sg-123"]`, 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reported(scan(t, NewScanner(), tt.config).Issues, "SEC019")
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("SEC019 issues = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBucketPublicAccessBlock(t *testing.T) {
+	bucket := `resource "aws_s3_bucket" "logs" {
+  bucket = "company-logs"
+}
+`
+	accessBlock := `
+resource "aws_s3_bucket_public_access_block" "logs" {
+  bucket                  = %s
+  block_public_acls       = true
+  block_public_policy     = true
+  ignore_public_acls      = true
+  restrict_public_buckets = %s
+}
+`
+	withAccessBlock := func(bucketRef, restrict string) string {
+		return bucket + strings.Replace(strings.Replace(accessBlock, "%s", bucketRef, 1), "%s", restrict, 1)
+	}
+
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{"no access block", bucket, []string{"aws_s3_bucket.logs 1:1"}},
+		{"access block by reference", withAccessBlock("aws_s3_bucket.logs.id", "true"), nil},
+		{"access block by bucket name", withAccessBlock(`"company-logs"`, "true"), nil},
+		{"access block with a setting off", withAccessBlock("aws_s3_bucket.logs.id", "false"), []string{"aws_s3_bucket.logs 1:1"}},
+		{"access block of a bucket that does not resolve", withAccessBlock("aws_s3_bucket.missing.id", "true"), []string{"aws_s3_bucket.logs 1:1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reported(scan(t, NewScanner(), tt.config).Issues, "SEC017")
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("SEC017 issues = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
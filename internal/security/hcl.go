@@ -0,0 +1,300 @@
+package security
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// Terraform providers that select the built-in rule packs
+const (
+	ProviderAWS    = "aws"
+	ProviderAzure  = "azurerm"
+	ProviderGoogle = "google"
+)
+
+// parsedConfig is a Terraform configuration parsed into HCL syntax
+type parsedConfig struct {
+	source []byte
+	blocks []*block
+	// references is the resource graph, see graph
+	references *resourceGraph
+}
+
+// block is a top-level block of a configuration: a resource, data source,
+// provider, variable, module, output or locals block
+type block struct {
+	Kind  string // "resource", "data", "provider", ...
+	Type  string // first label: resource type, provider or variable name
+	Name  string // second label of resource and data blocks
+	Body  *hclsyntax.Body
+	Range hcl.Range // the block header, e.g. resource "aws_s3_bucket" "logs"
+}
+
+// parseConfig parses a configuration into its top-level blocks
+func parseConfig(config string) (*parsedConfig, error) {
+	file, diags := hclsyntax.ParseConfig([]byte(config), "main.tf", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse HCL: %s", diags.Error())
+	}
+
+	parsed := &parsedConfig{source: file.Bytes}
+	for _, b := range file.Body.(*hclsyntax.Body).Blocks {
+		top := &block{
+			Kind:  b.Type,
+			Body:  b.Body,
+			Range: b.DefRange(),
+		}
+		if len(b.Labels) > 0 {
+			top.Type = b.Labels[0]
+		}
+		if len(b.Labels) > 1 {
+			top.Name = b.Labels[1]
+		}
+		parsed.blocks = append(parsed.blocks, top)
+	}
+
+	return parsed, nil
+}
+
+// Address returns the Terraform address of the block, e.g. aws_s3_bucket.logs
+func (b *block) Address() string {
+	switch b.Kind {
+	case "resource":
+		return b.Type + "." + b.Name
+	case "data":
+		return "data." + b.Type + "." + b.Name
+	case "variable":
+		return "var." + b.Type
+	case "module", "provider", "output":
+		return b.Kind + "." + b.Type
+	default:
+		return b.Kind
+	}
+}
+
+// provider returns the provider of a resource or data block, taken from
+// the prefix of its type (aws_s3_bucket is an aws resource)
+func (b *block) provider() string {
+	if b.Kind != "resource" && b.Kind != "data" {
+		return ""
+	}
+	if i := strings.Index(b.Type, "_"); i > 0 {
+		return b.Type[:i]
+	}
+	return ""
+}
+
+// resources returns the resource blocks of any of the given types, or all
+// resource blocks when no types are given
+func (c *parsedConfig) resources(types ...string) []*block {
+	var result []*block
+	for _, b := range c.blocks {
+		if b.Kind == "resource" && (len(types) == 0 || contains(types, b.Type)) {
+			result = append(result, b)
+		}
+	}
+	return result
+}
+
+// providers returns the Terraform providers the configuration uses: those
+// with a provider block or a required_providers entry, and those whose
+// resources or data sources it declares. google-beta counts as google.
+func (c *parsedConfig) providers() map[string]bool {
+	providers := make(map[string]bool)
+	add := func(name string) {
+		providers[strings.TrimSuffix(name, "-beta")] = true
+	}
+
+	for _, b := range c.blocks {
+		switch b.Kind {
+		case "provider":
+			add(b.Type)
+		case "resource", "data":
+			if provider := b.provider(); provider != "" {
+				add(provider)
+			}
+		case "terraform":
+			for _, required := range nestedBlocks(b.Body, "required_providers") {
+				for name := range required.Body.Attributes {
+					add(name)
+				}
+			}
+		}
+	}
+	return providers
+}
+
+// referencedBy returns the resources of the given types whose attribute
+// refers to target, either through a reference such as
+// aws_s3_bucket.logs.id or by repeating target's literal name
+// (e.g. bucket = "my-logs")
+func (c *parsedConfig) referencedBy(target *block, attribute string, types ...string) []*block {
+	targetName, _ := attributeValue(target.Body, attribute)
+	referring := make(map[*block]bool)
+	for _, b := range c.graph().dependents(target, attribute, types...) {
+		referring[b] = true
+	}
+
+	var result []*block
+	for _, b := range c.resources(types...) {
+		attr, ok := b.Body.Attributes[attribute]
+		if !ok {
+			continue
+		}
+		if referring[b] {
+			result = append(result, b)
+			continue
+		}
+		if value, ok := literalString(attr.Expr); ok && targetName != "" && value == targetName {
+			result = append(result, b)
+		}
+	}
+	return result
+}
+
+// nestedBlocks returns the blocks of the given type directly inside body
+func nestedBlocks(body *hclsyntax.Body, blockType string) []*hclsyntax.Block {
+	var result []*hclsyntax.Block
+	for _, b := range body.Blocks {
+		if b.Type == blockType {
+			result = append(result, b)
+		}
+	}
+	return result
+}
+
+// literalValue evaluates expr without variables or functions; ok is false
+// for expressions that depend on anything but literals
+func literalValue(expr hclsyntax.Expression) (cty.Value, bool) {
+	value, diags := expr.Value(nil)
+	if diags.HasErrors() || !value.IsWhollyKnown() || value.IsNull() {
+		return cty.NilVal, false
+	}
+	return value, true
+}
+
+// literalString returns a literal primitive value (string, number or bool) as a string
+func literalString(expr hclsyntax.Expression) (string, bool) {
+	value, ok := literalValue(expr)
+	if !ok || !value.Type().IsPrimitiveType() {
+		return "", false
+	}
+	converted, err := convert.Convert(value, cty.String)
+	if err != nil {
+		return "", false
+	}
+	return converted.AsString(), true
+}
+
+// literalStrings returns the primitive values of a literal value or list,
+// e.g. ["0.0.0.0/0"] or "0.0.0.0/0"
+func literalStrings(expr hclsyntax.Expression) ([]string, bool) {
+	value, ok := literalValue(expr)
+	if !ok {
+		return nil, false
+	}
+	return ctyStrings(value)
+}
+
+// ctyStrings converts a primitive value, or a list, tuple or set of them, to strings
+func ctyStrings(value cty.Value) ([]string, bool) {
+	if value.Type().IsPrimitiveType() {
+		converted, err := convert.Convert(value, cty.String)
+		if err != nil {
+			return nil, false
+		}
+		return []string{converted.AsString()}, true
+	}
+
+	if !(value.Type().IsListType() || value.Type().IsTupleType() || value.Type().IsSetType()) {
+		return nil, false
+	}
+
+	var values []string
+	for it := value.ElementIterator(); it.Next(); {
+		_, element := it.Element()
+		converted, err := convert.Convert(element, cty.String)
+		if err != nil || converted.IsNull() {
+			return nil, false
+		}
+		values = append(values, converted.AsString())
+	}
+	return values, true
+}
+
+// attributeValue returns the literal value of an attribute in body as a
+// string; ok is false if the attribute is missing or not a literal
+func attributeValue(body *hclsyntax.Body, name string) (string, bool) {
+	attr, ok := body.Attributes[name]
+	if !ok {
+		return "", false
+	}
+	return literalString(attr.Expr)
+}
+
+// notEnabled reports whether a boolean setting is missing or literally not
+// true. Settings computed from variables or other resources are given the
+// benefit of the doubt.
+func notEnabled(body *hclsyntax.Body, name string) bool {
+	attr, ok := body.Attributes[name]
+	if !ok {
+		return true
+	}
+	value, ok := literalString(attr.Expr)
+	return ok && value != "true"
+}
+
+// attributeRange returns the range of the named attribute, or fallback if
+// it is missing
+func attributeRange(body *hclsyntax.Body, name string, fallback hcl.Range) hcl.Range {
+	if attr, ok := body.Attributes[name]; ok {
+		return attr.SrcRange
+	}
+	return fallback
+}
+
+// sourceText returns the configuration text covered by rng
+func (c *parsedConfig) sourceText(rng hcl.Range) string {
+	if rng.Start.Byte < 0 || rng.End.Byte > len(c.source) || rng.Start.Byte > rng.End.Byte {
+		return ""
+	}
+	return string(c.source[rng.Start.Byte:rng.End.Byte])
+}
+
+// walkAttributes calls fn for every attribute in body and its nested blocks
+func walkAttributes(body *hclsyntax.Body, fn func(attr *hclsyntax.Attribute)) {
+	for _, attr := range sortedAttributes(body) {
+		fn(attr)
+	}
+	for _, nested := range body.Blocks {
+		walkAttributes(nested.Body, fn)
+	}
+}
+
+// sortedAttributes returns the attributes of body in source order
+func sortedAttributes(body *hclsyntax.Body) []*hclsyntax.Attribute {
+	attrs := make([]*hclsyntax.Attribute, 0, len(body.Attributes))
+	for _, attr := range body.Attributes {
+		attrs = append(attrs, attr)
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].SrcRange.Start.Byte < attrs[j].SrcRange.Start.Byte
+	})
+	return attrs
+}
+
+// hasSuffixAny reports whether s ends with any of the suffixes
+func hasSuffixAny(s string, suffixes ...string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
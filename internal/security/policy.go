@@ -0,0 +1,295 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/open-policy-agent/opa/rego"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// PolicyNamespace is the package prefix of Rego policies. Every package
+// below it (e.g. package tfnlp.s3) may define deny and warn rules.
+const PolicyNamespace = "tfnlp"
+
+// Default severities of policy results that do not set one
+const (
+	policyDenySeverity = "HIGH"
+	policyWarnSeverity = "LOW"
+)
+
+// policyEngine evaluates Rego policies against configurations
+type policyEngine struct {
+	query rego.PreparedEvalQuery
+}
+
+// policyResult is a deny or warn result given as an object instead of a
+// plain message
+type policyResult struct {
+	Msg         string `json:"msg"`
+	Resource    string `json:"resource"`
+	Rule        string `json:"rule"`
+	Severity    string `json:"severity"`
+	Remediation string `json:"remediation"`
+}
+
+// LoadPolicies compiles the .rego files (and JSON or YAML data files) found
+// at paths, which may be files or bundle directories, and makes Scan
+// evaluate them. Rules in packages below PolicyNamespace produce issues:
+// deny results at HIGH severity and warn results at LOW, unless a result
+// object sets "severity". A result is either a message or an object with
+// "msg" and optionally "resource", "rule", "severity" and "remediation".
+func (s *Scanner) LoadPolicies(paths ...string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	query, err := rego.New(
+		rego.Query("data."+PolicyNamespace),
+		rego.Load(paths, nil),
+	).PrepareForEval(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to compile policies: %w", err)
+	}
+
+	s.policies = &policyEngine{query: query}
+	return nil
+}
+
+// evaluate runs the policies against a parsed configuration
+func (p *policyEngine) evaluate(c *parsedConfig) ([]Issue, error) {
+	input, err := c.document()
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := p.query.Eval(context.Background(), rego.EvalInput(input))
+	if err != nil {
+		return nil, fmt.Errorf("policy evaluation failed: %w", err)
+	}
+
+	var issues []Issue
+	for _, result := range results {
+		for _, expression := range result.Expressions {
+			packages, ok := expression.Value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			found, err := policyIssues(PolicyNamespace, packages)
+			if err != nil {
+				return nil, err
+			}
+			issues = append(issues, found...)
+		}
+	}
+
+	// Point issues at the resources they name
+	addresses := make(map[string]*block)
+	for _, b := range c.blocks {
+		addresses[b.Address()] = b
+	}
+	for i := range issues {
+		if b, ok := addresses[issues[i].Resource]; ok {
+			issues[i].Line = b.Range.Start.Line
+			issues[i].Column = b.Range.Start.Column
+		}
+	}
+
+	return issues, nil
+}
+
+// policyIssues collects the deny and warn results of a package and the
+// packages below it, in package order
+func policyIssues(pkg string, value map[string]interface{}) ([]Issue, error) {
+	var issues []Issue
+
+	for _, kind := range []string{"deny", "warn"} {
+		results, ok := value[kind]
+		if !ok {
+			continue
+		}
+		severity := policyDenySeverity
+		if kind == "warn" {
+			severity = policyWarnSeverity
+		}
+
+		entries, ok := results.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("policy %s: %s must be a set of messages or objects", pkg, kind)
+		}
+		for _, entry := range entries {
+			issue, err := policyIssue(pkg, severity, entry)
+			if err != nil {
+				return nil, fmt.Errorf("policy %s: %s: %w", pkg, kind, err)
+			}
+			issues = append(issues, issue)
+		}
+	}
+
+	names := make([]string, 0, len(value))
+	for name, nested := range value {
+		if _, ok := nested.(map[string]interface{}); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		nested, err := policyIssues(pkg+"."+name, value[name].(map[string]interface{}))
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, nested...)
+	}
+
+	return issues, nil
+}
+
+// policyIssue converts one deny or warn result to an issue
+func policyIssue(pkg, severity string, entry interface{}) (Issue, error) {
+	issue := Issue{Severity: severity, Rule: pkg}
+
+	switch entry := entry.(type) {
+	case string:
+		issue.Message = entry
+
+	case map[string]interface{}:
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return Issue{}, err
+		}
+		var result policyResult
+		if err := json.Unmarshal(data, &result); err != nil {
+			return Issue{}, fmt.Errorf("invalid result %s: %w", data, err)
+		}
+		if result.Msg == "" {
+			return Issue{}, fmt.Errorf("result %s has no msg", data)
+		}
+		issue.Message = result.Msg
+		issue.Resource = result.Resource
+		issue.Remediation = result.Remediation
+		if result.Rule != "" {
+			issue.Rule = result.Rule
+		}
+		if result.Severity != "" {
+			issue.Severity = strings.ToUpper(result.Severity)
+			if !contains(severities, issue.Severity) {
+				return Issue{}, fmt.Errorf("invalid severity %q (use %s)", result.Severity, strings.Join(severities, ", "))
+			}
+		}
+
+	default:
+		return Issue{}, fmt.Errorf("result must be a message or an object, got %v", entry)
+	}
+
+	return issue, nil
+}
+
+// document converts the configuration into the policy input: blocks keyed
+// like Terraform's JSON syntax (resource.aws_s3_bucket.logs), plus a flat
+// "resources" list with each resource's address, type, name and config.
+// Literal values keep their type; other expressions become "${...}"
+// strings of their source, e.g. "${var.bucket_name}".
+func (c *parsedConfig) document() (map[string]interface{}, error) {
+	document := make(map[string]interface{})
+	var resources []interface{}
+
+	for _, b := range c.blocks {
+		body, err := c.bodyDocument(b.Body)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", b.Address(), err)
+		}
+
+		switch {
+		case b.Kind == "resource" || b.Kind == "data":
+			kind := nestedMap(document, b.Kind)
+			nestedMap(kind, b.Type)[b.Name] = body
+			if b.Kind == "resource" {
+				resources = append(resources, map[string]interface{}{
+					"address": b.Address(),
+					"type":    b.Type,
+					"name":    b.Name,
+					"config":  body,
+				})
+			}
+		case b.Kind == "provider":
+			providers, _ := document["provider"].(map[string]interface{})
+			if providers == nil {
+				providers = make(map[string]interface{})
+				document["provider"] = providers
+			}
+			list, _ := providers[b.Type].([]interface{})
+			providers[b.Type] = append(list, body)
+		case b.Type != "":
+			nestedMap(document, b.Kind)[b.Type] = body
+		default:
+			// Unlabeled blocks such as terraform and locals are merged
+			kind := nestedMap(document, b.Kind)
+			for key, value := range body {
+				kind[key] = value
+			}
+		}
+	}
+
+	if resources == nil {
+		resources = []interface{}{}
+	}
+	document["resources"] = resources
+	return document, nil
+}
+
+// bodyDocument converts a block body: attributes to values and nested
+// blocks to lists of objects
+func (c *parsedConfig) bodyDocument(body *hclsyntax.Body) (map[string]interface{}, error) {
+	document := make(map[string]interface{})
+
+	for name, attr := range body.Attributes {
+		value, err := c.expressionDocument(attr.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		document[name] = value
+	}
+
+	for _, nested := range body.Blocks {
+		value, err := c.bodyDocument(nested.Body)
+		if err != nil {
+			return nil, err
+		}
+		list, _ := document[nested.Type].([]interface{})
+		document[nested.Type] = append(list, value)
+	}
+
+	return document, nil
+}
+
+// expressionDocument converts an expression to a JSON value
+func (c *parsedConfig) expressionDocument(expr hclsyntax.Expression) (interface{}, error) {
+	value, ok := literalValue(expr)
+	if !ok {
+		return "${" + strings.TrimSpace(c.sourceText(expr.Range())) + "}", nil
+	}
+
+	data, err := ctyjson.SimpleJSONValue{Value: value}.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// nestedMap returns the object stored under key, creating it if needed
+func nestedMap(parent map[string]interface{}, key string) map[string]interface{} {
+	if child, ok := parent[key].(map[string]interface{}); ok {
+		return child
+	}
+	child := make(map[string]interface{})
+	parent[key] = child
+	return child
+}
@@ -0,0 +1,169 @@
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePolicy writes a Rego policy to a temporary directory
+func writePolicy(t *testing.T, policy string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.rego")
+	if err := os.WriteFile(path, []byte(policy), 0644); err != nil {
+		t.Fatalf("failed to write policy: %v", err)
+	}
+	return path
+}
+
+func TestLoadPoliciesExample(t *testing.T) {
+	scanner := NewScanner()
+	if err := scanner.LoadPolicies("../../examples/policies"); err != nil {
+		t.Fatalf("LoadPolicies() error = %v", err)
+	}
+
+	config := `resource "aws_s3_bucket" "tagged" {
+  bucket        = "tagged"
+  force_destroy = false
+
+  tags = {
+    DataClass = "internal"
+  }
+}
+
+resource "aws_s3_bucket" "untagged" {
+  bucket = "untagged"
+}
+`
+	issues := scan(t, scanner, config).Issues
+
+	var deny, warn []Issue
+	for _, issue := range issues {
+		switch issue.Rule {
+		case "POL-S3-001":
+			deny = append(deny, issue)
+		case "tfnlp.s3":
+			warn = append(warn, issue)
+		}
+	}
+
+	if len(deny) != 1 {
+		t.Fatalf("deny issues = %+v, want one for aws_s3_bucket.untagged", deny)
+	}
+	want := Issue{
+		Severity:    "HIGH",
+		Message:     "aws_s3_bucket.untagged has no DataClass tag",
+		Resource:    "aws_s3_bucket.untagged",
+		Line:        10,
+		Column:      1,
+		Rule:        "POL-S3-001",
+		Remediation: "Tag every bucket with the classification of the data it holds",
+	}
+	if deny[0] != want {
+		t.Errorf("deny issue = %+v, want %+v", deny[0], want)
+	}
+
+	if len(warn) != 1 || warn[0].Severity != "LOW" || warn[0].Message != "aws_s3_bucket.untagged does not set force_destroy = false" {
+		t.Errorf("warn issues = %+v, want one LOW issue for aws_s3_bucket.untagged", warn)
+	}
+	if warn[0].Resource != "" || warn[0].Line != 0 {
+		t.Errorf("warn issue = %+v, want no resource for a plain message", warn[0])
+	}
+}
+
+func TestPolicyResults(t *testing.T) {
+	config := `resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+}
+`
+
+	tests := []struct {
+		name    string
+		policy  string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "severity set by the result",
+			policy: `package tfnlp.naming
+import future.keywords.contains
+import future.keywords.if
+deny contains {"msg": "bucket name too short", "severity": "medium", "resource": "aws_s3_bucket.logs"} if true`,
+			want: []string{"tfnlp.naming MEDIUM aws_s3_bucket.logs bucket name too short"},
+		},
+		{
+			name: "nested packages",
+			policy: `package tfnlp.team.storage
+import future.keywords.contains
+import future.keywords.if
+warn contains "review storage" if true`,
+			want: []string{"tfnlp.team.storage LOW  review storage"},
+		},
+		{
+			name:   "packages outside the namespace are ignored",
+			policy: "package other\ndeny[\"ignored\"] { true }",
+		},
+		{
+			name: "invalid severity",
+			policy: `package tfnlp.naming
+import future.keywords.contains
+import future.keywords.if
+deny contains {"msg": "bad", "severity": "urgent"} if true`,
+			wantErr: `policy tfnlp.naming: deny: invalid severity "urgent"`,
+		},
+		{
+			name: "result without msg",
+			policy: `package tfnlp.naming
+import future.keywords.contains
+import future.keywords.if
+deny contains {"resource": "aws_s3_bucket.logs"} if true`,
+			wantErr: "has no msg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := NewScanner()
+			if err := scanner.LoadPolicies(writePolicy(t, tt.policy)); err != nil {
+				t.Fatalf("LoadPolicies() error = %v", err)
+			}
+
+			result, err := scanner.Scan(config)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Scan() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+
+			var got []string
+			for _, issue := range result.Issues {
+				if strings.HasPrefix(issue.Rule, PolicyNamespace) {
+					got = append(got, strings.Join([]string{issue.Rule, issue.Severity, issue.Resource, issue.Message}, " "))
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("policy issues = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPoliciesCompileError(t *testing.T) {
+	scanner := NewScanner()
+	err := scanner.LoadPolicies(writePolicy(t, "package tfnlp.broken\ndeny[msg] {\n  msg := undefined_function(input)\n}\n"))
+	if err == nil || !strings.Contains(err.Error(), "failed to compile policies") {
+		t.Fatalf("LoadPolicies() error = %v, want a compile error", err)
+	}
+	if scanner.policies != nil {
+		t.Error("LoadPolicies() kept a policy engine after a compile error")
+	}
+
+	if err := scanner.LoadPolicies(filepath.Join(t.TempDir(), "missing.rego")); err == nil {
+		t.Error("LoadPolicies() accepted a missing path")
+	}
+}
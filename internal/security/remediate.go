@@ -0,0 +1,306 @@
+package security
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Fix is a change applied to a configuration to resolve an issue
+type Fix struct {
+	Rule        string
+	Resource    string
+	Description string
+}
+
+// Remediation is a configuration rewritten to resolve the issues that have
+// an automatic fix
+type Remediation struct {
+	// Configuration is the patched file
+	Configuration string
+	// Diff is a unified diff from the original file to the patched one
+	Diff  string
+	Fixes []Fix
+	// Issues and Suppressed are the scan of the patched file, including
+	// issues without an automatic fix
+	Issues     []Issue
+	Suppressed []SuppressedIssue
+}
+
+// fixFunc rewrites block b, whose source is w, to resolve a rule and
+// describes each change; it returns nil when nothing could be changed
+type fixFunc func(r *remediation, b *block, w *hclwrite.Block) []string
+
+// remediation is a configuration being rewritten
+type remediation struct {
+	parsed *parsedConfig
+	file   *hclwrite.File
+	// names are the declared addresses, e.g. aws_s3_bucket.logs and var.region
+	names map[string]bool
+	// secrets tells credentials from placeholders and allowlisted values
+	secrets *secretDetector
+}
+
+// Remediate applies the automatic fixes of the rules violated in the file
+// named filename and returns the patched file, a diff and the fixes.
+// Suppressed issues are left alone. A patched file is also formatted, so the
+// diff includes formatting changes of a file that was not.
+func (s *Scanner) Remediate(config, filename string) (*Remediation, error) {
+	scanned, err := s.Scan(config)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	file, diags := hclwrite.ParseConfig([]byte(config), filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse configuration: %s", diags.Error())
+	}
+
+	r := &remediation{parsed: parsed, file: file, names: make(map[string]bool), secrets: s.secrets}
+	for _, b := range parsed.blocks {
+		switch b.Kind {
+		case "resource":
+			r.names[b.Type+"."+b.Name] = true
+		case "variable":
+			r.names["var."+b.Type] = true
+		}
+	}
+
+	result := &Remediation{Configuration: config}
+	fixed := make(map[string]bool)
+	for _, issue := range scanned.Issues {
+		if fixed[issue.Rule+" "+issue.Resource] {
+			continue
+		}
+		fixed[issue.Rule+" "+issue.Resource] = true
+
+		b, w := r.block(issue.Resource)
+		if b == nil {
+			continue
+		}
+		for _, rule := range s.rules {
+			if rule.ID != issue.Rule || rule.fix == nil || !rule.appliesTo(b) {
+				continue
+			}
+			for _, description := range rule.fix(r, b, w) {
+				result.Fixes = append(result.Fixes, Fix{Rule: issue.Rule, Resource: issue.Resource, Description: description})
+			}
+		}
+	}
+
+	if len(result.Fixes) == 0 {
+		result.Issues = scanned.Issues
+		result.Suppressed = scanned.Suppressed
+		return result, nil
+	}
+
+	result.Configuration = string(hclwrite.Format(file.Bytes()))
+	result.Diff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(config),
+		B:        splitLines(result.Configuration),
+		FromFile: "a/" + filename,
+		ToFile:   "b/" + filename,
+		Context:  3,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff configuration: %w", err)
+	}
+
+	rescanned, err := s.Scan(result.Configuration)
+	if err != nil {
+		return nil, fmt.Errorf("patched configuration: %w", err)
+	}
+	result.Issues = rescanned.Issues
+	result.Suppressed = rescanned.Suppressed
+
+	return result, nil
+}
+
+// splitLines splits text into lines that keep their newline
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// block returns the parsed block at address and its source
+func (r *remediation) block(address string) (*block, *hclwrite.Block) {
+	for _, b := range r.parsed.blocks {
+		if b.Address() != address {
+			continue
+		}
+		for _, w := range r.file.Body().Blocks() {
+			if labels := w.Labels(); w.Type() == b.Kind && len(labels) == 2 && labels[0] == b.Type && labels[1] == b.Name {
+				return b, w
+			}
+		}
+	}
+	return nil, nil
+}
+
+// appendBlock adds a block at the end of the file
+func (r *remediation) appendBlock(blockType string, labels ...string) *hclwrite.Block {
+	body := r.file.Body()
+	body.AppendNewline()
+	return body.AppendNewBlock(blockType, labels)
+}
+
+// freeName returns a name for a new block of kind (a resource type or
+// "var") based on name, numbered if it is taken, and reserves it
+func (r *remediation) freeName(kind, name string) string {
+	candidate := name
+	for i := 2; r.names[kind+"."+candidate]; i++ {
+		candidate = fmt.Sprintf("%s_%d", name, i)
+	}
+	r.names[kind+"."+candidate] = true
+	return candidate
+}
+
+// fixAttribute sets an attribute to a literal value
+func fixAttribute(name string, value cty.Value) fixFunc {
+	return func(r *remediation, b *block, w *hclwrite.Block) []string {
+		w.Body().SetAttributeValue(name, value)
+		return []string{fmt.Sprintf("set %s = %s", name, strings.TrimSpace(string(hclwrite.TokensForValue(value).Bytes())))}
+	}
+}
+
+// fixStorageEncryption enables encryption at rest with the setting of the
+// storage type
+func fixStorageEncryption(r *remediation, b *block, w *hclwrite.Block) []string {
+	return fixAttribute(storageEncryption[b.Type], cty.True)(r, b, w)
+}
+
+// fixVolumeEncryption encrypts an EBS volume, or the unencrypted block
+// devices of an instance
+func fixVolumeEncryption(r *remediation, b *block, w *hclwrite.Block) []string {
+	if b.Type == "aws_ebs_volume" {
+		return fixAttribute("encrypted", cty.True)(r, b, w)
+	}
+
+	nested := w.Body().Blocks()
+	if len(nested) != len(b.Body.Blocks) {
+		return nil
+	}
+	var fixes []string
+	for i, device := range b.Body.Blocks {
+		if (device.Type == "root_block_device" || device.Type == "ebs_block_device") && notEnabled(device.Body, "encrypted") {
+			nested[i].Body().SetAttributeValue("encrypted", cty.True)
+			fixes = append(fixes, fmt.Sprintf("set %s.encrypted = true", device.Type))
+		}
+	}
+	return fixes
+}
+
+// fixHardcodedSecrets replaces literal credentials with sensitive variables
+// declared at the end of the file. The values are dropped from the file and
+// have to be supplied when applying, e.g. as TF_VAR_ environment variables.
+// Tokens inside other strings, such as a key in a user_data script, are left
+// for the author and stay reported.
+func fixHardcodedSecrets(r *remediation, b *block, w *hclwrite.Block) []string {
+	return r.replaceSecrets(b, b.Body, w.Body(), nil)
+}
+
+// replaceSecrets replaces the literal credentials in body, found at path
+// below block b, and in its nested blocks
+func (r *remediation) replaceSecrets(b *block, body *hclsyntax.Body, w *hclwrite.Body, path []string) []string {
+	var fixes []string
+
+	for _, attr := range sortedAttributes(body) {
+		if !r.secrets.credential(attr.Name, attr.Expr) {
+			continue
+		}
+
+		attrPath := append(append([]string{}, path...), attr.Name)
+		name := r.freeName("var", b.Name+"_"+strings.Join(attrPath, "_"))
+		w.SetAttributeTraversal(attr.Name, hcl.Traversal{hcl.TraverseRoot{Name: "var"}, hcl.TraverseAttr{Name: name}})
+
+		variable := r.appendBlock("variable", name).Body()
+		variable.SetAttributeValue("description", cty.StringVal(fmt.Sprintf("%s of %s", strings.Join(attrPath, "."), b.Address())))
+		variable.SetAttributeTraversal("type", hcl.Traversal{hcl.TraverseRoot{Name: "string"}})
+		variable.SetAttributeValue("sensitive", cty.True)
+
+		fixes = append(fixes, fmt.Sprintf("replaced literal %s with sensitive variable var.%s", strings.Join(attrPath, "."), name))
+	}
+
+	nested := w.Blocks()
+	if len(nested) != len(body.Blocks) {
+		return fixes
+	}
+	for i, child := range body.Blocks {
+		fixes = append(fixes, r.replaceSecrets(b, child.Body, nested[i].Body(), append(append([]string{}, path...), child.Type))...)
+	}
+	return fixes
+}
+
+// fixBucketEncryption adds an aws_s3_bucket_server_side_encryption_configuration
+// with SSE-S3 default encryption for the bucket
+func fixBucketEncryption(r *remediation, b *block, w *hclwrite.Block) []string {
+	resourceType := "aws_s3_bucket_server_side_encryption_configuration"
+	name := r.freeName(resourceType, b.Name)
+
+	body := r.appendBlock("resource", resourceType, name).Body()
+	body.SetAttributeTraversal("bucket", hcl.Traversal{hcl.TraverseRoot{Name: b.Type}, hcl.TraverseAttr{Name: b.Name}, hcl.TraverseAttr{Name: "id"}})
+	rule := body.AppendNewBlock("rule", nil).Body()
+	rule.AppendNewBlock("apply_server_side_encryption_by_default", nil).Body().SetAttributeValue("sse_algorithm", cty.StringVal("AES256"))
+
+	return []string{fmt.Sprintf("added %s.%s with AES256 default encryption", resourceType, name)}
+}
+
+// fixBucketVersioning adds an aws_s3_bucket_versioning for buckets without
+// any versioning configuration. Versioning that is configured but suspended
+// is left to the author.
+func fixBucketVersioning(r *remediation, b *block, w *hclwrite.Block) []string {
+	if len(nestedBlocks(b.Body, "versioning")) > 0 || len(r.parsed.referencedBy(b, "bucket", "aws_s3_bucket_versioning")) > 0 {
+		return nil
+	}
+
+	resourceType := "aws_s3_bucket_versioning"
+	name := r.freeName(resourceType, b.Name)
+
+	body := r.appendBlock("resource", resourceType, name).Body()
+	body.SetAttributeTraversal("bucket", hcl.Traversal{hcl.TraverseRoot{Name: b.Type}, hcl.TraverseAttr{Name: b.Name}, hcl.TraverseAttr{Name: "id"}})
+	body.AppendNewBlock("versioning_configuration", nil).Body().SetAttributeValue("status", cty.StringVal("Enabled"))
+
+	return []string{fmt.Sprintf("added %s.%s with versioning enabled", resourceType, name)}
+}
+
+// fixBucketPublicAccessBlock enables the settings of the bucket's public
+// access block, or adds one with all settings enabled
+func fixBucketPublicAccessBlock(r *remediation, b *block, w *hclwrite.Block) []string {
+	for _, access := range r.parsed.referencedBy(b, "bucket", "aws_s3_bucket_public_access_block") {
+		_, accessBlock := r.block(access.Address())
+		if accessBlock == nil {
+			continue
+		}
+		var fixes []string
+		for _, setting := range publicAccessSettings {
+			if notEnabled(access.Body, setting) {
+				accessBlock.Body().SetAttributeValue(setting, cty.True)
+				fixes = append(fixes, fmt.Sprintf("set %s.%s = true", access.Address(), setting))
+			}
+		}
+		return fixes
+	}
+
+	resourceType := "aws_s3_bucket_public_access_block"
+	name := r.freeName(resourceType, b.Name)
+
+	body := r.appendBlock("resource", resourceType, name).Body()
+	body.SetAttributeTraversal("bucket", hcl.Traversal{hcl.TraverseRoot{Name: b.Type}, hcl.TraverseAttr{Name: b.Name}, hcl.TraverseAttr{Name: "id"}})
+	for _, setting := range publicAccessSettings {
+		body.SetAttributeValue(setting, cty.True)
+	}
+
+	return []string{fmt.Sprintf("added %s.%s blocking all public access", resourceType, name)}
+}
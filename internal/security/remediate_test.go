@@ -0,0 +1,297 @@
+package security
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// remediate runs Remediate over config and fails the test on an error
+func remediate(t *testing.T, config string) *Remediation {
+	t.Helper()
+	result, err := NewScanner().Remediate(config, "main.tf")
+	if err != nil {
+		t.Fatalf("Remediate() error = %v", err)
+	}
+	return result
+}
+
+// fixDescriptions returns the descriptions of the fixes of one rule
+func fixDescriptions(fixes []Fix, rule string) []string {
+	var descriptions []string
+	for _, fix := range fixes {
+		if fix.Rule == rule {
+			descriptions = append(descriptions, fix.Resource+": "+fix.Description)
+		}
+	}
+	return descriptions
+}
+
+// nestedSource returns the source of the attribute name in each nested block
+// of the first block in config, e.g. "ebs_block_device encrypted=true"
+func nestedSource(t *testing.T, config, name string) []string {
+	t.Helper()
+	parsed, err := parseConfig(config)
+	if err != nil {
+		t.Fatalf("patched configuration is invalid: %v", err)
+	}
+
+	var found []string
+	for _, nested := range parsed.blocks[0].Body.Blocks {
+		value := "unset"
+		if attr, ok := nested.Body.Attributes[name]; ok {
+			value = strings.TrimSpace(string(attr.Expr.Range().SliceBytes(parsed.source)))
+		}
+		found = append(found, nested.Type+" "+name+"="+value)
+	}
+	return found
+}
+
+func TestRemediateInterleavedBlockDevices(t *testing.T) {
+	result := remediate(t, `resource "aws_instance" "web" {
+  ami                    = "ami-0c55b159cbfafe1f0"
+  vpc_security_group_ids = ["sg-123"]
+
+  ebs_block_device {
+    device_name = "/dev/sdb"
+    encrypted   = true
+  }
+
+  metadata_options {
+    http_tokens = "required"
+  }
+
+  root_block_device {
+    volume_size = 20
+  }
+
+  ebs_block_device {
+    device_name = "/dev/sdc"
+  }
+}
+`)
+
+	wantFixes := []string{
+		"aws_instance.web: set root_block_device.encrypted = true",
+		"aws_instance.web: set ebs_block_device.encrypted = true",
+	}
+	if got := fixDescriptions(result.Fixes, "SEC004"); strings.Join(got, ",") != strings.Join(wantFixes, ",") {
+		t.Errorf("SEC004 fixes = %v, want %v", got, wantFixes)
+	}
+
+	want := []string{
+		"ebs_block_device encrypted=true",
+		"metadata_options encrypted=unset",
+		"root_block_device encrypted=true",
+		"ebs_block_device encrypted=true",
+	}
+	if got := nestedSource(t, result.Configuration, "encrypted"); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("nested blocks = %v, want %v", got, want)
+	}
+}
+
+func TestRemediateInterleavedNestedSecrets(t *testing.T) {
+	result := remediate(t, `resource "aws_mq_broker" "main" {
+  broker_name = "main"
+
+  logs {
+    general = true
+  }
+
+  user {
+    username = "admin"
+    password = "Sup3rS3cretValue!"
+  }
+
+  user {
+    username = "reader"
+    password = var.reader_password
+  }
+
+  user {
+    username = "app"
+    password = "An0therS3cretValue"
+  }
+}
+
+variable "main_user_password" {
+  type = string
+}
+`)
+
+	wantFixes := []string{
+		"aws_mq_broker.main: replaced literal user.password with sensitive variable var.main_user_password_2",
+		"aws_mq_broker.main: replaced literal user.password with sensitive variable var.main_user_password_3",
+	}
+	if got := fixDescriptions(result.Fixes, "SEC007"); strings.Join(got, ",") != strings.Join(wantFixes, ",") {
+		t.Errorf("SEC007 fixes = %v, want %v", got, wantFixes)
+	}
+
+	want := []string{
+		"logs password=unset",
+		"user password=var.main_user_password_2",
+		"user password=var.reader_password",
+		"user password=var.main_user_password_3",
+	}
+	if got := nestedSource(t, result.Configuration, "password"); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("nested blocks = %v, want %v", got, want)
+	}
+
+	for _, declaration := range []string{`variable "main_user_password_2" {`, `variable "main_user_password_3" {`, `description = "user.password of aws_mq_broker.main"`, "sensitive   = true"} {
+		if !strings.Contains(result.Configuration, declaration) {
+			t.Errorf("configuration is missing %q:\n%s", declaration, result.Configuration)
+		}
+	}
+	if strings.Contains(result.Configuration, "S3cretValue") {
+		t.Errorf("configuration still holds a secret:\n%s", result.Configuration)
+	}
+}
+
+func TestFreeName(t *testing.T) {
+	r := &remediation{names: map[string]bool{
+		"var.db_password":                 true,
+		"var.db_password_2":               true,
+		"aws_s3_bucket_versioning.logs":   true,
+		"aws_s3_bucket_versioning.logs_3": true,
+	}}
+
+	tests := []struct {
+		kind string
+		name string
+		want string
+	}{
+		{"var", "db_password", "db_password_3"},
+		{"var", "db_password", "db_password_4"},
+		{"var", "api_token", "api_token"},
+		{"var", "api_token", "api_token_2"},
+		{"aws_s3_bucket_versioning", "logs", "logs_2"},
+		{"aws_s3_bucket_versioning", "logs", "logs_4"},
+		{"aws_s3_bucket_versioning", "db_password", "db_password"},
+	}
+	for _, tt := range tests {
+		if got := r.freeName(tt.kind, tt.name); got != tt.want {
+			t.Errorf("freeName(%q, %q) = %q, want %q", tt.kind, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRemediateDiffAndRescan(t *testing.T) {
+	config := `resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+  acl    = "public-read"
+}
+
+resource "aws_s3_bucket_versioning" "logs" {
+  bucket = aws_s3_bucket.other.id
+  versioning_configuration {
+    status = "Enabled"
+  }
+}
+`
+	result := remediate(t, config)
+
+	fixedRules := make(map[string]bool)
+	for _, fix := range result.Fixes {
+		fixedRules[fix.Rule] = true
+	}
+	for _, rule := range []string{"SEC001", "SEC011", "SEC012", "SEC017"} {
+		if !fixedRules[rule] {
+			t.Errorf("fixes = %+v, want a fix for %s", result.Fixes, rule)
+		}
+	}
+	for _, issue := range result.Issues {
+		if fixedRules[issue.Rule] && issue.Resource == "aws_s3_bucket.logs" {
+			t.Errorf("%s still reported after its fix: %+v", issue.Rule, issue)
+		}
+	}
+
+	// The versioning resource of another bucket keeps its name
+	for _, want := range []string{
+		`resource "aws_s3_bucket_versioning" "logs_2" {`,
+		`resource "aws_s3_bucket_server_side_encryption_configuration" "logs" {`,
+		`resource "aws_s3_bucket_public_access_block" "logs" {`,
+	} {
+		if !strings.Contains(result.Configuration, want) {
+			t.Errorf("configuration is missing %q:\n%s", want, result.Configuration)
+		}
+	}
+
+	for _, want := range []string{
+		"--- a/main.tf\n+++ b/main.tf\n@@ -1,6 +1,6 @@\n",
+		"-  acl    = \"public-read\"\n+  acl    = \"private\"\n",
+		"+resource \"aws_s3_bucket_versioning\" \"logs_2\" {\n",
+	} {
+		if !strings.Contains(result.Diff, want) {
+			t.Errorf("diff is missing %q:\n%s", want, result.Diff)
+		}
+	}
+	if _, err := parseConfig(result.Configuration); err != nil {
+		t.Errorf("patched configuration is invalid: %v", err)
+	}
+}
+
+func TestRemediateFormatsPatchedFile(t *testing.T) {
+	config := `variable "region" {
+  type = string
+  default   = "us-east-1"
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+  acl = "public-read"
+}
+`
+	result := remediate(t, config)
+
+	// Blocks without fixes are formatted too
+	if !strings.Contains(result.Configuration, "  type    = string\n  default = \"us-east-1\"\n") {
+		t.Errorf("configuration is not formatted:\n%s", result.Configuration)
+	}
+	if formatted := string(hclwrite.Format([]byte(result.Configuration))); formatted != result.Configuration {
+		t.Errorf("configuration = %q, want it formatted as %q", result.Configuration, formatted)
+	}
+	if want := "-  acl = \"public-read\"\n+  acl    = \"private\"\n"; !strings.Contains(result.Diff, want) {
+		t.Errorf("diff is missing %q:\n%s", want, result.Diff)
+	}
+}
+
+func TestRemediateWithoutFixes(t *testing.T) {
+	config := `# tfnlp:ignore SEC001 reason="public website"
+resource "aws_s3_bucket" "site" {
+  acl = "public-read"
+}
+
+resource "aws_s3_bucket_server_side_encryption_configuration" "site" {
+  bucket = aws_s3_bucket.site.id
+  rule {
+    apply_server_side_encryption_by_default {
+      sse_algorithm = "AES256"
+    }
+  }
+}
+
+resource "aws_s3_bucket_versioning" "site" {
+  bucket = aws_s3_bucket.site.id
+  versioning_configuration {
+    status = "Enabled"
+  }
+}
+
+resource "aws_s3_bucket_public_access_block" "site" {
+  bucket                  = aws_s3_bucket.site.id
+  block_public_acls       = true
+  block_public_policy     = true
+  ignore_public_acls      = true
+  restrict_public_buckets = true
+}
+`
+	result := remediate(t, config)
+
+	if len(result.Fixes) != 0 || result.Diff != "" || result.Configuration != config {
+		t.Errorf("Remediate() = %d fixes and diff %q, want the configuration unchanged", len(result.Fixes), result.Diff)
+	}
+	if got := suppressed(&ScanResult{Suppressed: result.Suppressed}, "SEC001"); strings.Join(got, ",") != "aws_s3_bucket.site 3:3" {
+		t.Errorf("suppressed SEC001 = %v, want the suppressed issue kept", got)
+	}
+}
@@ -0,0 +1,180 @@
+package security
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/RyanSStephens/TF-NLP-Agent/internal/nlp"
+)
+
+// CheckRequirements verifies that the configuration satisfies the typed
+// requirements parsed from the description, e.g. that a database requested
+// as encrypted has storage_encrypted = true. Requirements expanded from a
+// compliance framework are left to CheckCompliance.
+func (s *Scanner) CheckRequirements(config string, requirements []nlp.Requirement) []Issue {
+	parsed, err := parseConfig(config)
+	if err != nil {
+		return nil
+	}
+
+	var issues []Issue
+	for _, requirement := range requirements {
+		// Framework controls are enforced as a rule set by CheckCompliance
+		if requirement.Framework != "" {
+			continue
+		}
+
+		switch requirement.Kind {
+		case nlp.KindEncryptionAtRest:
+			issues = append(issues, checkEncryptionAtRest(parsed, requirement)...)
+
+		case nlp.KindEncryptionInTransit:
+			for _, block := range parsed.resources("aws_lb_listener", "aws_alb_listener") {
+				if protocol, _ := attributeValue(block.Body, "protocol"); protocol == "HTTP" && !redirects(block.Body) {
+					issues = append(issues, requirementIssue("REQ002", "HIGH", block, requirement,
+						"Listener accepts plain HTTP although encryption in transit was requested",
+						"Use protocol = \"HTTPS\" with a certificate, or redirect HTTP to HTTPS"))
+				}
+			}
+
+		case nlp.KindPrivateAccess:
+			for _, block := range parsed.resources("aws_db_instance", "aws_rds_cluster_instance") {
+				if public, _ := attributeValue(block.Body, "publicly_accessible"); public == "true" {
+					issues = append(issues, requirementIssue("REQ003", "HIGH", block, requirement,
+						"Database is publicly accessible although private access was requested",
+						"Set publicly_accessible = false"))
+				}
+			}
+
+		case nlp.KindMultiAZ, nlp.KindHighAvailability:
+			if requirement.Target != "database" {
+				continue
+			}
+			for _, block := range parsed.resources("aws_db_instance") {
+				if notEnabled(block.Body, "multi_az") {
+					issues = append(issues, requirementIssue("REQ004", "MEDIUM", block, requirement,
+						"Database is not deployed across availability zones as requested",
+						"Set multi_az = true"))
+				}
+			}
+
+		case nlp.KindMinInstances:
+			if requirement.Target == "compute" {
+				issues = append(issues, checkMinInstances(parsed, requirement)...)
+			}
+		}
+	}
+
+	return issues
+}
+
+// checkEncryptionAtRest verifies encryption on the resources the requirement targets
+func checkEncryptionAtRest(parsed *parsedConfig, requirement nlp.Requirement) []Issue {
+	var issues []Issue
+
+	switch requirement.Target {
+	case "database":
+		for _, block := range parsed.resources("aws_db_instance", "aws_rds_cluster") {
+			if notEnabled(block.Body, "storage_encrypted") {
+				issues = append(issues, requirementIssue("REQ001", "HIGH", block, requirement,
+					"Database storage is not encrypted although encryption at rest was requested",
+					"Set storage_encrypted = true"))
+			}
+		}
+
+	case "storage":
+		for _, block := range parsed.resources("aws_s3_bucket") {
+			if !bucketEncrypted(parsed, block) {
+				issues = append(issues, requirementIssue("REQ001", "HIGH", block, requirement,
+					"Bucket has no server-side encryption although encryption at rest was requested",
+					"Add an aws_s3_bucket_server_side_encryption_configuration for the bucket"))
+			}
+		}
+
+	case "compute":
+		for _, block := range parsed.resources("aws_instance", "aws_ebs_volume") {
+			if volumeEncrypted(block) {
+				continue
+			}
+			issues = append(issues, requirementIssue("REQ001", "HIGH", block, requirement,
+				"Volume is not encrypted although encryption at rest was requested",
+				"Set encrypted = true on the volume or root_block_device"))
+		}
+	}
+
+	return issues
+}
+
+// checkMinInstances verifies that enough compute capacity is declared
+func checkMinInstances(parsed *parsedConfig, requirement nlp.Requirement) []Issue {
+	want, err := strconv.Atoi(requirement.Value)
+	if err != nil {
+		return nil
+	}
+
+	groups := parsed.resources("aws_autoscaling_group")
+	for _, block := range groups {
+		value, _ := attributeValue(block.Body, "min_size")
+		if minSize, err := strconv.Atoi(value); err == nil && minSize < want {
+			return []Issue{requirementIssue("REQ005", "MEDIUM", block, requirement,
+				fmt.Sprintf("Auto scaling group allows %d instances but at least %d were requested", minSize, want),
+				fmt.Sprintf("Set min_size = %d", want))}
+		}
+	}
+	if len(groups) > 0 {
+		return nil
+	}
+
+	instances := 0
+	for _, block := range parsed.resources("aws_instance") {
+		value, _ := attributeValue(block.Body, "count")
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			count = 1
+		}
+		instances += count
+	}
+	if instances > 0 && instances < want {
+		return []Issue{{
+			Severity:    "MEDIUM",
+			Message:     fmt.Sprintf("Configuration declares %d instances but at least %d were requested", instances, want),
+			Rule:        "REQ005",
+			Remediation: fmt.Sprintf("Set count = %d or use an auto scaling group with min_size = %d", want, want),
+		}}
+	}
+
+	return nil
+}
+
+// requirementIssue builds an issue for a requirement the block does not satisfy
+func requirementIssue(rule, severity string, block *block, requirement nlp.Requirement, message, remediation string) Issue {
+	return Issue{
+		Severity:    severity,
+		Message:     fmt.Sprintf("%s (requirement: %s)", message, requirement.Constraint()),
+		Resource:    block.Address(),
+		Line:        block.Range.Start.Line,
+		Column:      block.Range.Start.Column,
+		Rule:        rule,
+		Remediation: remediation,
+	}
+}
+
+// volumeEncrypted reports whether an EBS volume, or every block device
+// declared on an instance, is encrypted
+func volumeEncrypted(block *block) bool {
+	if block.Type == "aws_ebs_volume" {
+		return !notEnabled(block.Body, "encrypted")
+	}
+
+	devices := 0
+	for _, device := range block.Body.Blocks {
+		if device.Type != "root_block_device" && device.Type != "ebs_block_device" {
+			continue
+		}
+		devices++
+		if notEnabled(device.Body, "encrypted") {
+			return false
+		}
+	}
+	return devices > 0
+}
@@ -0,0 +1,249 @@
+package security
+
+import (
+	"encoding/json"
+	"path/filepath"
+)
+
+// SARIF 2.1.0 schema and version written by SARIF
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// sarifLevels maps issue severities to SARIF result levels
+var sarifLevels = map[string]string{
+	"CRITICAL": "error",
+	"HIGH":     "error",
+	"MEDIUM":   "warning",
+	"LOW":      "note",
+}
+
+// sarifSecuritySeverities are the scores code scanning dashboards use to
+// rank security results
+var sarifSecuritySeverities = map[string]string{
+	"CRITICAL": "9.5",
+	"HIGH":     "8.0",
+	"MEDIUM":   "5.5",
+	"LOW":      "2.0",
+}
+
+// SARIFLog is a SARIF 2.1.0 log with a single run
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun is the output of one scan
+type SARIFRun struct {
+	Tool    SARIFTool     `json:"tool"`
+	Results []SARIFResult `json:"results"`
+}
+
+// SARIFTool describes the scanner and its rules
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver is the tool component that produced the results
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+// SARIFRule is the metadata of a rule
+type SARIFRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name,omitempty"`
+	ShortDescription     SARIFMessage       `json:"shortDescription"`
+	Help                 *SARIFMessage      `json:"help,omitempty"`
+	DefaultConfiguration SARIFConfiguration `json:"defaultConfiguration"`
+	Properties           SARIFProperties    `json:"properties"`
+}
+
+// SARIFConfiguration holds a rule's default level
+type SARIFConfiguration struct {
+	Level string `json:"level"`
+}
+
+// SARIFProperties are the rule properties read by code scanning dashboards
+type SARIFProperties struct {
+	Tags             []string `json:"tags"`
+	SecuritySeverity string   `json:"security-severity,omitempty"`
+}
+
+// SARIFMessage is a plain text message
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFResult is one issue
+type SARIFResult struct {
+	RuleID       string             `json:"ruleId"`
+	RuleIndex    int                `json:"ruleIndex"`
+	Level        string             `json:"level"`
+	Message      SARIFMessage       `json:"message"`
+	Locations    []SARIFLocation    `json:"locations"`
+	Suppressions []SARIFSuppression `json:"suppressions,omitempty"`
+}
+
+// SARIFSuppression records why a result is not reported as an issue
+type SARIFSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// SARIFLocation is where an issue was found
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []SARIFLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+// SARIFPhysicalLocation is a file and region
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Region           *SARIFRegion          `json:"region,omitempty"`
+}
+
+// SARIFArtifactLocation is a file URI relative to the repository root
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// SARIFRegion is the start of an issue within a file
+type SARIFRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// SARIFLogicalLocation is the resource an issue was found in
+type SARIFLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// SARIF converts the issues of a scan of the file at path to a SARIF 2.1.0
+// log. Rule metadata comes from the scanner's rules; issues from
+// requirement, compliance and policy checks describe their own rule.
+// Suppressed issues are included with an inSource (comment) or external
+// (baseline) suppression. Relative paths are kept relative so dashboards
+// resolve them against the repository root.
+func (s *Scanner) SARIF(result *ScanResult, path, version string) *SARIFLog {
+	driver := SARIFDriver{
+		Name:           "tf-nlp-agent",
+		Version:        version,
+		InformationURI: "https://github.com/RyanSStephens/TF-NLP-Agent",
+		Rules:          []SARIFRule{},
+	}
+
+	rules := make(map[string]SecurityRule)
+	for _, rule := range s.rules {
+		if _, ok := rules[rule.ID]; !ok {
+			rules[rule.ID] = rule
+		}
+	}
+
+	uri := filepath.ToSlash(path)
+	if filepath.IsAbs(path) {
+		uri = "file://" + uri
+	}
+	indexes := make(map[string]int)
+	results := []SARIFResult{}
+
+	var suppressions [][]SARIFSuppression
+	issues := append([]Issue{}, result.Issues...)
+	for range result.Issues {
+		suppressions = append(suppressions, nil)
+	}
+	for _, suppressed := range result.Suppressed {
+		kind := "external"
+		if suppressed.Source == SuppressionSourceInline {
+			kind = "inSource"
+		}
+		issues = append(issues, suppressed.Issue)
+		suppressions = append(suppressions, []SARIFSuppression{{Kind: kind, Justification: suppressed.Reason}})
+	}
+
+	for i, issue := range issues {
+		index, ok := indexes[issue.Rule]
+		if !ok {
+			rule, known := rules[issue.Rule]
+			if !known {
+				rule = SecurityRule{ID: issue.Rule, Severity: issue.Severity, Message: issue.Message, Remediation: issue.Remediation}
+			}
+			index = len(driver.Rules)
+			indexes[issue.Rule] = index
+			driver.Rules = append(driver.Rules, sarifRule(rule))
+		}
+
+		location := SARIFLocation{
+			PhysicalLocation: SARIFPhysicalLocation{ArtifactLocation: SARIFArtifactLocation{URI: uri}},
+		}
+		if issue.Line > 0 {
+			location.PhysicalLocation.Region = &SARIFRegion{StartLine: issue.Line, StartColumn: issue.Column}
+		}
+		if issue.Resource != "" {
+			location.LogicalLocations = []SARIFLogicalLocation{{FullyQualifiedName: issue.Resource, Kind: "resource"}}
+		}
+
+		message := issue.Message
+		if issue.Remediation != "" {
+			message += ". " + issue.Remediation
+		}
+
+		results = append(results, SARIFResult{
+			RuleID:       issue.Rule,
+			RuleIndex:    index,
+			Level:        sarifLevel(issue.Severity),
+			Message:      SARIFMessage{Text: message},
+			Locations:    []SARIFLocation{location},
+			Suppressions: suppressions[i],
+		})
+	}
+
+	return &SARIFLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []SARIFRun{{Tool: SARIFTool{Driver: driver}, Results: results}},
+	}
+}
+
+// JSON encodes the log
+func (l *SARIFLog) JSON() ([]byte, error) {
+	return json.MarshalIndent(l, "", "  ")
+}
+
+// sarifRule converts a rule's metadata
+func sarifRule(rule SecurityRule) SARIFRule {
+	sarif := SARIFRule{
+		ID:                   rule.ID,
+		Name:                 rule.Name,
+		ShortDescription:     SARIFMessage{Text: rule.Message},
+		DefaultConfiguration: SARIFConfiguration{Level: sarifLevel(rule.Severity)},
+		Properties: SARIFProperties{
+			Tags:             []string{"security", "terraform"},
+			SecuritySeverity: sarifSecuritySeverities[rule.Severity],
+		},
+	}
+	if rule.Remediation != "" {
+		sarif.Help = &SARIFMessage{Text: rule.Remediation}
+	}
+	// Controls become tags such as cis-aws/2.1.5, in framework order
+	for _, framework := range controlFrameworks {
+		for _, control := range rule.Controls[framework.ID] {
+			sarif.Properties.Tags = append(sarif.Properties.Tags, framework.ID+"/"+control)
+		}
+	}
+	return sarif
+}
+
+// sarifLevel maps a severity to a SARIF level
+func sarifLevel(severity string) string {
+	if level, ok := sarifLevels[severity]; ok {
+		return level
+	}
+	return "warning"
+}
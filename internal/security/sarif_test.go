@@ -0,0 +1,150 @@
+package security
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func TestSARIF(t *testing.T) {
+	scanner := NewScanner()
+	result := &ScanResult{
+		Issues: []Issue{
+			{Severity: "HIGH", Message: "S3 bucket has public read access", Resource: "aws_s3_bucket.site", Line: 3, Column: 3, Rule: "SEC001"},
+			{Severity: "MEDIUM", Message: "bucket name too short", Resource: "aws_s3_bucket.site", Rule: "POL-S3-002", Remediation: "Use a descriptive name"},
+			{Severity: "HIGH", Message: "S3 bucket has public read access", Resource: "aws_s3_bucket.assets", Line: 9, Column: 3, Rule: "SEC001"},
+		},
+		Suppressed: []SuppressedIssue{
+			{Issue: Issue{Severity: "HIGH", Message: "Security group allows ingress from 0.0.0.0/0", Resource: "aws_security_group.alb", Line: 14, Column: 5, Rule: "SEC003"}, Reason: "public ALB", Source: SuppressionSourceInline},
+			{Issue: Issue{Severity: "MEDIUM", Message: "S3 bucket is not encrypted", Resource: "aws_s3_bucket.assets", Line: 7, Column: 1, Rule: "SEC011"}, Reason: "static assets", Source: ".tfnlp-baseline.yaml"},
+		},
+	}
+
+	log := scanner.SARIF(result, "main.tf", "1.2.0")
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("SARIF() = version %s with %d runs, want 2.1.0 with one run", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Version != "1.2.0" {
+		t.Errorf("driver version = %q, want 1.2.0", run.Tool.Driver.Version)
+	}
+	if len(run.Results) != 5 || len(run.Tool.Driver.Rules) != 4 {
+		t.Fatalf("SARIF() has %d results and %d rules, want 5 and 4", len(run.Results), len(run.Tool.Driver.Rules))
+	}
+
+	for _, r := range run.Results {
+		if r.RuleIndex < 0 || r.RuleIndex >= len(run.Tool.Driver.Rules) || run.Tool.Driver.Rules[r.RuleIndex].ID != r.RuleID {
+			t.Errorf("result %s has ruleIndex %d, which is not its rule", r.RuleID, r.RuleIndex)
+		}
+	}
+	if run.Results[0].RuleIndex != run.Results[2].RuleIndex {
+		t.Errorf("results of SEC001 have ruleIndex %d and %d, want one rule entry", run.Results[0].RuleIndex, run.Results[2].RuleIndex)
+	}
+
+	// Built-in rules carry the scanner's metadata
+	known := run.Tool.Driver.Rules[run.Results[0].RuleIndex]
+	var sec001 SecurityRule
+	for _, rule := range scanner.GetRules() {
+		if rule.ID == "SEC001" {
+			sec001 = rule
+		}
+	}
+	if known.Name != sec001.Name || known.ShortDescription.Text != sec001.Message || known.Properties.SecuritySeverity != "8.0" {
+		t.Errorf("SEC001 rule = %+v, want the metadata of the built-in rule", known)
+	}
+
+	// Rules the scanner does not know are described by their issue
+	synthesized := run.Tool.Driver.Rules[run.Results[1].RuleIndex]
+	if synthesized.ID != "POL-S3-002" || synthesized.ShortDescription.Text != "bucket name too short" ||
+		synthesized.DefaultConfiguration.Level != "warning" || synthesized.Help == nil || synthesized.Help.Text != "Use a descriptive name" {
+		t.Errorf("POL-S3-002 rule = %+v, want metadata from the issue", synthesized)
+	}
+	if run.Results[1].Message.Text != "bucket name too short. Use a descriptive name" {
+		t.Errorf("POL-S3-002 message = %q, want the message and remediation", run.Results[1].Message.Text)
+	}
+	if run.Results[1].Locations[0].PhysicalLocation.Region != nil {
+		t.Errorf("POL-S3-002 region = %+v, want none for an issue without a line", run.Results[1].Locations[0].PhysicalLocation.Region)
+	}
+
+	tests := []struct {
+		result        int
+		wantKind      string
+		justification string
+	}{
+		{0, "", ""},
+		{3, "inSource", "public ALB"},
+		{4, "external", "static assets"},
+	}
+	for _, tt := range tests {
+		r := run.Results[tt.result]
+		if tt.wantKind == "" {
+			if len(r.Suppressions) != 0 {
+				t.Errorf("result %s suppressions = %+v, want none", r.RuleID, r.Suppressions)
+			}
+			continue
+		}
+		if len(r.Suppressions) != 1 || r.Suppressions[0].Kind != tt.wantKind || r.Suppressions[0].Justification != tt.justification {
+			t.Errorf("result %s suppressions = %+v, want %s %q", r.RuleID, r.Suppressions, tt.wantKind, tt.justification)
+		}
+	}
+
+	// ruleIndex 0 must still be written
+	data, err := log.JSON()
+	if err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+	var decoded struct {
+		Runs []struct {
+			Results []map[string]interface{} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("JSON() is invalid: %v", err)
+	}
+	if _, ok := decoded.Runs[0].Results[0]["ruleIndex"]; !ok {
+		t.Errorf("JSON() result %v has no ruleIndex", decoded.Runs[0].Results[0])
+	}
+}
+
+func TestSARIFArtifactURI(t *testing.T) {
+	absolute, err := filepath.Abs(filepath.Join("testdata", "main.tf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"file name", "main.tf", "main.tf"},
+		{"relative path", filepath.Join("modules", "app", "main.tf"), "modules/app/main.tf"},
+		{"absolute path", absolute, "file://" + filepath.ToSlash(absolute)},
+	}
+
+	result := &ScanResult{Issues: []Issue{{Severity: "LOW", Message: "m", Rule: "SEC009", Line: 1, Column: 1}}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := NewScanner().SARIF(result, tt.path, "")
+			if got := log.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI; got != tt.want {
+				t.Errorf("artifact uri = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSARIFWithoutIssues(t *testing.T) {
+	data, err := NewScanner().SARIF(&ScanResult{}, "main.tf", "").JSON()
+	if err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("JSON() is invalid: %v", err)
+	}
+	run := decoded["runs"].([]interface{})[0].(map[string]interface{})
+	if results, ok := run["results"].([]interface{}); !ok || len(results) != 0 {
+		t.Errorf("results = %v, want an empty list", run["results"])
+	}
+}
@@ -1,230 +1,455 @@
 package security
 
 import (
+	"fmt"
 	"regexp"
-	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
 )
 
-// Issue represents a security issue found in Terraform configuration
+// Issue represents a security issue found in Terraform configuration.
+// Resource is the address of the block the issue was found in, e.g.
+// aws_s3_bucket.logs; Line and Column point at the offending attribute or,
+// for missing settings, at the block header.
 type Issue struct {
 	Severity    string
 	Message     string
 	Resource    string
 	Line        int
+	Column      int
 	Rule        string
 	Remediation string
 }
 
 // Scanner handles security scanning of Terraform configurations
 type Scanner struct {
-	rules []SecurityRule
+	rules        []SecurityRule
+	policies     *policyEngine
+	baseline     []BaselineEntry
+	baselinePath string
+	secrets      *secretDetector
 }
 
-// SecurityRule represents a security rule to check
+// SecurityRule represents a security rule to check. Built-in rules inspect
+// the parsed blocks and attributes; custom rules match Pattern against the
+// source text of every attribute (including multi-line values such as
+// heredocs) and block header.
 type SecurityRule struct {
-	ID          string
-	Name        string
-	Severity    string
-	Pattern     *regexp.Regexp
-	Message     string
-	Remediation string
+	ID       string
+	Name     string
+	Severity string
+	// Provider limits the rule to configurations that use this Terraform
+	// provider (ProviderAWS, ProviderAzure, ProviderGoogle), and to that
+	// provider's resources and data sources; empty means any
+	Provider string
+	// ResourceTypes limits the rule to resource and data blocks of these
+	// types; empty means all blocks
+	ResourceTypes []string
+	Pattern       *regexp.Regexp
+	Message       string
+	Remediation   string
+	// Controls maps control frameworks (ControlsCISAWS, ...) to the IDs of
+	// the controls the rule provides evidence for
+	Controls map[string][]string
+
+	// check returns the ranges in block b that violate the rule
+	check func(c *parsedConfig, b *block) []hcl.Range
+	// fix rewrites a violating block; nil when the rule has no automatic fix
+	fix fixFunc
 }
 
 // NewScanner creates a new security scanner with default rules
 func NewScanner() *Scanner {
 	scanner := &Scanner{
-		rules: []SecurityRule{},
+		rules:   []SecurityRule{},
+		secrets: &secretDetector{},
 	}
 
 	scanner.loadDefaultRules()
 	return scanner
 }
 
-// Scan analyzes a Terraform configuration for security issues
-func (s *Scanner) Scan(config string) ([]Issue, error) {
-	var issues []Issue
+// Scan analyzes a Terraform configuration for security issues. Every rule
+// of the providers the configuration uses runs against each of its blocks,
+// so issues are reported per resource, followed by the results of the
+// loaded policies.
+// Issues accepted by a tfnlp:ignore comment or the baseline are returned
+// as suppressed; a suppression without a reason is an error.
+func (s *Scanner) Scan(config string) (*ScanResult, error) {
+	parsed, err := parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
 
-	lines := strings.Split(config, "\n")
+	providers := parsed.providers()
 
-	for lineNum, line := range lines {
+	var issues []Issue
+	for _, b := range parsed.blocks {
 		for _, rule := range s.rules {
-			if rule.Pattern.MatchString(line) {
-				issue := Issue{
+			if (rule.Provider != "" && !providers[rule.Provider]) || !rule.appliesTo(b) {
+				continue
+			}
+			for _, rng := range rule.matches(parsed, b) {
+				issues = append(issues, Issue{
 					Severity:    rule.Severity,
 					Message:     rule.Message,
-					Resource:    extractResourceName(line),
-					Line:        lineNum + 1,
+					Resource:    b.Address(),
+					Line:        rng.Start.Line,
+					Column:      rng.Start.Column,
 					Rule:        rule.ID,
 					Remediation: rule.Remediation,
-				}
-				issues = append(issues, issue)
+				})
 			}
 		}
 	}
 
-	// Additional context-aware checks
-	contextIssues := s.performContextualScans(config)
-	issues = append(issues, contextIssues...)
+	if s.policies != nil {
+		policyIssues, err := s.policies.evaluate(parsed)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, policyIssues...)
+	}
 
-	return issues, nil
+	return s.suppress(parsed, issues)
+}
+
+// Location renders where the issue was found, e.g. "aws_s3_bucket.logs, line 3"
+func (i Issue) Location() string {
+	switch {
+	case i.Resource != "" && i.Line > 0:
+		return fmt.Sprintf("%s, line %d", i.Resource, i.Line)
+	case i.Resource != "":
+		return i.Resource
+	case i.Line > 0:
+		return fmt.Sprintf("line %d", i.Line)
+	default:
+		return ""
+	}
+}
+
+// appliesTo reports whether the rule inspects block b. Rules of a provider
+// skip the resources and data sources of other providers, so a rule without
+// resource types does not fire on another cloud's blocks in a multi-cloud
+// configuration.
+func (r SecurityRule) appliesTo(b *block) bool {
+	if provider := b.provider(); r.Provider != "" && provider != "" && provider != r.Provider {
+		return false
+	}
+	if len(r.ResourceTypes) == 0 {
+		return true
+	}
+	return (b.Kind == "resource" || b.Kind == "data") && contains(r.ResourceTypes, b.Type)
+}
+
+// matches returns the ranges in block b that violate the rule
+func (r SecurityRule) matches(c *parsedConfig, b *block) []hcl.Range {
+	if r.check != nil {
+		return r.check(c, b)
+	}
+	if r.Pattern == nil {
+		return nil
+	}
+
+	var ranges []hcl.Range
+	if r.Pattern.MatchString(c.sourceText(b.Range)) {
+		ranges = append(ranges, b.Range)
+	}
+	walkAttributes(b.Body, func(attr *hclsyntax.Attribute) {
+		if r.Pattern.MatchString(c.sourceText(attr.SrcRange)) {
+			ranges = append(ranges, attr.SrcRange)
+		}
+	})
+	return ranges
 }
 
 // loadDefaultRules loads the default security rules
 func (s *Scanner) loadDefaultRules() {
 	rules := []SecurityRule{
 		{
-			ID:          "SEC001",
-			Name:        "Public S3 Bucket",
-			Severity:    "HIGH",
-			Pattern:     regexp.MustCompile(`acl\s*=\s*"public-read"`),
-			Message:     "S3 bucket configured with public read access",
-			Remediation: "Remove public ACL and use bucket policies for controlled access",
+			ID:            "SEC001",
+			Name:          "Public S3 Bucket",
+			Severity:      "HIGH",
+			Provider:      ProviderAWS,
+			ResourceTypes: []string{"aws_s3_bucket", "aws_s3_bucket_acl"},
+			Message:       "S3 bucket configured with public read access",
+			Remediation:   "Remove public ACL and use bucket policies for controlled access",
+			Controls: map[string][]string{
+				ControlsCISAWS:    {"2.1.5"},
+				ControlsNIST80053: {"AC-3", "SC-7"},
+				ControlsPCIDSS:    {"1.4.4"},
+				ControlsHIPAA:     {"164.312(a)(1)"},
+			},
+			check: checkPublicACL,
+			fix:   fixAttribute("acl", cty.StringVal("private")),
 		},
 		{
-			ID:          "SEC002",
-			Name:        "Unencrypted Storage",
-			Severity:    "MEDIUM",
-			Pattern:     regexp.MustCompile(`resource\s+"aws_s3_bucket"`),
-			Message:     "S3 bucket may not have encryption enabled",
-			Remediation: "Enable server-side encryption for S3 buckets",
+			ID:            "SEC002",
+			Name:          "Unencrypted Storage",
+			Severity:      "MEDIUM",
+			Provider:      ProviderAWS,
+			ResourceTypes: storageTypes(),
+			Message:       "Storage resource does not have encryption at rest enabled",
+			Remediation:   "Enable encryption at rest for file systems, warehouses and caches",
+			Controls: map[string][]string{
+				ControlsCISAWS:    {"2.4.1"},
+				ControlsNIST80053: {"SC-28"},
+				ControlsPCIDSS:    {"3.5.1"},
+				ControlsHIPAA:     {"164.312(a)(2)(iv)"},
+			},
+			check: checkStorageEncryption,
+			fix:   fixStorageEncryption,
 		},
 		{
-			ID:          "SEC003",
-			Name:        "Open Security Group",
-			Severity:    "CRITICAL",
-			Pattern:     regexp.MustCompile(`cidr_blocks\s*=\s*\["0\.0\.0\.0/0"\]`),
-			Message:     "Security group allows access from anywhere (0.0.0.0/0)",
-			Remediation: "Restrict CIDR blocks to specific IP ranges",
+			ID:            "SEC003",
+			Name:          "Open Security Group",
+			Severity:      "CRITICAL",
+			Provider:      ProviderAWS,
+			ResourceTypes: []string{"aws_security_group", "aws_security_group_rule", "aws_vpc_security_group_ingress_rule"},
+			Message:       "Security group allows ingress from anywhere (0.0.0.0/0)",
+			Remediation:   "Restrict CIDR blocks to specific IP ranges",
+			Controls: map[string][]string{
+				ControlsNIST80053: {"SC-7"},
+				ControlsPCIDSS:    {"1.3.1"},
+				ControlsHIPAA:     {"164.312(a)(1)"},
+			},
+			check: checkOpenIngress,
 		},
 		{
-			ID:          "SEC004",
-			Name:        "Unencrypted EBS Volume",
-			Severity:    "MEDIUM",
-			Pattern:     regexp.MustCompile(`resource\s+"aws_ebs_volume"`),
-			Message:     "EBS volume may not have encryption enabled",
-			Remediation: "Enable encryption for EBS volumes",
+			ID:            "SEC004",
+			Name:          "Unencrypted EBS Volume",
+			Severity:      "MEDIUM",
+			Provider:      ProviderAWS,
+			ResourceTypes: []string{"aws_ebs_volume", "aws_instance"},
+			Message:       "EBS volume does not have encryption enabled",
+			Remediation:   "Enable encryption for EBS volumes",
+			Controls: map[string][]string{
+				ControlsCISAWS:    {"2.2.1"},
+				ControlsNIST80053: {"SC-28"},
+				ControlsPCIDSS:    {"3.5.1"},
+				ControlsHIPAA:     {"164.312(a)(2)(iv)"},
+			},
+			check: checkVolumeEncryption,
+			fix:   fixVolumeEncryption,
 		},
 		{
 			ID:          "SEC005",
 			Name:        "Public RDS Instance",
 			Severity:    "HIGH",
-			Pattern:     regexp.MustCompile(`publicly_accessible\s*=\s*true`),
+			Provider:    ProviderAWS,
 			Message:     "RDS instance is publicly accessible",
 			Remediation: "Set publicly_accessible to false for RDS instances",
+			Controls: map[string][]string{
+				ControlsCISAWS:    {"2.3.3"},
+				ControlsNIST80053: {"AC-3", "SC-7"},
+				ControlsPCIDSS:    {"1.4.4"},
+				ControlsHIPAA:     {"164.312(a)(1)"},
+			},
+			check: checkAttributeEquals("publicly_accessible", "true"),
+			fix:   fixAttribute("publicly_accessible", cty.False),
 		},
 		{
 			ID:          "SEC006",
 			Name:        "Weak Password Policy",
 			Severity:    "MEDIUM",
-			Pattern:     regexp.MustCompile(`password\s*=\s*"[^"]{1,7}"`),
 			Message:     "Password appears to be too short",
 			Remediation: "Use strong passwords with at least 8 characters",
+			Controls: map[string][]string{
+				ControlsNIST80053: {"IA-5"},
+				ControlsPCIDSS:    {"8.3.6"},
+				ControlsHIPAA:     {"164.308(a)(5)(ii)(D)"},
+			},
+			check: checkShortPasswords,
 		},
 		{
 			ID:          "SEC007",
 			Name:        "Hardcoded Secrets",
 			Severity:    "CRITICAL",
-			Pattern:     regexp.MustCompile(`(password|secret|key)\s*=\s*"[^$][^"]*"`),
 			Message:     "Potential hardcoded secret or password",
 			Remediation: "Use variables or AWS Secrets Manager for sensitive data",
+			Controls: map[string][]string{
+				ControlsNIST80053: {"IA-5(7)"},
+				ControlsPCIDSS:    {"8.6.2"},
+				ControlsHIPAA:     {"164.312(d)"},
+			},
+			check: s.secrets.check,
+			fix:   fixHardcodedSecrets,
 		},
 		{
-			ID:          "SEC008",
-			Name:        "Missing HTTPS",
-			Severity:    "MEDIUM",
-			Pattern:     regexp.MustCompile(`protocol\s*=\s*"HTTP"`),
-			Message:     "Load balancer listener using HTTP instead of HTTPS",
-			Remediation: "Use HTTPS protocol for load balancer listeners",
+			ID:            "SEC008",
+			Name:          "Missing HTTPS",
+			Severity:      "MEDIUM",
+			Provider:      ProviderAWS,
+			ResourceTypes: []string{"aws_lb_listener", "aws_alb_listener"},
+			Message:       "Load balancer listener using HTTP instead of HTTPS",
+			Remediation:   "Use HTTPS protocol for load balancer listeners",
+			Controls: map[string][]string{
+				ControlsNIST80053: {"SC-8"},
+				ControlsPCIDSS:    {"4.2.1"},
+				ControlsHIPAA:     {"164.312(e)(1)"},
+			},
+			check: checkPlainHTTPListener,
 		},
 		{
-			ID:          "SEC009",
-			Name:        "Default VPC Usage",
-			Severity:    "LOW",
-			Pattern:     regexp.MustCompile(`default\s*=\s*true.*vpc`),
-			Message:     "Using default VPC may not follow security best practices",
-			Remediation: "Create custom VPC with proper network segmentation",
+			ID:            "SEC009",
+			Name:          "Default VPC Usage",
+			Severity:      "LOW",
+			Provider:      ProviderAWS,
+			ResourceTypes: []string{"aws_vpc", "aws_default_vpc"},
+			Message:       "Using default VPC may not follow security best practices",
+			Remediation:   "Create custom VPC with proper network segmentation",
+			Controls: map[string][]string{
+				ControlsNIST80053: {"SC-7"},
+			},
+			check: checkDefaultVPC,
 		},
 		{
 			ID:          "SEC010",
 			Name:        "Missing Backup",
 			Severity:    "MEDIUM",
-			Pattern:     regexp.MustCompile(`backup_retention_period\s*=\s*0`),
+			Provider:    ProviderAWS,
 			Message:     "Database backup retention period is set to 0",
 			Remediation: "Enable automated backups with appropriate retention period",
+			Controls: map[string][]string{
+				ControlsNIST80053: {"CP-9"},
+				ControlsHIPAA:     {"164.308(a)(7)(ii)(A)"},
+			},
+			check: checkAttributeEquals("backup_retention_period", "0"),
+			fix:   fixAttribute("backup_retention_period", cty.NumberIntVal(7)),
+		},
+		{
+			ID:            "SEC011",
+			Name:          "Unencrypted S3 Bucket",
+			Severity:      "MEDIUM",
+			Provider:      ProviderAWS,
+			ResourceTypes: []string{"aws_s3_bucket"},
+			Message:       "S3 bucket missing server-side encryption configuration",
+			Remediation:   "Add an aws_s3_bucket_server_side_encryption_configuration for the bucket",
+			Controls: map[string][]string{
+				ControlsCISAWS:    {"2.1.1"},
+				ControlsNIST80053: {"SC-28"},
+				ControlsPCIDSS:    {"3.5.1"},
+				ControlsHIPAA:     {"164.312(a)(2)(iv)"},
+			},
+			check: checkBucketEncryption,
+			fix:   fixBucketEncryption,
+		},
+		{
+			ID:            "SEC012",
+			Name:          "S3 Bucket Versioning",
+			Severity:      "LOW",
+			Provider:      ProviderAWS,
+			ResourceTypes: []string{"aws_s3_bucket"},
+			Message:       "S3 bucket missing versioning configuration",
+			Remediation:   "Enable versioning for S3 buckets",
+			Controls: map[string][]string{
+				ControlsNIST80053: {"CP-9"},
+				ControlsHIPAA:     {"164.308(a)(7)(ii)(A)"},
+			},
+			check: checkBucketVersioning,
+			fix:   fixBucketVersioning,
+		},
+		{
+			ID:            "SEC013",
+			Name:          "S3 Bucket MFA Delete",
+			Severity:      "LOW",
+			Provider:      ProviderAWS,
+			ResourceTypes: []string{"aws_s3_bucket"},
+			Message:       "S3 bucket missing MFA delete protection",
+			Remediation:   "Enable MFA delete for S3 buckets containing sensitive data",
+			Controls: map[string][]string{
+				ControlsCISAWS: {"2.1.3"},
+			},
+			check: checkBucketMFADelete,
+		},
+		{
+			ID:            "SEC014",
+			Name:          "EC2 Instance Without Security Group",
+			Severity:      "HIGH",
+			Provider:      ProviderAWS,
+			ResourceTypes: []string{"aws_instance"},
+			Message:       "EC2 instance missing security group configuration",
+			Remediation:   "Assign appropriate security groups to EC2 instances",
+			Controls: map[string][]string{
+				ControlsNIST80053: {"SC-7"},
+				ControlsPCIDSS:    {"1.3.1"},
+			},
+			check: checkInstanceSecurityGroups,
+		},
+		{
+			ID:            "SEC015",
+			Name:          "Unencrypted RDS Instance",
+			Severity:      "MEDIUM",
+			Provider:      ProviderAWS,
+			ResourceTypes: []string{"aws_db_instance", "aws_rds_cluster"},
+			Message:       "RDS instance missing storage encryption",
+			Remediation:   "Enable storage encryption for RDS instances",
+			Controls: map[string][]string{
+				ControlsCISAWS:    {"2.3.1"},
+				ControlsNIST80053: {"SC-28"},
+				ControlsPCIDSS:    {"3.5.1"},
+				ControlsHIPAA:     {"164.312(a)(2)(iv)"},
+			},
+			check: checkSettingEnabled("storage_encrypted"),
+			fix:   fixAttribute("storage_encrypted", cty.True),
+		},
+		// Relationship rules follow the references between resources
+		{
+			ID:            "SEC017",
+			Name:          "S3 Bucket Without Public Access Block",
+			Severity:      "MEDIUM",
+			Provider:      ProviderAWS,
+			ResourceTypes: []string{"aws_s3_bucket"},
+			Message:       "S3 bucket is not covered by a public access block",
+			Remediation:   "Add an aws_s3_bucket_public_access_block for the bucket with all four settings enabled",
+			Controls: map[string][]string{
+				ControlsCISAWS:    {"2.1.5"},
+				ControlsNIST80053: {"AC-3"},
+				ControlsPCIDSS:    {"1.4.4"},
+				ControlsHIPAA:     {"164.312(a)(1)"},
+			},
+			check: checkBucketPublicAccessBlock,
+			fix:   fixBucketPublicAccessBlock,
+		},
+		{
+			ID:            "SEC018",
+			Name:          "Database In Public Subnet",
+			Severity:      "HIGH",
+			Provider:      ProviderAWS,
+			ResourceTypes: []string{"aws_db_instance", "aws_rds_cluster"},
+			Message:       "Database subnet group includes a subnet routed to an internet gateway",
+			Remediation:   "Place databases in private subnets without a route to an internet gateway",
+			Controls: map[string][]string{
+				ControlsNIST80053: {"SC-7"},
+				ControlsPCIDSS:    {"1.4.4"},
+				ControlsHIPAA:     {"164.312(a)(1)"},
+			},
+			check: checkDatabaseInPublicSubnet,
+		},
+		{
+			ID:            "SEC019",
+			Name:          "Instance Reachable Over SSH",
+			Severity:      "CRITICAL",
+			Provider:      ProviderAWS,
+			ResourceTypes: []string{"aws_instance", "aws_launch_template"},
+			Message:       "Instance is attached to a security group that allows SSH (port 22) from anywhere",
+			Remediation:   "Restrict SSH to known address ranges, or use Session Manager instead of SSH",
+			Controls: map[string][]string{
+				ControlsCISAWS:    {"5.2"},
+				ControlsNIST80053: {"AC-17", "SC-7"},
+				ControlsPCIDSS:    {"1.3.1"},
+			},
+			check: checkReachableSSH,
 		},
 	}
 
-	s.rules = rules
-}
-
-// performContextualScans performs more complex security checks that require context
-func (s *Scanner) performContextualScans(config string) []Issue {
-	var issues []Issue
-
-	// Check for missing encryption on storage resources
-	if strings.Contains(config, "aws_s3_bucket") && !strings.Contains(config, "server_side_encryption") {
-		issues = append(issues, Issue{
-			Severity:    "MEDIUM",
-			Message:     "S3 bucket missing server-side encryption configuration",
-			Rule:        "SEC011",
-			Remediation: "Add server_side_encryption_configuration block",
-		})
-	}
-
-	// Check for missing versioning on S3 buckets
-	if strings.Contains(config, "aws_s3_bucket") && !strings.Contains(config, "versioning") {
-		issues = append(issues, Issue{
-			Severity:    "LOW",
-			Message:     "S3 bucket missing versioning configuration",
-			Rule:        "SEC012",
-			Remediation: "Enable versioning for S3 buckets",
-		})
-	}
-
-	// Check for missing MFA delete on S3 buckets
-	if strings.Contains(config, "aws_s3_bucket") && !strings.Contains(config, "mfa_delete") {
-		issues = append(issues, Issue{
-			Severity:    "LOW",
-			Message:     "S3 bucket missing MFA delete protection",
-			Rule:        "SEC013",
-			Remediation: "Enable MFA delete for S3 buckets containing sensitive data",
-		})
-	}
-
-	// Check for EC2 instances without security groups
-	if strings.Contains(config, "aws_instance") && !strings.Contains(config, "security_groups") && !strings.Contains(config, "vpc_security_group_ids") {
-		issues = append(issues, Issue{
-			Severity:    "HIGH",
-			Message:     "EC2 instance missing security group configuration",
-			Rule:        "SEC014",
-			Remediation: "Assign appropriate security groups to EC2 instances",
-		})
-	}
-
-	// Check for RDS instances without encryption
-	if strings.Contains(config, "aws_db_instance") && !strings.Contains(config, "storage_encrypted") {
-		issues = append(issues, Issue{
-			Severity:    "MEDIUM",
-			Message:     "RDS instance missing storage encryption",
-			Rule:        "SEC015",
-			Remediation: "Enable storage encryption for RDS instances",
-		})
-	}
-
-	return issues
-}
-
-// extractResourceName extracts the resource name from a Terraform line
-func extractResourceName(line string) string {
-	// Pattern to match resource declarations: resource "type" "name"
-	re := regexp.MustCompile(`resource\s+"([^"]+)"\s+"([^"]+)"`)
-	matches := re.FindStringSubmatch(line)
+	// The Azure and Google Cloud packs run on configurations using those providers
+	rules = append(rules, azureRules()...)
+	rules = append(rules, googleRules()...)
 
-	if len(matches) >= 3 {
-		return matches[1] + "." + matches[2]
-	}
-
-	return ""
+	s.rules = rules
 }
 
 // AddCustomRule adds a custom security rule to the scanner
@@ -244,17 +469,30 @@ func (s *Scanner) AddAdvancedSecurityRules() {
 			ID:          "SEC015",
 			Name:        "IAM Policy Wildcard Resources",
 			Severity:    "HIGH",
-			Pattern:     regexp.MustCompile(`"Resource":\s*"\*"`),
+			Provider:    ProviderAWS,
 			Message:     "IAM policy grants access to all resources using wildcard",
 			Remediation: "Specify explicit resource ARNs instead of using wildcards",
+			Controls: map[string][]string{
+				ControlsNIST80053: {"AC-6"},
+				ControlsPCIDSS:    {"7.2.2"},
+				ControlsHIPAA:     {"164.308(a)(4)"},
+			},
+			check: checkWildcardResources,
 		},
 		{
-			ID:          "SEC016",
-			Name:        "Unencrypted SNS Topic",
-			Severity:    "MEDIUM",
-			Pattern:     regexp.MustCompile(`resource\s+"aws_sns_topic"`),
-			Message:     "SNS topic may not have encryption enabled",
-			Remediation: "Enable KMS encryption for SNS topics",
+			ID:            "SEC016",
+			Name:          "Unencrypted SNS Topic",
+			Severity:      "MEDIUM",
+			Provider:      ProviderAWS,
+			ResourceTypes: []string{"aws_sns_topic"},
+			Message:       "SNS topic does not have encryption enabled",
+			Remediation:   "Enable KMS encryption for SNS topics",
+			Controls: map[string][]string{
+				ControlsNIST80053: {"SC-28"},
+				ControlsHIPAA:     {"164.312(a)(2)(iv)"},
+			},
+			check: checkAttributeMissing("kms_master_key_id"),
+			fix:   fixAttribute("kms_master_key_id", cty.StringVal("alias/aws/sns")),
 		},
 	}
 
@@ -0,0 +1,290 @@
+package security
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// scan runs the scanner over config and fails the test on a scan error
+func scan(t *testing.T, scanner *Scanner, config string) *ScanResult {
+	t.Helper()
+	result, err := scanner.Scan(config)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	return result
+}
+
+// reported returns the issues of one rule as "resource line:column"
+func reported(issues []Issue, rule string) []string {
+	var found []string
+	for _, issue := range issues {
+		if issue.Rule == rule {
+			found = append(found, fmt.Sprintf("%s %d:%d", issue.Resource, issue.Line, issue.Column))
+		}
+	}
+	sort.Strings(found)
+	return found
+}
+
+// ruleIDs returns the sorted, distinct rules of the issues
+func ruleIDs(issues []Issue) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, issue := range issues {
+		if !seen[issue.Rule] {
+			seen[issue.Rule] = true
+			ids = append(ids, issue.Rule)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestScanMultiLineValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   string
+		config string
+		want   []string
+	}{
+		{
+			name: "cidr list spread over several lines",
+			rule: "SEC003",
+			config: `resource "aws_security_group" "web" {
+  ingress {
+    from_port = 443
+    to_port   = 443
+    protocol  = "tcp"
+    cidr_blocks = [
+      "10.0.0.0/8",
+      "0.0.0.0/0",
+    ]
+  }
+}
+`,
+			want: []string{"aws_security_group.web 6:5"},
+		},
+		{
+			name: "egress to anywhere is not reported",
+			rule: "SEC003",
+			config: `resource "aws_security_group" "web" {
+  egress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+`,
+		},
+		{
+			name: "policy document in a heredoc",
+			rule: "SEC015",
+			config: `resource "aws_iam_policy" "admin" {
+  name   = "admin"
+  policy = <<EOF
+{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Effect": "Allow",
+      "Action": "s3:*",
+      "Resource": "*"
+    }
+  ]
+}
+EOF
+}
+`,
+			want: []string{"aws_iam_policy.admin 3:3"},
+		},
+		{
+			name: "policy document in jsonencode over several lines",
+			rule: "SEC015",
+			config: `resource "aws_iam_role_policy" "app" {
+  policy = jsonencode({
+    Statement = [{
+      Effect   = "Allow"
+      Action   = ["s3:GetObject"]
+      Resource = "*"
+    }]
+  })
+}
+`,
+			want: []string{"aws_iam_role_policy.app 2:3"},
+		},
+		{
+			name: "multi-line condition in an ACL",
+			rule: "SEC001",
+			config: `resource "aws_s3_bucket" "site" {
+  bucket = "site"
+  acl = (
+    "public-read"
+  )
+}
+`,
+			want: []string{"aws_s3_bucket.site 3:3"},
+		},
+		{
+			name: "heredoc password",
+			rule: "SEC007",
+			config: `resource "aws_db_instance" "main" {
+  storage_encrypted = true
+  password = <<-EOT
+    Sup3rS3cretValue
+  EOT
+}
+`,
+			want: []string{"aws_db_instance.main 3:3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := NewScanner()
+			scanner.AddAdvancedSecurityRules()
+
+			got := reported(scan(t, scanner, tt.config).Issues, tt.rule)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("%s issues = %v, want %v", tt.rule, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanReportsEachResource(t *testing.T) {
+	config := `resource "aws_s3_bucket" "encrypted" {
+  bucket = "encrypted"
+}
+
+resource "aws_s3_bucket_server_side_encryption_configuration" "encrypted" {
+  bucket = aws_s3_bucket.encrypted.id
+  rule {
+    apply_server_side_encryption_by_default {
+      sse_algorithm = "aws:kms"
+    }
+  }
+}
+
+resource "aws_s3_bucket" "plain" {
+  bucket = "plain"
+}
+
+resource "aws_s3_bucket" "inline" {
+  bucket = "inline"
+  server_side_encryption_configuration {
+    rule {
+      apply_server_side_encryption_by_default {
+        sse_algorithm = "AES256"
+      }
+    }
+  }
+}
+
+resource "aws_s3_bucket_versioning" "plain" {
+  bucket = aws_s3_bucket.plain.id
+  versioning_configuration {
+    status = "Enabled"
+  }
+}
+`
+	issues := scan(t, NewScanner(), config).Issues
+
+	if got := reported(issues, "SEC011"); strings.Join(got, ",") != "aws_s3_bucket.plain 14:1" {
+		t.Errorf("SEC011 issues = %v, want only aws_s3_bucket.plain", got)
+	}
+	if got := reported(issues, "SEC012"); strings.Join(got, ",") != "aws_s3_bucket.encrypted 1:1,aws_s3_bucket.inline 18:1" {
+		t.Errorf("SEC012 issues = %v, want the buckets without versioning", got)
+	}
+	for _, issue := range issues {
+		if issue.Resource == "" {
+			t.Errorf("issue %s is not attributed to a resource", issue.Rule)
+		}
+	}
+}
+
+func TestScanIssueLocations(t *testing.T) {
+	config := `provider "aws" {
+  region = "us-east-1"
+}
+
+resource "aws_db_instance" "main" {
+  engine                  = "postgres"
+  publicly_accessible     = true
+  backup_retention_period = 0
+  password                = "short"
+}
+
+resource "aws_instance" "web" {
+  ami                    = "ami-0c55b159cbfafe1f0"
+  vpc_security_group_ids = ["sg-123"]
+
+  root_block_device {
+    volume_size = 20
+  }
+}
+
+resource "aws_lb_listener" "http" {
+  port     = 80
+    protocol = "HTTP"
+}
+`
+	issues := scan(t, NewScanner(), config).Issues
+
+	tests := []struct {
+		rule string
+		want []string
+	}{
+		{"SEC005", []string{"aws_db_instance.main 7:3"}},
+		{"SEC006", []string{"aws_db_instance.main 9:3"}},
+		{"SEC007", []string{"aws_db_instance.main 9:3"}},
+		{"SEC010", []string{"aws_db_instance.main 8:3"}},
+		{"SEC015", []string{"aws_db_instance.main 5:1"}},
+		{"SEC004", []string{"aws_instance.web 16:3"}},
+		{"SEC008", []string{"aws_lb_listener.http 23:5"}},
+	}
+	for _, tt := range tests {
+		if got := reported(issues, tt.rule); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s issues = %v, want %v", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestShortPasswords(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  bool
+	}{
+		{"short literal", `"abc123"`, true},
+		{"long literal", `"a-much-longer-password"`, false},
+		{"boolean setting", `true`, false},
+		{"number", `1234`, false},
+		{"variable", `var.db_password`, false},
+		{"empty", `""`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := fmt.Sprintf(`resource "aws_db_instance" "main" {
+  storage_encrypted           = true
+  manage_master_user_password = %s
+}
+`, tt.value)
+
+			got := reported(scan(t, NewScanner(), config).Issues, "SEC006")
+			if (len(got) > 0) != tt.want {
+				t.Errorf("SEC006 issues = %v, want reported = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanInvalidConfiguration(t *testing.T) {
+	if _, err := NewScanner().Scan(`resource "aws_s3_bucket" "logs" {`); err == nil {
+		t.Error("Scan() accepted invalid HCL")
+	}
+}
@@ -0,0 +1,298 @@
+package security
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// secretFormat is a token format issued by a provider, recognized anywhere
+// in a string
+type secretFormat struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// secretFormats are the provider-specific key and token formats
+var secretFormats = []secretFormat{
+	{"AWS access key ID", regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`)},
+	{"AWS secret access key", regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?[A-Za-z0-9/+]{40}\b`)},
+	{"GitHub token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{82})\b`)},
+	{"GitLab token", regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20}\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[baprs]-[A-Za-z0-9-]{10,}`)},
+	{"Stripe secret key", regexp.MustCompile(`\b[rs]k_live_[A-Za-z0-9]{24,}\b`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"Azure storage account key", regexp.MustCompile(`AccountKey=[A-Za-z0-9+/]{86}==`)},
+	{"private key", regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )*PRIVATE KEY(?: BLOCK)?-----`)},
+	{"JSON web token", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{"password in URL", regexp.MustCompile(`\b[a-z][a-z0-9+.-]*://[^\s:@/]+:[^\s:@/]+@`)},
+}
+
+// credentialAssignmentPattern matches a credential assigned in text, such as
+// export DB_PASSWORD=... in user_data or api_key: ... in an embedded YAML
+// file; group 1 is the name and group 2 the value
+var credentialAssignmentPattern = regexp.MustCompile(`(?i)(?:^|[^A-Za-z0-9_.-])([A-Za-z0-9_.-]*(?:password|passwd|secret|token|api_?key|access_?key|private_?key))["']?[ \t]*[:=][ \t]*["']?([^\s"',;]+)`)
+
+// secretPlaceholderPattern matches values that stand in for a secret, such
+// as <password>, xxxx, CHANGE_ME and the example keys of provider docs
+var secretPlaceholderPattern = regexp.MustCompile(`(?i)^(?:<[^>]*>|\*+|x{3,}|\.{3,}|(?:change|replace)[_-]?me|your[_-].*|placeholder|dummy|redacted|todo|true|false|null|none|.*example.*)$`)
+
+// entropyTokenPattern splits strings into candidate tokens for the entropy
+// check
+var entropyTokenPattern = regexp.MustCompile(`[A-Za-z0-9+/=_-]{32,}`)
+
+// entropyAllowedSuffixes are attribute names whose values are identifiers,
+// digests or public keys and certificates rather than secrets, so they are
+// not checked for entropy
+var entropyAllowedSuffixes = []string{"_hash", "sha1", "sha256", "sha512", "md5", "etag", "checksum", "digest", "fingerprint", "thumbprint", "_id", "_ids", "_arn", "ami",
+	"public_key", "certificate", "certificate_body", "certificate_chain"}
+
+// publicKeyPattern matches public key material, such as an SSH public key or
+// a PEM certificate in a user_data script, whose tokens are not checked for
+// entropy
+var publicKeyPattern = regexp.MustCompile(`(?s)-----BEGIN (?:CERTIFICATE|PUBLIC KEY|RSA PUBLIC KEY)-----.*?-----END (?:CERTIFICATE|PUBLIC KEY|RSA PUBLIC KEY)-----|\b(?:ssh-(?:rsa|dss|ed25519)|ecdsa-sha2-nistp(?:256|384|521)|sk-(?:ssh-ed25519|ecdsa-sha2-nistp256)@openssh\.com)\s+[A-Za-z0-9+/]+=*`)
+
+// minSecretEntropy is the Shannon entropy, in bits per character, above which
+// a token of mixed letters and digits is taken for a generated secret. Random
+// base64 tokens of 32 characters or more score about 4.8; words, UUIDs and hex
+// digests stay below 4.
+const minSecretEntropy = 4.5
+
+// secretDetector finds hardcoded secrets in every string literal and heredoc
+// of a block: credential-named attributes and object keys with a literal
+// value, provider token formats, credentials assigned in embedded scripts,
+// and high-entropy tokens. Values matching the allowlist are ignored.
+type secretDetector struct {
+	allowlist []*regexp.Regexp
+}
+
+// AllowSecrets ignores values matching any of the regular expressions in the
+// secret checks of SEC007, e.g. known test fixtures
+func (s *Scanner) AllowSecrets(patterns ...string) error {
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid secret allowlist pattern %q: %w", pattern, err)
+		}
+		s.secrets.allowlist = append(s.secrets.allowlist, re)
+	}
+	return nil
+}
+
+// check flags the secrets of a block, one per line
+func (d *secretDetector) check(c *parsedConfig, b *block) []hcl.Range {
+	lines := make(map[int]bool)
+	var ranges []hcl.Range
+	for _, rng := range d.find(c.source, b.Body) {
+		if !lines[rng.Start.Line] {
+			lines[rng.Start.Line] = true
+			ranges = append(ranges, rng)
+		}
+	}
+	return ranges
+}
+
+// find returns the secrets in the attributes of body and its nested blocks,
+// in source order
+func (d *secretDetector) find(source []byte, body *hclsyntax.Body) []hcl.Range {
+	var findings []hcl.Range
+	walkAttributes(body, func(attr *hclsyntax.Attribute) {
+		findings = append(findings, d.findInAttribute(source, attr)...)
+	})
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Start.Byte < findings[j].Start.Byte
+	})
+	return findings
+}
+
+// findInAttribute returns the secrets in one attribute's expression
+func (d *secretDetector) findInAttribute(source []byte, attr *hclsyntax.Attribute) []hcl.Range {
+	if d.credential(attr.Name, attr.Expr) {
+		return []hcl.Range{attr.SrcRange}
+	}
+
+	entropy := !hasSuffixAny(strings.ToLower(attr.Name), entropyAllowedSuffixes...)
+	var findings []hcl.Range
+	hclsyntax.VisitAll(attr.Expr, func(node hclsyntax.Node) hcl.Diagnostics {
+		switch n := node.(type) {
+		case *hclsyntax.ObjectConsExpr:
+			for _, item := range n.Items {
+				if key, ok := literalString(item.KeyExpr); ok && d.credential(key, item.ValueExpr) {
+					findings = append(findings, item.ValueExpr.Range())
+				}
+			}
+		case *hclsyntax.LiteralValueExpr:
+			if n.Val.Type() == cty.String && n.Val.IsKnown() && !n.Val.IsNull() {
+				findings = append(findings, d.findInText(source, n.Val.AsString(), n.SrcRange, entropy)...)
+			}
+		}
+		return nil
+	})
+	return findings
+}
+
+// credential reports whether a credential-named attribute or object key is
+// set to a literal string that is not a placeholder. Booleans and numbers,
+// such as manage_master_user_password = true, are settings, not secrets.
+func (d *secretDetector) credential(name string, expr hclsyntax.Expression) bool {
+	if !hasSuffixAny(strings.ToLower(name), secretAttributeSuffixes...) {
+		return false
+	}
+	value, ok := literalValue(expr)
+	if !ok || value.Type() != cty.String {
+		return false
+	}
+	return !d.allowed(value.AsString())
+}
+
+// findInText returns the token formats, credential assignments and, with
+// entropy, the high-entropy tokens in a literal string found at rng
+func (d *secretDetector) findInText(source []byte, text string, rng hcl.Range, entropy bool) []hcl.Range {
+	var findings []hcl.Range
+
+	for _, format := range secretFormats {
+		for _, loc := range format.Pattern.FindAllStringIndex(text, -1) {
+			if !d.allowed(text[loc[0]:loc[1]]) {
+				findings = append(findings, offsetRange(source, rng, text, loc[0]))
+			}
+		}
+	}
+
+	for _, loc := range credentialAssignmentPattern.FindAllStringSubmatchIndex(text, -1) {
+		value := text[loc[4]:loc[5]]
+		if !assignedSecret(value) || d.allowed(value) {
+			continue
+		}
+		findings = append(findings, offsetRange(source, rng, text, loc[2]))
+	}
+
+	if entropy {
+		public := publicKeyPattern.FindAllStringIndex(text, -1)
+		for _, loc := range entropyTokenPattern.FindAllStringIndex(text, -1) {
+			if within(public, loc) {
+				continue
+			}
+			token := text[loc[0]:loc[1]]
+			if characterClasses(token) >= 3 && shannonEntropy(token) >= minSecretEntropy && !d.allowed(token) {
+				findings = append(findings, offsetRange(source, rng, text, loc[0]))
+			}
+		}
+	}
+
+	return findings
+}
+
+// allowed reports whether a value is empty, a placeholder or matches the
+// allowlist
+func (d *secretDetector) allowed(value string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" || secretPlaceholderPattern.MatchString(value) {
+		return true
+	}
+	for _, re := range d.allowlist {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// within reports whether the span loc lies inside one of spans
+func within(spans [][]int, loc []int) bool {
+	for _, span := range spans {
+		if loc[0] >= span[0] && loc[1] <= span[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// offsetRange returns the source position of the byte at offset in text, the
+// decoded value of a literal found at rng. Each line of text is matched to
+// its line in the source, so the indentation stripped from a <<- heredoc is
+// counted. A value that decoding changed, such as a string with escapes like
+// \n, is reported at the start of the literal.
+func offsetRange(source []byte, rng hcl.Range, text string, offset int) hcl.Range {
+	start := hcl.Range{Filename: rng.Filename, Start: rng.Start, End: rng.Start}
+
+	sourceLines := strings.Split(string(rng.SliceBytes(source)), "\n")
+	textLines := strings.Split(text, "\n")
+	if len(sourceLines) != len(textLines) {
+		return start
+	}
+	for i, line := range textLines {
+		indent := strings.TrimSuffix(sourceLines[i], line)
+		if !strings.HasSuffix(sourceLines[i], line) || strings.Trim(indent, " \t") != "" || (i == 0 && indent != "") {
+			return start
+		}
+	}
+
+	line := strings.Count(text[:offset], "\n")
+	column := offset - (strings.LastIndex(text[:offset], "\n") + 1)
+	column += len(sourceLines[line]) - len(textLines[line])
+
+	pos := rng.Start
+	for _, previous := range sourceLines[:line] {
+		pos.Byte += len(previous) + 1
+	}
+	pos.Byte += column
+	if line > 0 {
+		pos.Line += line
+		pos.Column = 1
+	}
+	pos.Column += utf8.RuneCountInString(sourceLines[line][:column])
+	return hcl.Range{Filename: rng.Filename, Start: pos, End: pos}
+}
+
+// assignedSecret reports whether a value assigned to a credential name in
+// text looks like a secret: at least 8 characters, not only letters (so prose
+// such as "token: rotate monthly" passes) and not a shell or template
+// reference such as $DB_PASSWORD
+func assignedSecret(value string) bool {
+	if len(value) < 8 || strings.ContainsAny(value[:1], "$%{<(") {
+		return false
+	}
+	return strings.IndexFunc(value, func(r rune) bool { return !unicode.IsLetter(r) }) >= 0
+}
+
+// shannonEntropy returns the entropy of s in bits per character
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// characterClasses counts the classes (lower case, upper case, digits) used
+// in s
+func characterClasses(s string) int {
+	var lower, upper, digit int
+	for _, r := range s {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		}
+	}
+	return lower + upper + digit
+}
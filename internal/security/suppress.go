@@ -0,0 +1,200 @@
+package security
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"gopkg.in/yaml.v3"
+)
+
+// SuppressionSourceInline marks issues suppressed by a comment in the configuration
+const SuppressionSourceInline = "inline"
+
+var (
+	// suppressionPattern matches an inline suppression comment such as
+	// # tfnlp:ignore SEC003,SEC011 reason="public ALB"
+	suppressionPattern = regexp.MustCompile(`^(?:#|//)\s*tfnlp:ignore\s+([A-Za-z0-9_.-]+(?:\s*,\s*[A-Za-z0-9_.-]+)*)(?:\s+reason="([^"]*)")?\s*$`)
+	// suppressionMarker detects comments meant as suppressions, so malformed
+	// ones are rejected instead of ignored
+	suppressionMarker = regexp.MustCompile(`^(?:#|//)\s*tfnlp:ignore\b`)
+)
+
+// ScanResult holds the issues of a scan. Suppressed issues are accepted
+// risks, kept apart so they can still be reported.
+type ScanResult struct {
+	Issues     []Issue
+	Suppressed []SuppressedIssue
+}
+
+// SuppressedIssue is an issue matched by an inline comment or a baseline entry
+type SuppressedIssue struct {
+	Issue
+	Reason string
+	Source string // SuppressionSourceInline or the baseline file path
+}
+
+// Baseline is a YAML or JSON file of accepted findings
+type Baseline struct {
+	Accepted []BaselineEntry `yaml:"accepted"`
+}
+
+// BaselineEntry accepts the issues of a rule, on one resource or, without
+// a resource, everywhere
+type BaselineEntry struct {
+	Rule     string `yaml:"rule"`
+	Resource string `yaml:"resource"`
+	Reason   string `yaml:"reason"`
+}
+
+// suppression is an inline suppression comment attached to a block
+type suppression struct {
+	rules   []string
+	reason  string
+	address string
+}
+
+// LoadBaseline reads a baseline file; its entries suppress matching issues
+// in every scan. Entries without a rule or a reason are rejected.
+func (s *Scanner) LoadBaseline(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read baseline %s: %w", path, err)
+	}
+
+	var baseline Baseline
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&baseline); err != nil {
+		return fmt.Errorf("baseline %s: failed to decode: %w", path, err)
+	}
+
+	for i, entry := range baseline.Accepted {
+		if entry.Rule == "" {
+			return fmt.Errorf("baseline %s: accepted[%d]: rule is required", path, i)
+		}
+		if strings.TrimSpace(entry.Reason) == "" {
+			return fmt.Errorf("baseline %s: accepted[%d] %s: reason is required", path, i, entry.Rule)
+		}
+	}
+
+	s.baseline = baseline.Accepted
+	s.baselinePath = path
+	return nil
+}
+
+// suppress splits issues into those reported and those suppressed by the
+// configuration's comments or the baseline
+func (s *Scanner) suppress(c *parsedConfig, issues []Issue) (*ScanResult, error) {
+	inline, err := c.suppressions()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ScanResult{}
+	for _, issue := range issues {
+		if reason, ok := inlineReason(inline, issue); ok {
+			result.Suppressed = append(result.Suppressed, SuppressedIssue{Issue: issue, Reason: reason, Source: SuppressionSourceInline})
+			continue
+		}
+		if entry, ok := s.baselineEntry(issue); ok {
+			result.Suppressed = append(result.Suppressed, SuppressedIssue{Issue: issue, Reason: entry.Reason, Source: s.baselinePath})
+			continue
+		}
+		result.Issues = append(result.Issues, issue)
+	}
+
+	return result, nil
+}
+
+// inlineReason returns the reason of the comment suppressing issue
+func inlineReason(suppressions []suppression, issue Issue) (string, bool) {
+	for _, sup := range suppressions {
+		if sup.address == issue.Resource && contains(sup.rules, issue.Rule) {
+			return sup.reason, true
+		}
+	}
+	return "", false
+}
+
+// baselineEntry returns the baseline entry accepting issue
+func (s *Scanner) baselineEntry(issue Issue) (BaselineEntry, bool) {
+	for _, entry := range s.baseline {
+		if entry.Rule == issue.Rule && (entry.Resource == "" || entry.Resource == issue.Resource) {
+			return entry, true
+		}
+	}
+	return BaselineEntry{}, false
+}
+
+// suppressions finds the tfnlp:ignore comments of the configuration. A
+// comment applies to the block it is written in, or to the block that
+// directly follows it.
+func (c *parsedConfig) suppressions() ([]suppression, error) {
+	tokens, diags := hclsyntax.LexConfig(c.source, "main.tf", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to read comments: %s", diags.Error())
+	}
+
+	var suppressions []suppression
+	for i, token := range tokens {
+		if token.Type != hclsyntax.TokenComment {
+			continue
+		}
+		text := strings.TrimSpace(string(token.Bytes))
+		if !suppressionMarker.MatchString(text) {
+			continue
+		}
+
+		line := token.Range.Start.Line
+		match := suppressionPattern.FindStringSubmatch(text)
+		if match == nil {
+			return nil, fmt.Errorf("line %d: malformed suppression %q (use # tfnlp:ignore RULE reason=\"...\")", line, text)
+		}
+		target := c.suppressionTarget(tokens, i)
+		if target == nil {
+			return nil, fmt.Errorf("line %d: suppression of %s is not inside or directly before a block", line, match[1])
+		}
+		if strings.TrimSpace(match[2]) == "" {
+			return nil, fmt.Errorf("line %d: suppression of %s on %s needs a reason=\"...\"", line, match[1], target.Address())
+		}
+
+		var rules []string
+		for _, rule := range strings.Split(match[1], ",") {
+			rules = append(rules, strings.TrimSpace(rule))
+		}
+		suppressions = append(suppressions, suppression{rules: rules, reason: match[2], address: target.Address()})
+	}
+
+	return suppressions, nil
+}
+
+// suppressionTarget returns the block the comment at tokens[i] applies to
+func (c *parsedConfig) suppressionTarget(tokens hclsyntax.Tokens, i int) *block {
+	offset := tokens[i].Range.Start.Byte
+	for _, b := range c.blocks {
+		if offset >= b.Body.SrcRange.Start.Byte && offset < b.Body.SrcRange.End.Byte {
+			return b
+		}
+	}
+
+	for _, token := range tokens[i+1:] {
+		if token.Type == hclsyntax.TokenComment || token.Type == hclsyntax.TokenNewline {
+			continue
+		}
+		for _, b := range c.blocks {
+			if token.Range.Start.Byte == b.Range.Start.Byte {
+				return b
+			}
+		}
+		return nil
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// suppressed returns the suppressed issues of one rule as "resource line:column"
+func suppressed(result *ScanResult, rule string) []string {
+	var issues []Issue
+	for _, s := range result.Suppressed {
+		issues = append(issues, s.Issue)
+	}
+	return reported(issues, rule)
+}
+
+func TestInlineSuppressions(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         string
+		wantSuppressed []string
+		wantReported   []string
+		wantErr        string
+	}{
+		{
+			name: "comment directly before the block",
+			config: `# tfnlp:ignore SEC001 reason="public website"
+resource "aws_s3_bucket" "site" {
+  acl = "public-read"
+}
+`,
+			wantSuppressed: []string{"aws_s3_bucket.site 3:3"},
+		},
+		{
+			name: "comment inside the block",
+			config: `resource "aws_s3_bucket" "site" {
+  // tfnlp:ignore SEC001 reason="public website"
+  acl = "public-read"
+}
+`,
+			wantSuppressed: []string{"aws_s3_bucket.site 3:3"},
+		},
+		{
+			name: "comment applies to the next block only",
+			config: `# tfnlp:ignore SEC011, SEC001 reason="public website"
+resource "aws_s3_bucket" "site" {
+  acl = "public-read"
+}
+
+resource "aws_s3_bucket" "assets" {
+  acl = "public-read"
+}
+`,
+			wantSuppressed: []string{"aws_s3_bucket.site 3:3"},
+			wantReported:   []string{"aws_s3_bucket.assets 7:3"},
+		},
+		{
+			name: "comment after a block does not apply to it",
+			config: `resource "aws_s3_bucket" "site" {
+  acl = "public-read"
+}
+# tfnlp:ignore SEC001 reason="public website"
+resource "aws_s3_bucket" "assets" {
+  bucket = "assets"
+}
+`,
+			wantReported: []string{"aws_s3_bucket.site 2:3"},
+		},
+		{
+			name: "missing reason",
+			config: `resource "aws_s3_bucket" "site" {
+  acl = "public-read"
+}
+
+# tfnlp:ignore SEC001
+resource "aws_s3_bucket" "assets" {
+  acl = "public-read"
+}
+`,
+			wantErr: `line 5: suppression of SEC001 on aws_s3_bucket.assets needs a reason="..."`,
+		},
+		{
+			name: "blank reason",
+			config: `resource "aws_s3_bucket" "site" {
+  # tfnlp:ignore SEC001 reason="  "
+  acl = "public-read"
+}
+`,
+			wantErr: "line 2: suppression of SEC001 on aws_s3_bucket.site needs a reason",
+		},
+		{
+			name: "no rules",
+			config: `# tfnlp:ignore reason="public website"
+resource "aws_s3_bucket" "site" {
+  acl = "public-read"
+}
+`,
+			wantErr: `line 1: malformed suppression "# tfnlp:ignore reason=\"public website\""`,
+		},
+		{
+			name: "unquoted reason",
+			config: `# tfnlp:ignore SEC001 reason=website
+resource "aws_s3_bucket" "site" {
+  acl = "public-read"
+}
+`,
+			wantErr: "line 1: malformed suppression",
+		},
+		{
+			name: "comment before no block",
+			config: `resource "aws_s3_bucket" "site" {
+  acl = "public-read"
+}
+# tfnlp:ignore SEC001 reason="public website"
+`,
+			wantErr: "line 4: suppression of SEC001 is not inside or directly before a block",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewScanner().Scan(tt.config)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Scan() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+
+			if got := suppressed(result, "SEC001"); strings.Join(got, ",") != strings.Join(tt.wantSuppressed, ",") {
+				t.Errorf("suppressed SEC001 = %v, want %v", got, tt.wantSuppressed)
+			}
+			if got := reported(result.Issues, "SEC001"); strings.Join(got, ",") != strings.Join(tt.wantReported, ",") {
+				t.Errorf("reported SEC001 = %v, want %v", got, tt.wantReported)
+			}
+			for _, s := range result.Suppressed {
+				if s.Source != SuppressionSourceInline || s.Reason != "public website" {
+					t.Errorf("suppressed issue = %+v, want an inline suppression with its reason", s)
+				}
+			}
+		})
+	}
+}
+
+func writeBaseline(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "baseline.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write baseline: %v", err)
+	}
+	return path
+}
+
+func TestLoadBaseline(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "valid",
+			content: "accepted:\n  - rule: SEC008\n    resource: aws_lb_listener.web\n    reason: TLS terminates at the CDN\n",
+		},
+		{
+			name:    "missing rule",
+			content: "accepted:\n  - resource: aws_lb_listener.web\n    reason: TLS terminates at the CDN\n",
+			wantErr: "accepted[0]: rule is required",
+		},
+		{
+			name:    "missing reason",
+			content: "accepted:\n  - rule: SEC008\n  - rule: SEC011\n    reason: \" \"\n",
+			wantErr: "accepted[0] SEC008: reason is required",
+		},
+		{
+			name:    "unknown field",
+			content: "accepted:\n  - rule: SEC008\n    reson: typo\n",
+			wantErr: "field reson not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := NewScanner()
+			path := writeBaseline(t, tt.content)
+			err := scanner.LoadBaseline(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), "baseline "+path) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("LoadBaseline() error = %v, want %q", err, tt.wantErr)
+				}
+				if scanner.baseline != nil {
+					t.Errorf("LoadBaseline() kept entries %v after an error", scanner.baseline)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("LoadBaseline() error = %v", err)
+			}
+		})
+	}
+
+	if err := NewScanner().LoadBaseline(""); err != nil {
+		t.Errorf("LoadBaseline(\"\") error = %v, want none", err)
+	}
+	if err := NewScanner().LoadBaseline(filepath.Join(t.TempDir(), "missing.yaml")); err == nil || !strings.Contains(err.Error(), "failed to read baseline") {
+		t.Errorf("LoadBaseline() error = %v, want a read error", err)
+	}
+}
+
+func TestBaselineSuppression(t *testing.T) {
+	path := writeBaseline(t, `accepted:
+  - rule: SEC001
+    resource: aws_s3_bucket.site
+    reason: public website
+  - rule: SEC012
+    reason: versioning is handled by backups
+`)
+	scanner := NewScanner()
+	if err := scanner.LoadBaseline(path); err != nil {
+		t.Fatalf("LoadBaseline() error = %v", err)
+	}
+
+	result := scan(t, scanner, `resource "aws_s3_bucket" "site" {
+  acl = "public-read"
+}
+
+resource "aws_s3_bucket" "assets" {
+  acl = "public-read"
+}
+`)
+
+	tests := []struct {
+		rule           string
+		wantSuppressed []string
+		wantReported   []string
+	}{
+		{"SEC001", []string{"aws_s3_bucket.site 2:3"}, []string{"aws_s3_bucket.assets 6:3"}},
+		{"SEC012", []string{"aws_s3_bucket.assets 5:1", "aws_s3_bucket.site 1:1"}, nil},
+	}
+	for _, tt := range tests {
+		if got := suppressed(result, tt.rule); strings.Join(got, ",") != strings.Join(tt.wantSuppressed, ",") {
+			t.Errorf("suppressed %s = %v, want %v", tt.rule, got, tt.wantSuppressed)
+		}
+		if got := reported(result.Issues, tt.rule); strings.Join(got, ",") != strings.Join(tt.wantReported, ",") {
+			t.Errorf("reported %s = %v, want %v", tt.rule, got, tt.wantReported)
+		}
+	}
+
+	for _, s := range result.Suppressed {
+		if s.Source != path || (s.Rule == "SEC001" && s.Reason != "public website") {
+			t.Errorf("suppressed issue = %+v, want the baseline entry's reason and path", s)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package terraform
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+
+	"github.com/RyanSStephens/TF-NLP-Agent/internal/nlp"
+)
+
+// instancePrices are rough on-demand monthly prices in USD per instance type
+var instancePrices = map[string]float64{
+	// AWS EC2
+	"t3.nano":    3.8,
+	"t3.micro":   7.6,
+	"t3.small":   15.2,
+	"t3.medium":  30.4,
+	"t3.large":   60.7,
+	"m5.large":   70.1,
+	"m5.xlarge":  140.2,
+	"m5.2xlarge": 280.3,
+	// AWS RDS
+	"db.t3.micro":   12.4,
+	"db.t3.small":   24.8,
+	"db.t3.medium":  49.6,
+	"db.m5.large":   124.8,
+	"db.m5.xlarge":  249.7,
+	"db.m5.2xlarge": 499.4,
+	// Azure virtual machines
+	"Standard_B1s":    7.6,
+	"Standard_B2s":    30.4,
+	"Standard_D2s_v3": 70.1,
+	"Standard_D4s_v3": 140.2,
+	"Standard_D8s_v3": 280.3,
+	// GCP Compute Engine
+	"e2-micro":      6.1,
+	"e2-small":      12.2,
+	"e2-medium":     24.5,
+	"n2-standard-2": 70.9,
+	"n2-standard-4": 141.8,
+	"n2-standard-8": 283.6,
+}
+
+// instanceCost returns the monthly cost of the resources of the given types,
+// priced by the instance type in attribute and multiplied by their count.
+// Resources with an unknown instance type, or a configuration that cannot be
+// inspected, cost fallback each.
+func instanceCost(file *hclwrite.File, attribute string, fallback float64, resourceTypes ...string) float64 {
+	if file == nil {
+		return fallback
+	}
+
+	defaults := variableDefaults(file)
+	total, found := 0.0, false
+
+	for _, block := range file.Body().Blocks() {
+		if block.Type() != "resource" || len(block.Labels()) == 0 || !contains(resourceTypes, block.Labels()[0]) {
+			continue
+		}
+		found = true
+
+		price, ok := instancePrices[literalValue(block.Body(), attribute, defaults)]
+		if !ok {
+			price = fallback
+		}
+		count, err := strconv.Atoi(literalValue(block.Body(), "count", defaults))
+		if err != nil {
+			count = 1
+		}
+		total += price * float64(count)
+	}
+
+	if !found {
+		return fallback
+	}
+	return total
+}
+
+// variableDefaults returns the literal default of every variable that has one
+func variableDefaults(file *hclwrite.File) map[string]string {
+	defaults := make(map[string]string)
+	for _, block := range file.Body().Blocks() {
+		if block.Type() == "variable" && len(block.Labels()) > 0 {
+			if value := literalValue(block.Body(), "default", nil); value != "" {
+				defaults[block.Labels()[0]] = value
+			}
+		}
+	}
+	return defaults
+}
+
+// literalValue returns the value of a string or number attribute, resolving
+// a plain variable reference to its default. It is empty for other expressions.
+func literalValue(body *hclwrite.Body, name string, defaults map[string]string) string {
+	attribute := body.GetAttribute(name)
+	if attribute == nil {
+		return ""
+	}
+
+	value := strings.TrimSpace(string(attribute.Expr().BuildTokens(nil).Bytes()))
+	if strings.HasPrefix(value, "var.") {
+		return defaults[strings.TrimPrefix(value, "var.")]
+	}
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		return unquoted
+	}
+	if _, err := strconv.Atoi(value); err == nil {
+		return value
+	}
+	return ""
+}
+
+// BudgetWarning compares the estimated monthly costs with the stated budget.
+// It returns a warning naming the largest items when the total exceeds the
+// budget, and an empty string otherwise.
+func BudgetWarning(costs map[string]float64, budget *nlp.Budget) string {
+	if budget == nil {
+		return ""
+	}
+
+	total := 0.0
+	items := make([]string, 0, len(costs))
+	for item, cost := range costs {
+		total += cost
+		items = append(items, item)
+	}
+	if total <= budget.Amount {
+		return ""
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if costs[items[i]] != costs[items[j]] {
+			return costs[items[i]] > costs[items[j]]
+		}
+		return items[i] < items[j]
+	})
+
+	if len(items) > 3 {
+		items = items[:3]
+	}
+	var largest []string
+	for _, item := range items {
+		largest = append(largest, fmt.Sprintf("%s $%.2f", item, costs[item]))
+	}
+
+	return fmt.Sprintf("estimated cost of $%.2f/month exceeds the budget of %s (largest items: %s)", total, budget, strings.Join(largest, ", "))
+}
@@ -0,0 +1,168 @@
+package terraform
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+
+	"github.com/RyanSStephens/TF-NLP-Agent/internal/nlp"
+)
+
+func TestInstanceCost(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   float64
+	}{
+		{
+			name:   "known instance type",
+			config: `resource "aws_instance" "web" { instance_type = "t3.medium" }`,
+			want:   30.4,
+		},
+		{
+			name: "known instance type with count",
+			config: `resource "aws_instance" "web" {
+  instance_type = "t3.micro"
+  count         = 3
+}`,
+			want: 3 * 7.6,
+		},
+		{
+			name: "instance type from variable default",
+			config: `variable "size" { default = "m5.large" }
+resource "aws_instance" "web" { instance_type = var.size }`,
+			want: 70.1,
+		},
+		{
+			name:   "unknown instance type",
+			config: `resource "aws_instance" "web" { instance_type = "x9.huge" }`,
+			want:   50.0,
+		},
+		{
+			name:   "empty instance type",
+			config: `resource "aws_instance" "web" { instance_type = "" }`,
+			want:   50.0,
+		},
+		{
+			name:   "instance type not set",
+			config: `resource "aws_instance" "web" { ami = "ami-123" }`,
+			want:   50.0,
+		},
+		{
+			name: "known and unknown summed",
+			config: `resource "aws_instance" "web" { instance_type = "t3.small" }
+resource "aws_instance" "worker" { instance_type = "x9.huge" }`,
+			want: 15.2 + 50.0,
+		},
+		{
+			name:   "no matching resources",
+			config: `resource "aws_s3_bucket" "logs" {}`,
+			want:   50.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, diags := hclwrite.ParseConfig([]byte(tt.config), "main.tf", hcl.Pos{Line: 1, Column: 1})
+			if diags.HasErrors() {
+				t.Fatalf("ParseConfig() error = %s", diags.Error())
+			}
+
+			if got := instanceCost(file, "instance_type", 50.0, "aws_instance"); !closeTo(got, tt.want) {
+				t.Errorf("instanceCost() = %.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+
+	if got := instanceCost(nil, "instance_type", 50.0, "aws_instance"); got != 50.0 {
+		t.Errorf("instanceCost(nil) = %.2f, want the fallback", got)
+	}
+}
+
+func TestEstimateCostInstanceTypes(t *testing.T) {
+	g := NewGenerator()
+
+	costs, err := g.EstimateCost(`resource "aws_instance" "web" {
+  instance_type = "t3.large"
+  count         = 2
+}
+
+resource "aws_db_instance" "main" {
+  instance_class = "db.t3.micro"
+}
+`)
+	if err != nil {
+		t.Fatalf("EstimateCost() error = %v", err)
+	}
+
+	if !closeTo(costs["EC2 Instances"], 2*60.7) {
+		t.Errorf("EC2 Instances = %.2f, want %.2f", costs["EC2 Instances"], 2*60.7)
+	}
+	if !closeTo(costs["RDS Database"], 12.4) {
+		t.Errorf("RDS Database = %.2f, want 12.40", costs["RDS Database"])
+	}
+}
+
+func TestBudgetWarning(t *testing.T) {
+	costs := map[string]float64{
+		"EC2 Instances": 121.4,
+		"RDS Database":  100.0,
+		"Load Balancer": 25.0,
+		"S3 Storage":    10.0,
+	}
+
+	tests := []struct {
+		name   string
+		costs  map[string]float64
+		budget *nlp.Budget
+		want   string
+	}{
+		{
+			name:   "nil budget",
+			costs:  costs,
+			budget: nil,
+		},
+		{
+			name:   "under budget",
+			costs:  costs,
+			budget: &nlp.Budget{Amount: 300, Currency: "USD"},
+		},
+		{
+			name:   "exactly on budget",
+			costs:  map[string]float64{"S3 Storage": 10.0},
+			budget: &nlp.Budget{Amount: 10, Currency: "USD"},
+		},
+		{
+			name:   "over budget names the three largest items",
+			costs:  costs,
+			budget: &nlp.Budget{Amount: 200, Currency: "USD"},
+			want:   "estimated cost of $256.40/month exceeds the budget of $200/month (largest items: EC2 Instances $121.40, RDS Database $100.00, Load Balancer $25.00)",
+		},
+		{
+			name:   "over budget with one item",
+			costs:  map[string]float64{"GKE Cluster": 150.0},
+			budget: &nlp.Budget{Amount: 100, Currency: "USD"},
+			want:   "estimated cost of $150.00/month exceeds the budget of $100/month (largest items: GKE Cluster $150.00)",
+		},
+		{
+			name:   "no costs",
+			costs:  map[string]float64{},
+			budget: &nlp.Budget{Amount: 0, Currency: "USD"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BudgetWarning(tt.costs, tt.budget); got != tt.want {
+				t.Errorf("BudgetWarning() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// closeTo compares prices summed from floats to the cent
+func closeTo(got, want float64) bool {
+	return math.Abs(got-want) < 0.005
+}
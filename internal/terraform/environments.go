@@ -0,0 +1,189 @@
+package terraform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/RyanSStephens/TF-NLP-Agent/internal/nlp"
+)
+
+// sharedModuleDir is where the shared configuration is written, relative to the output directory
+const sharedModuleDir = "modules/stack"
+
+// Environment holds the variable values one environment sets on the shared module.
+// Values are HCL literals, e.g. "true", "3" or "\"prod\"".
+type Environment struct {
+	Name      string
+	Variables map[string]string
+}
+
+// EnvironmentLayout is a multi-environment project: a shared module plus a
+// root configuration and tfvars file per environment, keyed by relative path
+type EnvironmentLayout struct {
+	Files    map[string]string
+	Warnings []string
+}
+
+// EnvironmentsFrom returns the environments named in the parsed input with
+// the variable overrides of the requirements scoped to each
+func EnvironmentsFrom(parsed *nlp.ParsedInput) []Environment {
+	var environments []Environment
+
+	for _, name := range parsed.EnvironmentNames() {
+		environment := Environment{
+			Name:      name,
+			Variables: map[string]string{"environment": fmt.Sprintf("%q", name)},
+		}
+		for _, requirement := range parsed.Requirements {
+			if requirement.Environment != name {
+				continue
+			}
+			if variable, value, ok := requirement.EnvironmentVariable(); ok {
+				environment.Variables[variable] = value
+			}
+		}
+		environments = append(environments, environment)
+	}
+
+	return environments
+}
+
+// SplitEnvironments turns a configuration parameterized by variables into a
+// shared module and one root configuration per environment. terraform and
+// provider blocks move to the roots, which pass every module variable
+// through and re-export the module outputs. Overrides for variables the
+// configuration does not declare are reported as warnings.
+func (g *Generator) SplitEnvironments(config string, environments []Environment) (*EnvironmentLayout, error) {
+	file, diags := hclwrite.ParseConfig([]byte(config), "main.tf", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse HCL: %s", diags.Error())
+	}
+
+	module := hclwrite.NewEmptyFile()
+	var rootBlocks, variableBlocks [][]byte
+	var variables, outputs []string
+
+	for _, block := range file.Body().Blocks() {
+		tokens := block.BuildTokens(nil).Bytes()
+
+		switch block.Type() {
+		case "terraform", "provider":
+			rootBlocks = append(rootBlocks, tokens)
+			continue
+		case "variable":
+			variableBlocks = append(variableBlocks, tokens)
+			variables = append(variables, block.Labels()[0])
+		case "output":
+			outputs = append(outputs, block.Labels()[0])
+		}
+
+		module.Body().AppendUnstructuredTokens(block.BuildTokens(nil))
+		module.Body().AppendNewline()
+	}
+
+	layout := &EnvironmentLayout{
+		Files: map[string]string{
+			filepath.Join(sharedModuleDir, "main.tf"): string(hclwrite.Format(module.Bytes())),
+		},
+	}
+
+	if !contains(variables, "environment") {
+		layout.Warnings = append(layout.Warnings, "configuration declares no environment variable; resources will not be named per environment")
+	}
+
+	for _, environment := range environments {
+		dir := filepath.Join("environments", environment.Name)
+
+		root := hclwrite.NewEmptyFile()
+		call := root.Body().AppendNewBlock("module", []string{"stack"}).Body()
+		call.SetAttributeValue("source", cty.StringVal("../../"+sharedModuleDir))
+		for _, variable := range variables {
+			call.SetAttributeTraversal(variable, hcl.Traversal{
+				hcl.TraverseRoot{Name: "var"},
+				hcl.TraverseAttr{Name: variable},
+			})
+		}
+
+		for _, output := range outputs {
+			root.Body().AppendNewline()
+			root.Body().AppendNewBlock("output", []string{output}).Body().SetAttributeTraversal("value", hcl.Traversal{
+				hcl.TraverseRoot{Name: "module"},
+				hcl.TraverseAttr{Name: "stack"},
+				hcl.TraverseAttr{Name: output},
+			})
+		}
+
+		main := append(toStrings(rootBlocks), string(root.Bytes()))
+		layout.Files[filepath.Join(dir, "main.tf")] = string(hclwrite.Format([]byte(strings.Join(main, "\n"))))
+		layout.Files[filepath.Join(dir, "variables.tf")] = string(hclwrite.Format([]byte(strings.Join(toStrings(variableBlocks), "\n"))))
+
+		var tfvars strings.Builder
+		for _, name := range sortedKeys(environment.Variables) {
+			if !contains(variables, name) {
+				if name != "environment" {
+					layout.Warnings = append(layout.Warnings, fmt.Sprintf("%s: ignoring %s = %s, the configuration declares no %s variable", environment.Name, name, environment.Variables[name], name))
+				}
+				continue
+			}
+			tfvars.WriteString(fmt.Sprintf("%s = %s\n", name, environment.Variables[name]))
+		}
+		layout.Files[filepath.Join(dir, "terraform.tfvars")] = string(hclwrite.Format([]byte(tfvars.String())))
+	}
+
+	return layout, nil
+}
+
+// Write writes the layout's files below dir
+func (l *EnvironmentLayout) Write(dir string) error {
+	for _, path := range l.Paths() {
+		target := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+		}
+		if err := os.WriteFile(target, []byte(l.Files[path]), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+	}
+	return nil
+}
+
+// Paths returns the relative file paths of the layout in sorted order
+func (l *EnvironmentLayout) Paths() []string {
+	return sortedKeys(l.Files)
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// toStrings converts byte slices to strings
+func toStrings(values [][]byte) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		result = append(result, string(value))
+	}
+	return result
+}
+
+// contains reports whether value is in values
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/RyanSStephens/TF-NLP-Agent/internal/nlp"
+)
+
+const environmentConfig = `terraform {
+  required_version = ">= 1.0"
+}
+
+provider "aws" {
+  region = "us-east-1"
+}
+
+variable "environment" {
+  type = string
+}
+
+variable "multi_az" {
+  type    = bool
+  default = false
+}
+
+resource "aws_db_instance" "main" {
+  identifier = "db-${var.environment}"
+  multi_az   = var.multi_az
+}
+
+output "endpoint" {
+  value = aws_db_instance.main.endpoint
+}
+`
+
+func TestEnvironmentsFrom(t *testing.T) {
+	engine := nlp.NewEngine()
+
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]map[string]string
+	}{
+		{
+			name:  "no environment",
+			input: "Create an encrypted S3 bucket",
+			want:  map[string]map[string]string{},
+		},
+		{
+			name:  "single environment passes through",
+			input: "Create a prod database that is multi-az",
+			want: map[string]map[string]string{
+				"prod": {"environment": `"prod"`},
+			},
+		},
+		{
+			name:  "scoped requirements become variables",
+			input: "Deploy the same web server and postgres database for dev and prod, with prod multi-AZ and 3 servers in prod",
+			want: map[string]map[string]string{
+				"dev":  {"environment": `"dev"`},
+				"prod": {"environment": `"prod"`, "multi_az": "true", "instance_count": "3"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := engine.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			environments := EnvironmentsFrom(parsed)
+			if len(environments) != len(tt.want) {
+				t.Fatalf("EnvironmentsFrom() = %v, want %d environments", environments, len(tt.want))
+			}
+			for _, environment := range environments {
+				want, ok := tt.want[environment.Name]
+				if !ok {
+					t.Errorf("unexpected environment %q", environment.Name)
+					continue
+				}
+				if len(environment.Variables) != len(want) {
+					t.Errorf("%s variables = %v, want %v", environment.Name, environment.Variables, want)
+				}
+				for name, value := range want {
+					if environment.Variables[name] != value {
+						t.Errorf("%s %s = %q, want %q", environment.Name, name, environment.Variables[name], value)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSplitEnvironments(t *testing.T) {
+	g := NewGenerator()
+
+	layout, err := g.SplitEnvironments(environmentConfig, []Environment{
+		{Name: "dev", Variables: map[string]string{"environment": `"dev"`}},
+		{Name: "prod", Variables: map[string]string{"environment": `"prod"`, "multi_az": "true", "instance_count": "3"}},
+	})
+	if err != nil {
+		t.Fatalf("SplitEnvironments() error = %v", err)
+	}
+
+	wantPaths := []string{
+		filepath.Join("environments", "dev", "main.tf"),
+		filepath.Join("environments", "dev", "terraform.tfvars"),
+		filepath.Join("environments", "dev", "variables.tf"),
+		filepath.Join("environments", "prod", "main.tf"),
+		filepath.Join("environments", "prod", "terraform.tfvars"),
+		filepath.Join("environments", "prod", "variables.tf"),
+		filepath.Join("modules", "stack", "main.tf"),
+	}
+	if got := layout.Paths(); strings.Join(got, ",") != strings.Join(wantPaths, ",") {
+		t.Fatalf("Paths() = %v, want %v", got, wantPaths)
+	}
+
+	module := layout.Files[filepath.Join("modules", "stack", "main.tf")]
+	for _, want := range []string{`variable "environment"`, `resource "aws_db_instance" "main"`, `output "endpoint"`} {
+		if !strings.Contains(module, want) {
+			t.Errorf("module is missing %s:\n%s", want, module)
+		}
+	}
+	for _, moved := range []string{"terraform {", `provider "aws"`} {
+		if strings.Contains(module, moved) {
+			t.Errorf("module still contains %s:\n%s", moved, module)
+		}
+	}
+
+	root := layout.Files[filepath.Join("environments", "prod", "main.tf")]
+	for _, want := range []string{`provider "aws"`, `source      = "../../modules/stack"`, "environment = var.environment", "multi_az    = var.multi_az", "value = module.stack.endpoint"} {
+		if !strings.Contains(root, want) {
+			t.Errorf("prod main.tf is missing %q:\n%s", want, root)
+		}
+	}
+	if variables := layout.Files[filepath.Join("environments", "prod", "variables.tf")]; !strings.Contains(variables, `variable "multi_az"`) {
+		t.Errorf("prod variables.tf does not declare multi_az:\n%s", variables)
+	}
+
+	if got, want := layout.Files[filepath.Join("environments", "dev", "terraform.tfvars")], "environment = \"dev\"\n"; got != want {
+		t.Errorf("dev terraform.tfvars = %q, want %q", got, want)
+	}
+	if got, want := layout.Files[filepath.Join("environments", "prod", "terraform.tfvars")], "environment = \"prod\"\nmulti_az    = true\n"; got != want {
+		t.Errorf("prod terraform.tfvars = %q, want %q", got, want)
+	}
+
+	if len(layout.Warnings) != 1 || !strings.Contains(layout.Warnings[0], "prod: ignoring instance_count = 3") {
+		t.Errorf("Warnings = %v, want the undeclared instance_count override", layout.Warnings)
+	}
+}
+
+func TestSplitEnvironmentsWithoutEnvironmentVariable(t *testing.T) {
+	g := NewGenerator()
+
+	layout, err := g.SplitEnvironments(`resource "aws_s3_bucket" "logs" {}`, []Environment{
+		{Name: "dev", Variables: map[string]string{"environment": `"dev"`}},
+		{Name: "prod", Variables: map[string]string{"environment": `"prod"`}},
+	})
+	if err != nil {
+		t.Fatalf("SplitEnvironments() error = %v", err)
+	}
+
+	if len(layout.Warnings) != 1 || !strings.Contains(layout.Warnings[0], "declares no environment variable") {
+		t.Errorf("Warnings = %v, want a missing environment variable warning", layout.Warnings)
+	}
+	if tfvars := layout.Files[filepath.Join("environments", "dev", "terraform.tfvars")]; tfvars != "" {
+		t.Errorf("dev terraform.tfvars = %q, want empty", tfvars)
+	}
+
+	if _, err := g.SplitEnvironments(`resource "aws_s3_bucket" "logs" {`, nil); err == nil {
+		t.Error("SplitEnvironments() accepted invalid HCL")
+	}
+}
+
+func TestEnvironmentLayoutWrite(t *testing.T) {
+	layout := &EnvironmentLayout{Files: map[string]string{
+		filepath.Join("modules", "stack", "main.tf"):             "# module\n",
+		filepath.Join("environments", "dev", "terraform.tfvars"): "environment = \"dev\"\n",
+	}}
+
+	dir := t.TempDir()
+	if err := layout.Write(dir); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	for path, want := range layout.Files {
+		got, err := os.ReadFile(filepath.Join(dir, path))
+		if err != nil {
+			t.Errorf("%s was not written: %v", path, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -331,6 +331,7 @@ resource "aws_vpc" "main" {
 }
 
 # Security Group for ALB
+# tfnlp:ignore SEC003 reason="public ALB serves HTTP and HTTPS to the internet"
 resource "aws_security_group" "alb" {
   name        = "${var.environment}-alb-sg"
   description = "Security group for Application Load Balancer"
@@ -674,19 +675,24 @@ output "kubernetes_cluster_host" {
 	return template
 }
 
-// EstimateCost provides a rough cost estimation for the configuration
+// EstimateCost provides a rough cost estimation for the configuration.
+// Instances are priced by their instance type where it is known.
 func (g *Generator) EstimateCost(config string) (map[string]float64, error) {
 	costs := make(map[string]float64)
 
 	// Simple cost estimation based on resource types
 	// In production, this would integrate with cloud provider pricing APIs
+	file, diags := hclwrite.ParseConfig([]byte(config), "config.tf", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		file = nil
+	}
 
 	if strings.Contains(config, "aws_instance") {
-		costs["EC2 Instances"] = 50.0 // Rough monthly estimate
+		costs["EC2 Instances"] = instanceCost(file, "instance_type", 50.0, "aws_instance") // Rough monthly estimate
 	}
 
-	if strings.Contains(config, "aws_rds_instance") {
-		costs["RDS Database"] = 100.0
+	if strings.Contains(config, "aws_rds_instance") || strings.Contains(config, "aws_db_instance") {
+		costs["RDS Database"] = instanceCost(file, "instance_class", 100.0, "aws_db_instance")
 	}
 
 	if strings.Contains(config, "aws_lb") {
@@ -697,6 +703,14 @@ func (g *Generator) EstimateCost(config string) (map[string]float64, error) {
 		costs["S3 Storage"] = 10.0
 	}
 
+	if strings.Contains(config, "azurerm_linux_virtual_machine") || strings.Contains(config, "azurerm_windows_virtual_machine") {
+		costs["Virtual Machines"] = instanceCost(file, "size", 50.0, "azurerm_linux_virtual_machine", "azurerm_windows_virtual_machine")
+	}
+
+	if strings.Contains(config, "google_compute_instance") {
+		costs["Compute Engine"] = instanceCost(file, "machine_type", 50.0, "google_compute_instance")
+	}
+
 	if strings.Contains(config, "google_container_cluster") {
 		costs["GKE Cluster"] = 150.0
 	}
@@ -14,36 +14,80 @@ import (
 type Server struct {
 	router      *gin.Engine
 	aiProvider  ai.Provider
-	nlpEngine   *nlp.Engine
+	engine      *nlp.Engine
+	parser      nlp.Parser
 	tfGenerator *terraform.Generator
 	secScanner  *security.Scanner
 }
 
 // GenerateRequest represents a generation request
 type GenerateRequest struct {
-	Description string `json:"description" binding:"required"`
-	Provider    string `json:"provider,omitempty"`
+	Description string            `json:"description" binding:"required"`
+	Provider    string            `json:"provider,omitempty"`
+	Answers     map[string]string `json:"answers,omitempty"`
+	UseDefaults bool              `json:"use_defaults,omitempty"`
 }
 
 // GenerateResponse represents a generation response
 type GenerateResponse struct {
-	Configuration string             `json:"configuration"`
-	Issues        []security.Issue   `json:"issues,omitempty"`
-	Costs         map[string]float64 `json:"estimated_costs,omitempty"`
-	Success       bool               `json:"success"`
-	Error         string             `json:"error,omitempty"`
+	Configuration string                     `json:"configuration"`
+	Issues        []security.Issue           `json:"issues,omitempty"`
+	Suppressed    []security.SuppressedIssue `json:"suppressed,omitempty"`
+	Costs         map[string]float64         `json:"estimated_costs,omitempty"`
+	Files         map[string]string          `json:"files,omitempty"`
+	Warnings      []string                   `json:"warnings,omitempty"`
+	Corrections   []nlp.Correction           `json:"corrections,omitempty"`
+	Status        string                     `json:"status,omitempty"`
+	Questions     []nlp.Question             `json:"questions,omitempty"`
+	Success       bool                       `json:"success"`
+	Error         string                     `json:"error,omitempty"`
 }
 
-// NewServer creates a new web server
-func NewServer() *Server {
+// ParseResponse represents a parse-only response: the structured
+// interpretation of a description and the prompt generation would send
+type ParseResponse struct {
+	Parsed    *nlp.ParsedInput `json:"parsed,omitempty"`
+	Questions []nlp.Question   `json:"questions,omitempty"`
+	Prompt    string           `json:"prompt,omitempty"`
+	Success   bool             `json:"success"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// RemediateRequest is a configuration to fix; Filename names it in the diff
+type RemediateRequest struct {
+	Configuration string `json:"configuration" binding:"required"`
+	Filename      string `json:"filename,omitempty"`
+}
+
+// RemediateResponse is the patched configuration, a unified diff, the fixes
+// applied and the issues that remain
+type RemediateResponse struct {
+	Configuration string                     `json:"configuration"`
+	Diff          string                     `json:"diff,omitempty"`
+	Fixes         []security.Fix             `json:"fixes,omitempty"`
+	Issues        []security.Issue           `json:"issues,omitempty"`
+	Suppressed    []security.SuppressedIssue `json:"suppressed,omitempty"`
+	Success       bool                       `json:"success"`
+	Error         string                     `json:"error,omitempty"`
+}
+
+// StatusNeedsClarification is returned when the description must be clarified
+// before generation; the client answers the questions in a follow-up request
+const StatusNeedsClarification = "needs_clarification"
+
+// NewServer creates a new web server that parses descriptions with parser
+// and scans configurations with scanner. The keyword engine asks the
+// clarifying questions and answers parse-only requests without a model call.
+func NewServer(engine *nlp.Engine, parser nlp.Parser, scanner *security.Scanner) *Server {
 	gin.SetMode(gin.ReleaseMode)
 
 	server := &Server{
 		router:      gin.Default(),
 		aiProvider:  ai.NewProvider("openai"),
-		nlpEngine:   nlp.NewEngine(),
+		engine:      engine,
+		parser:      parser,
 		tfGenerator: terraform.NewGenerator(),
-		secScanner:  security.NewScanner(),
+		secScanner:  scanner,
 	}
 
 	server.setupRoutes()
@@ -56,7 +100,9 @@ func (s *Server) setupRoutes() {
 	api := s.router.Group("/api/v1")
 	{
 		api.POST("/generate", s.handleGenerate)
+		api.POST("/parse", s.handleParse)
 		api.POST("/validate", s.handleValidate)
+		api.POST("/remediate", s.handleRemediate)
 		api.GET("/health", s.handleHealth)
 	}
 
@@ -91,7 +137,7 @@ func (s *Server) handleGenerate(c *gin.Context) {
 	}
 
 	// Parse natural language input
-	parsed, err := s.nlpEngine.Parse(req.Description)
+	parsed, err := s.parser.Parse(req.Description)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, GenerateResponse{
 			Success: false,
@@ -105,6 +151,41 @@ func (s *Server) handleGenerate(c *gin.Context) {
 		parsed.CloudProvider = req.Provider
 	}
 
+	// Ask the client to clarify ambiguous descriptions before generating
+	var questions []nlp.Question
+	for _, question := range s.engine.Clarify(parsed) {
+		// An explicit provider in the request already answers this one
+		if question.ID == nlp.QuestionCloudProvider && req.Provider != "" {
+			continue
+		}
+		questions = append(questions, question)
+	}
+
+	answers := req.Answers
+	if req.UseDefaults {
+		answers = nlp.DefaultAnswers(questions)
+		for id, answer := range req.Answers {
+			answers[id] = answer
+		}
+	}
+
+	if pending := nlp.PendingQuestions(questions, answers); len(pending) > 0 {
+		c.JSON(http.StatusOK, GenerateResponse{
+			Status:    StatusNeedsClarification,
+			Questions: pending,
+			Success:   false,
+		})
+		return
+	}
+
+	if err := s.engine.ApplyAnswers(parsed, answers); err != nil {
+		c.JSON(http.StatusBadRequest, GenerateResponse{
+			Success: false,
+			Error:   "Invalid clarification answers: " + err.Error(),
+		})
+		return
+	}
+
 	// Generate configuration using AI
 	config, err := s.aiProvider.GenerateConfig(parsed)
 	if err != nil {
@@ -126,7 +207,7 @@ func (s *Server) handleGenerate(c *gin.Context) {
 	}
 
 	// Security scan
-	issues, err := s.secScanner.Scan(validated)
+	result, err := s.secScanner.Scan(validated)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, GenerateResponse{
 			Success: false,
@@ -134,6 +215,17 @@ func (s *Server) handleGenerate(c *gin.Context) {
 		})
 		return
 	}
+	issues := append(result.Issues, s.secScanner.CheckRequirements(validated, parsed.Requirements)...)
+
+	complianceIssues, err := s.secScanner.CheckCompliance(validated, parsed.FrameworkIDs())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, GenerateResponse{
+			Success: false,
+			Error:   "Compliance check failed: " + err.Error(),
+		})
+		return
+	}
+	issues = append(issues, complianceIssues...)
 
 	// Cost estimation
 	costs, err := s.tfGenerator.EstimateCost(validated)
@@ -142,11 +234,88 @@ func (s *Server) handleGenerate(c *gin.Context) {
 		costs = make(map[string]float64)
 	}
 
-	c.JSON(http.StatusOK, GenerateResponse{
+	response := GenerateResponse{
 		Configuration: validated,
 		Issues:        issues,
+		Suppressed:    result.Suppressed,
 		Costs:         costs,
+		Corrections:   parsed.Corrections,
 		Success:       true,
+	}
+
+	// Several environments also get a shared module and a root per environment
+	if environments := terraform.EnvironmentsFrom(parsed); len(environments) > 1 {
+		layout, err := s.tfGenerator.SplitEnvironments(validated, environments)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, GenerateResponse{
+				Success: false,
+				Error:   "Failed to split configuration into environments: " + err.Error(),
+			})
+			return
+		}
+		response.Files = layout.Files
+		response.Warnings = layout.Warnings
+	}
+
+	if warning := terraform.BudgetWarning(costs, parsed.Budget); warning != "" {
+		response.Warnings = append(response.Warnings, warning)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// handleParse returns what the keyword engine understood from a description
+// and the prompt generation would send, without calling the model.
+// Ambiguities are resolved with the given answers or the suggested defaults;
+// the questions are returned either way.
+func (s *Server) handleParse(c *gin.Context) {
+	var req GenerateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ParseResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	parsed, err := s.engine.Parse(req.Description)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ParseResponse{
+			Success: false,
+			Error:   "Failed to parse description: " + err.Error(),
+		})
+		return
+	}
+
+	if req.Provider != "" {
+		parsed.CloudProvider = req.Provider
+	}
+
+	var questions []nlp.Question
+	for _, question := range s.engine.Clarify(parsed) {
+		if question.ID == nlp.QuestionCloudProvider && req.Provider != "" {
+			continue
+		}
+		questions = append(questions, question)
+	}
+
+	answers := nlp.DefaultAnswers(questions)
+	for id, answer := range req.Answers {
+		answers[id] = answer
+	}
+	if err := s.engine.ApplyAnswers(parsed, answers); err != nil {
+		c.JSON(http.StatusBadRequest, ParseResponse{
+			Success: false,
+			Error:   "Invalid clarification answers: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ParseResponse{
+		Parsed:    parsed,
+		Questions: questions,
+		Prompt:    ai.BuildPrompt(parsed),
+		Success:   true,
 	})
 }
 
@@ -175,7 +344,7 @@ func (s *Server) handleValidate(c *gin.Context) {
 	}
 
 	// Security scan
-	issues, err := s.secScanner.Scan(req.Configuration)
+	result, err := s.secScanner.Scan(req.Configuration)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -185,8 +354,52 @@ func (s *Server) handleValidate(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"issues":  issues,
+		"success":    true,
+		"issues":     result.Issues,
+		"suppressed": result.Suppressed,
+	})
+}
+
+// handleRemediate applies the automatic fixes of the security rules to a
+// configuration
+func (s *Server) handleRemediate(c *gin.Context) {
+	var req RemediateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, RemediateResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if _, err := s.tfGenerator.Validate(req.Configuration); err != nil {
+		c.JSON(http.StatusOK, RemediateResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	filename := req.Filename
+	if filename == "" {
+		filename = "main.tf"
+	}
+	remediation, err := s.secScanner.Remediate(req.Configuration, filename)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, RemediateResponse{
+			Success: false,
+			Error:   "Remediation failed: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, RemediateResponse{
+		Configuration: remediation.Configuration,
+		Diff:          remediation.Diff,
+		Fixes:         remediation.Fixes,
+		Issues:        remediation.Issues,
+		Suppressed:    remediation.Suppressed,
+		Success:       true,
 	})
 }
 
@@ -0,0 +1,224 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RyanSStephens/TF-NLP-Agent/internal/nlp"
+	"github.com/RyanSStephens/TF-NLP-Agent/internal/security"
+	"github.com/gin-gonic/gin"
+)
+
+// ambiguousDescription leaves the database engine and network access open
+const ambiguousDescription = "set up a public and private network with a database on aws in us-west-2"
+
+// staticProvider returns the same configuration for every description
+type staticProvider struct {
+	config string
+}
+
+func (p staticProvider) GenerateConfig(parsed *nlp.ParsedInput) (string, error) {
+	return p.config, nil
+}
+
+func (p staticProvider) Complete(ctx context.Context, prompt string) (string, error) {
+	return "", nil
+}
+
+func newTestServer(config string) *Server {
+	gin.DefaultWriter = io.Discard
+	engine := nlp.NewEngine()
+	server := NewServer(engine, engine, security.NewScanner())
+	server.aiProvider = staticProvider{config: config}
+	return server
+}
+
+// post sends body to path and decodes the JSON response into response
+func post(t *testing.T, server *Server, path, body string, response interface{}) int {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	if err := json.Unmarshal(rec.Body.Bytes(), response); err != nil {
+		t.Fatalf("POST %s returned invalid JSON %q: %v", path, rec.Body.String(), err)
+	}
+	return rec.Code
+}
+
+func questionIDs(questions []nlp.Question) map[string]bool {
+	ids := make(map[string]bool)
+	for _, question := range questions {
+		ids[question.ID] = true
+	}
+	return ids
+}
+
+func TestHandleParse(t *testing.T) {
+	server := newTestServer("")
+
+	var response ParseResponse
+	code := post(t, server, "/api/v1/parse", `{"description": "Create an encrypted S3 bucket on AWS"}`, &response)
+	if code != http.StatusOK || !response.Success {
+		t.Fatalf("parse = %d %+v, want 200 and success", code, response)
+	}
+	if response.Parsed == nil || response.Parsed.CloudProvider != "aws" {
+		t.Fatalf("parsed = %+v, want provider aws", response.Parsed)
+	}
+	if len(response.Parsed.Resources) == 0 || response.Parsed.Resources[0].Type != "storage" {
+		t.Errorf("parsed resources = %+v, want storage", response.Parsed.Resources)
+	}
+	if !strings.Contains(response.Prompt, "Create an encrypted S3 bucket on AWS") {
+		t.Errorf("prompt does not quote the description:\n%s", response.Prompt)
+	}
+
+	// Ambiguities are answered with defaults, and the questions returned
+	response = ParseResponse{}
+	code = post(t, server, "/api/v1/parse", `{"description": "`+ambiguousDescription+`", "answers": {"database_engine": "mysql"}}`, &response)
+	if code != http.StatusOK || !response.Success {
+		t.Fatalf("parse ambiguous = %d %+v, want 200 and success", code, response)
+	}
+	if ids := questionIDs(response.Questions); !ids[nlp.QuestionDatabaseEngine] || !ids[nlp.QuestionNetworkAccess] {
+		t.Errorf("questions = %v, want database_engine and network_access", response.Questions)
+	}
+	if remaining := server.engine.Clarify(response.Parsed); len(remaining) != 0 {
+		t.Errorf("parsed input still has questions %v after answers and defaults", remaining)
+	}
+}
+
+func TestHandleParseBadRequest(t *testing.T) {
+	server := newTestServer("")
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"malformed JSON", `{"description": `, "unexpected EOF"},
+		{"missing description", `{}`, "Description"},
+		{"invalid answer", `{"description": "` + ambiguousDescription + `", "answers": {"database_engine": "oracle"}}`, "Invalid clarification answers"},
+		{"unknown question", `{"description": "` + ambiguousDescription + `", "answers": {"favourite_color": "blue"}}`, "unknown clarifying question"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response ParseResponse
+			code := post(t, server, "/api/v1/parse", tt.body, &response)
+			if code != http.StatusBadRequest || response.Success || !strings.Contains(response.Error, tt.wantErr) {
+				t.Errorf("parse = %d %+v, want 400 with %q", code, response, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleGenerateNeedsClarification(t *testing.T) {
+	server := newTestServer(`resource "aws_db_instance" "main" {
+  engine            = "mysql"
+  storage_encrypted = true
+}
+`)
+
+	var response GenerateResponse
+	code := post(t, server, "/api/v1/generate", `{"description": "`+ambiguousDescription+`"}`, &response)
+	if code != http.StatusOK || response.Success || response.Status != StatusNeedsClarification {
+		t.Fatalf("generate = %d %+v, want 200 and needs_clarification", code, response)
+	}
+	if ids := questionIDs(response.Questions); !ids[nlp.QuestionDatabaseEngine] || !ids[nlp.QuestionNetworkAccess] {
+		t.Errorf("questions = %v, want database_engine and network_access", response.Questions)
+	}
+	if response.Configuration != "" {
+		t.Errorf("configuration = %q, want none before the questions are answered", response.Configuration)
+	}
+
+	// Answering one question leaves the other pending
+	response = GenerateResponse{}
+	post(t, server, "/api/v1/generate", `{"description": "`+ambiguousDescription+`", "answers": {"database_engine": "mysql"}}`, &response)
+	if ids := questionIDs(response.Questions); response.Status != StatusNeedsClarification || ids[nlp.QuestionDatabaseEngine] || !ids[nlp.QuestionNetworkAccess] {
+		t.Errorf("generate with one answer = %+v, want only network_access pending", response)
+	}
+
+	response = GenerateResponse{}
+	code = post(t, server, "/api/v1/generate", `{"description": "`+ambiguousDescription+`", "use_defaults": true}`, &response)
+	if code != http.StatusOK || !response.Success || response.Status != "" {
+		t.Fatalf("generate with defaults = %d %+v, want 200 and success", code, response)
+	}
+	if !strings.Contains(response.Configuration, `resource "aws_db_instance" "main"`) {
+		t.Errorf("configuration = %q, want the generated database", response.Configuration)
+	}
+
+	response = GenerateResponse{}
+	code = post(t, server, "/api/v1/generate", `{"description": "`+ambiguousDescription+`", "use_defaults": true, "answers": {"database_engine": "oracle"}}`, &response)
+	if code != http.StatusBadRequest || !strings.Contains(response.Error, "Invalid clarification answers") {
+		t.Errorf("generate with an invalid answer = %d %+v, want 400", code, response)
+	}
+
+	response = GenerateResponse{}
+	if code := post(t, server, "/api/v1/generate", `{}`, &response); code != http.StatusBadRequest || response.Success {
+		t.Errorf("generate without description = %d %+v, want 400", code, response)
+	}
+}
+
+func TestHandleRemediate(t *testing.T) {
+	server := newTestServer("")
+
+	request, _ := json.Marshal(RemediateRequest{
+		Configuration: `resource "aws_db_instance" "main" {
+  engine              = "postgres"
+  storage_encrypted   = true
+  publicly_accessible = true
+}
+`,
+		Filename: "db.tf",
+	})
+
+	var response RemediateResponse
+	code := post(t, server, "/api/v1/remediate", string(request), &response)
+	if code != http.StatusOK || !response.Success {
+		t.Fatalf("remediate = %d %+v, want 200 and success", code, response)
+	}
+	if !strings.Contains(response.Configuration, "publicly_accessible = false") {
+		t.Errorf("configuration = %q, want publicly_accessible = false", response.Configuration)
+	}
+	if !strings.Contains(response.Diff, "--- a/db.tf") || !strings.Contains(response.Diff, "+  publicly_accessible = false") {
+		t.Errorf("diff = %q, want a diff of db.tf", response.Diff)
+	}
+	var fixed bool
+	for _, fix := range response.Fixes {
+		fixed = fixed || (fix.Rule == "SEC005" && fix.Resource == "aws_db_instance.main")
+	}
+	if !fixed {
+		t.Errorf("fixes = %+v, want SEC005 on aws_db_instance.main", response.Fixes)
+	}
+	for _, issue := range response.Issues {
+		if issue.Rule == "SEC005" {
+			t.Errorf("SEC005 still reported after the fix: %+v", issue)
+		}
+	}
+}
+
+func TestHandleRemediateBadRequest(t *testing.T) {
+	server := newTestServer("")
+
+	var response RemediateResponse
+	if code := post(t, server, "/api/v1/remediate", `{"filename": "main.tf"}`, &response); code != http.StatusBadRequest || response.Success || response.Error == "" {
+		t.Errorf("remediate without configuration = %d %+v, want 400", code, response)
+	}
+
+	response = RemediateResponse{}
+	if code := post(t, server, "/api/v1/remediate", `not json`, &response); code != http.StatusBadRequest || response.Success {
+		t.Errorf("remediate with malformed JSON = %d %+v, want 400", code, response)
+	}
+
+	response = RemediateResponse{}
+	code := post(t, server, "/api/v1/remediate", `{"configuration": "resource \"aws_s3_bucket\" \"logs\" {"}`, &response)
+	if code != http.StatusOK || response.Success || !strings.Contains(response.Error, "HCL syntax errors") {
+		t.Errorf("remediate with invalid HCL = %d %+v, want a syntax error", code, response)
+	}
+}